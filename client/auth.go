@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type WhoAmI struct {
+	Type          string      `json:"type"`
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Fullname      string      `json:"fullname"`
+	Email         *string     `json:"email,omitempty"`
+	EmailVerified *bool       `json:"emailVerified,omitempty"`
+	CanPay        bool        `json:"canPay"`
+	IsPro         bool        `json:"isPro"`
+	AvatarURL     string      `json:"avatarUrl"`
+	Orgs          []WhoAmIOrg `json:"orgs"`
+	Auth          WhoAmIAuth  `json:"auth"`
+}
+
+type WhoAmIOrg struct {
+	Type         string `json:"type"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Fullname     string `json:"fullname"`
+	CanPay       bool   `json:"canPay"`
+	RoleInOrg    string `json:"roleInOrg"`
+	IsEnterprise bool   `json:"isEnterprise"`
+}
+
+type WhoAmIAuth struct {
+	Type        string       `json:"type"`
+	AccessToken *AccessToken `json:"accessToken,omitempty"`
+}
+
+type AccessToken struct {
+	DisplayName string            `json:"displayName"`
+	Role        TokenRole         `json:"role"`
+	CreatedAt   string            `json:"createdAt"`
+	FineGrained *FineGrainedScope `json:"fineGrained,omitempty"`
+}
+
+type TokenRole string
+
+const (
+	TokenRoleRead        TokenRole = "read"
+	TokenRoleWrite       TokenRole = "write"
+	TokenRoleFineGrained TokenRole = "fineGrained"
+)
+
+type FineGrainedScope struct {
+	CanReadGatedRepos bool               `json:"canReadGatedRepos"`
+	Global            []string           `json:"global"`
+	Scoped            []FineGrainedEntry `json:"scoped"`
+}
+
+type FineGrainedEntry struct {
+	Entity      FineGrainedEntity `json:"entity"`
+	Permissions []string          `json:"permissions"`
+}
+
+type FineGrainedEntity struct {
+	ID   string `json:"_id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// NamespaceAccess describes a namespace (user or organization) the token can act on
+type NamespaceAccess struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	RoleInOrg   string    `json:"roleInOrg,omitempty"`
+	TokenRole   TokenRole `json:"tokenRole"`
+	Permissions []string  `json:"permissions,omitempty"`
+}
+
+// Namespaces - list the namespaces the token can act on. Read and write tokens
+// reach the user and all of its organizations, fine-grained tokens only reach
+// the user and the organizations they are scoped to.
+func (w *WhoAmI) Namespaces() []NamespaceAccess {
+	role := TokenRoleWrite
+	if w.Auth.AccessToken != nil {
+		role = w.Auth.AccessToken.Role
+	}
+
+	roles := map[string]string{}
+	for _, org := range w.Orgs {
+		roles[org.Name] = org.RoleInOrg
+	}
+
+	if role != TokenRoleFineGrained || w.Auth.AccessToken.FineGrained == nil {
+		namespaces := []NamespaceAccess{{Name: w.Name, Type: "user", TokenRole: role}}
+		for _, org := range w.Orgs {
+			namespaces = append(namespaces, NamespaceAccess{Name: org.Name, Type: "org", RoleInOrg: org.RoleInOrg, TokenRole: role})
+		}
+		return namespaces
+	}
+
+	scope := w.Auth.AccessToken.FineGrained
+	namespaces := []NamespaceAccess{{Name: w.Name, Type: "user", TokenRole: role, Permissions: scope.Global}}
+	for _, entry := range scope.Scoped {
+		switch entry.Entity.Type {
+		case "user":
+			if entry.Entity.Name == w.Name {
+				namespaces[0].Permissions = append(namespaces[0].Permissions, entry.Permissions...)
+			}
+		case "org":
+			namespaces = append(namespaces, NamespaceAccess{
+				Name:        entry.Entity.Name,
+				Type:        "org",
+				RoleInOrg:   roles[entry.Entity.Name],
+				TokenRole:   role,
+				Permissions: entry.Permissions,
+			})
+		}
+	}
+
+	return namespaces
+}
+
+// CanAccessNamespace - whether the token can act on the given namespace
+func (w *WhoAmI) CanAccessNamespace(namespace string) bool {
+	for _, ns := range w.Namespaces() {
+		if ns.Name == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hubHost - hub url, defaulting to HubURL for clients built without NewClient
+func (c *Client) hubHost() string {
+	if c.HubHost != "" {
+		return c.HubHost
+	}
+
+	return HubURL
+}
+
+// HubBaseURL - url of the hub the client authenticates against
+func (c *Client) HubBaseURL() string {
+	return c.hubHost()
+}
+
+// WhoAmI - Get the account, organizations and token scopes behind the client token
+func (c *Client) WhoAmI() (*WhoAmI, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/whoami-v2", c.hubHost()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAuthRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var whoami WhoAmI
+	err = json.Unmarshal(body, &whoami)
+	if err != nil {
+		return nil, err
+	}
+
+	return &whoami, nil
+}
+
+// TokenPath - location of the stored token, compatible with huggingface_hub:
+// $HF_TOKEN_PATH, else $HF_HOME/token, else ~/.cache/huggingface/token
+func TokenPath() (string, error) {
+	if path := os.Getenv("HF_TOKEN_PATH"); path != "" {
+		return path, nil
+	}
+
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "token"), nil
+	}
+
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = filepath.Join(userHome, ".cache")
+	}
+
+	return filepath.Join(cacheDir, "huggingface", "token"), nil
+}
+
+// Login - validate the token against the hub and store it at TokenPath
+func (c *Client) Login(token string) (*WhoAmI, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	check := *c
+	check.Token = token
+	check.TokenProvider = nil
+
+	whoami, err := check.WhoAmI()
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if err := SaveToken(token); err != nil {
+		return nil, err
+	}
+
+	return whoami, nil
+}
+
+// SaveToken - store the token at TokenPath with owner-only permissions
+func SaveToken(token string) error {
+	path, err := TokenPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(token), 0600)
+}
+
+// LoadToken - read the token stored at TokenPath
+func LoadToken() (string, error) {
+	path, err := TokenPath()
+	if err != nil {
+		return "", err
+	}
+
+	return NewFileTokenProvider(path).Token(context.Background())
+}
+
+// Logout - remove the token stored at TokenPath
+func Logout() error {
+	path, err := TokenPath()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return errors.New("not logged in")
+	}
+
+	return err
+}
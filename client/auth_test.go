@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const whoamiFineGrained = `{
+	"type": "user",
+	"name": "alice",
+	"orgs": [{"type": "org", "name": "acme", "roleInOrg": "admin"}, {"type": "org", "name": "other", "roleInOrg": "read"}],
+	"auth": {
+		"type": "access_token",
+		"accessToken": {
+			"displayName": "ci",
+			"role": "fineGrained",
+			"fineGrained": {
+				"global": ["inference.serverless.write"],
+				"scoped": [
+					{"entity": {"_id": "1", "type": "org", "name": "acme"}, "permissions": ["inference.endpoints.write"]},
+					{"entity": {"_id": "2", "type": "model", "name": "acme/model"}, "permissions": ["repo.content.read"]}
+				]
+			}
+		}
+	}
+}`
+
+func TestWhoAmI(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		if req.URL.String() != HubURL+"/api/whoami-v2" {
+			t.Fatalf("unexpected url: %s", req.URL.String())
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(whoamiFineGrained)),
+		}
+	})
+
+	whoami, err := client.WhoAmI()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	namespaces := whoami.Namespaces()
+	if len(namespaces) != 2 || namespaces[0].Name != "alice" || namespaces[1].Name != "acme" {
+		t.Fatalf("unexpected namespaces: %+v", namespaces)
+	}
+
+	if namespaces[1].RoleInOrg != "admin" || namespaces[1].Permissions[0] != "inference.endpoints.write" {
+		t.Fatalf("unexpected acme access: %+v", namespaces[1])
+	}
+
+	if whoami.CanAccessNamespace("other") {
+		t.Fatalf("fine-grained token should not reach unscoped org")
+	}
+}
+
+func TestWhoAmINamespacesWriteToken(t *testing.T) {
+	whoami := WhoAmI{
+		Name: "alice",
+		Orgs: []WhoAmIOrg{{Name: "acme"}, {Name: "other"}},
+		Auth: WhoAmIAuth{AccessToken: &AccessToken{Role: TokenRoleWrite}},
+	}
+
+	if len(whoami.Namespaces()) != 3 || !whoami.CanAccessNamespace("other") {
+		t.Fatalf("write token should reach all orgs: %+v", whoami.Namespaces())
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	os.Setenv("HF_TOKEN_PATH", path)
+	defer os.Unsetenv("HF_TOKEN_PATH")
+
+	var authorization string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		authorization = req.Header.Get("Authorization")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"name":"alice"}`)),
+		}
+	})
+
+	if _, err := client.Login(" new-token\n"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if authorization != "Bearer new-token" {
+		t.Fatalf("login should validate the new token, got %s", authorization)
+	}
+
+	stored, err := LoadToken()
+	if err != nil || stored != "new-token" {
+		t.Fatalf("unexpected stored token %q: %v", stored, err)
+	}
+
+	if err := Logout(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := Logout(); err == nil {
+		t.Fatalf("expected error when logging out twice")
+	}
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBatchWorkers      = 4
+	DefaultBatchRetries      = 3
+	DefaultBatchRetryBackoff = time.Second
+	maxBatchLineSize         = 64 * 1024 * 1024
+)
+
+// BatchOptions configures a batch inference run
+type BatchOptions struct {
+	// Route of the endpoint receiving the payloads, e.g. "/generate" (defaults to the root route)
+	Route   string
+	Workers int
+	// Number of retries of a failed request when the error is retryable
+	Retries      int
+	RetryBackoff time.Duration
+	// Maximum number of requests per second across workers, 0 for no limit
+	RateLimit float64
+	// Indexes of the lines to skip, typically loaded with LoadBatchCheckpoint
+	Done map[int]bool
+}
+
+// BatchResult is one line of the results file
+type BatchResult struct {
+	Index     int             `json:"index"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	Output    json.RawMessage `json:"output,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Attempts  int             `json:"attempts"`
+	LatencyMs float64         `json:"latencyMs"`
+}
+
+// BatchSummary reports the outcome of a batch run
+type BatchSummary struct {
+	Total       int          `json:"total"`
+	Skipped     int          `json:"skipped"`
+	Succeeded   int          `json:"succeeded"`
+	Failed      int          `json:"failed"`
+	Interrupted bool         `json:"interrupted"`
+	Duration    string       `json:"duration"`
+	Latency     LatencyStats `json:"latency"`
+}
+
+type batchJob struct {
+	index   int
+	payload []byte
+}
+
+// RunBatch - send every json line of input to the endpoint and write one BatchResult
+// per line to output, in completion order. Lines listed in options.Done are skipped
+// so an interrupted run resumes where it stopped. Cancelling ctx aborts the run,
+// requests in flight are not written and will be sent again on resume.
+func (ic *InferenceClient) RunBatch(ctx context.Context, input io.Reader, output io.Writer, options BatchOptions) (*BatchSummary, error) {
+	if options.Workers < 1 {
+		options.Workers = DefaultBatchWorkers
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultBatchRetryBackoff
+	}
+
+	limiter := newRateLimiter(options.RateLimit)
+	defer limiter.stop()
+
+	jobs := make(chan batchJob)
+	results := make(chan BatchResult)
+	summary := &BatchSummary{}
+	start := time.Now()
+
+	// Reader: feed the workers until input is exhausted or ctx is cancelled
+	var readErr error
+	go func() {
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 64*1024), maxBatchLineSize)
+
+		for index := 0; scanner.Scan(); index++ {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			summary.Total++
+			if options.Done[index] {
+				summary.Skipped++
+				continue
+			}
+
+			payload := make([]byte, len(line))
+			copy(payload, line)
+
+			select {
+			case jobs <- batchJob{index: index, payload: payload}:
+			case <-ctx.Done():
+				summary.Interrupted = true
+				return
+			}
+		}
+
+		readErr = scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := ic.runBatchJob(ctx, job, options, limiter)
+				if ctx.Err() != nil && result.Output == nil {
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Writer: results are written as soon as they complete
+	encoder := json.NewEncoder(output)
+	var latencies []time.Duration
+	var writeErr error
+
+	for result := range results {
+		if writeErr == nil {
+			writeErr = encoder.Encode(result)
+		}
+
+		if result.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.Succeeded++
+		latencies = append(latencies, time.Duration(result.LatencyMs*float64(time.Millisecond)))
+	}
+
+	summary.Duration = time.Since(start).Round(time.Millisecond).String()
+	summary.Latency = NewLatencyStats(latencies)
+	if ctx.Err() != nil {
+		summary.Interrupted = true
+	}
+
+	if writeErr != nil {
+		return summary, writeErr
+	}
+
+	return summary, readErr
+}
+
+func (ic *InferenceClient) runBatchJob(ctx context.Context, job batchJob, options BatchOptions, limiter *rateLimiter) BatchResult {
+	result := BatchResult{Index: job.index, Input: job.payload}
+
+	if !json.Valid(job.payload) {
+		result.Input, _ = json.Marshal(string(job.payload))
+		result.Error = "invalid json input"
+		return result
+	}
+
+	err := Retry(ctx, options.Retries, options.RetryBackoff, func() error {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		result.Attempts++
+		requestStart := time.Now()
+		body, err := ic.PostContext(ctx, options.Route, "application/json", bytes.NewReader(job.payload))
+		result.LatencyMs = float64(time.Since(requestStart)) / float64(time.Millisecond)
+		if err != nil {
+			return err
+		}
+
+		if json.Valid(body) {
+			result.Output = body
+		} else {
+			quoted, _ := json.Marshal(string(body))
+			result.Output = quoted
+		}
+		return nil
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LoadBatchCheckpoint - indexes already present in a results file. When retryFailed
+// is set, failed results are removed from the file so they are run again.
+// A missing file is an empty checkpoint.
+func LoadBatchCheckpoint(path string, retryFailed bool) (map[int]bool, error) {
+	done := map[int]bool{}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var result BatchResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			// A partially written last line from an interrupted run
+			continue
+		}
+
+		if retryFailed && result.Error != "" {
+			continue
+		}
+
+		done[result.Index] = true
+		kept = append(kept, line)
+	}
+
+	rewritten := strings.Join(kept, "\n")
+	if len(kept) > 0 {
+		rewritten += "\n"
+	}
+
+	// Rewrite to drop truncated lines and, if requested, failed results
+	if rewritten != string(content) {
+		if err := os.WriteFile(path, []byte(rewritten), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return done, nil
+}
+
+// rateLimiter spaces requests evenly, a nil limiter never waits
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / perSecond))}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-l.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	var failures int32
+	client := newTestClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+
+		// The "flaky" input fails once with a retryable error
+		if strings.Contains(string(body), "flaky") && atomic.AddInt32(&failures, 1) == 1 {
+			return &http.Response{StatusCode: 503, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("overloaded"))}
+		}
+		if strings.Contains(string(body), "bad") {
+			return &http.Response{StatusCode: 422, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("invalid"))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBuffer(body))}
+	})
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, ""))
+
+	input := strings.Join([]string{
+		`{"inputs":"done"}`,
+		`{"inputs":"ok"}`,
+		``,
+		`{"inputs":"flaky"}`,
+		`{"inputs":"bad"}`,
+		`not json`,
+	}, "\n")
+
+	var output bytes.Buffer
+	summary, err := inference.RunBatch(context.Background(), strings.NewReader(input), &output, BatchOptions{
+		Workers:      2,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		Done:         map[int]bool{0: true},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if summary.Total != 5 || summary.Skipped != 1 || summary.Succeeded != 2 || summary.Failed != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	results := map[int]BatchResult{}
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var result BatchResult
+		json.Unmarshal(scanner.Bytes(), &result)
+		results[result.Index] = result
+	}
+
+	if results[3].Attempts != 2 || results[3].Error != "" {
+		t.Fatalf("flaky input should succeed on second attempt: %+v", results[3])
+	}
+	if results[4].Attempts != 1 || results[4].Error == "" {
+		t.Fatalf("non retryable error should not be retried: %+v", results[4])
+	}
+	if _, ok := results[0]; ok {
+		t.Fatalf("checkpointed input should be skipped")
+	}
+}
+
+func TestLoadBatchCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	content := `{"index":0,"output":{},"attempts":1}
+{"index":1,"error":"HTTP error 500: boom","attempts":4}
+{"index":2,"outp`
+	os.WriteFile(path, []byte(content), 0644)
+
+	done, err := LoadBatchCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(done) != 2 || !done[0] || !done[1] {
+		t.Fatalf("unexpected checkpoint: %+v", done)
+	}
+
+	done, _ = LoadBatchCheckpoint(path, true)
+	if len(done) != 1 || !done[0] {
+		t.Fatalf("failed results should be retried: %+v", done)
+	}
+
+	rewritten, _ := os.ReadFile(path)
+	if string(rewritten) != "{\"index\":0,\"output\":{},\"attempts\":1}\n" {
+		t.Fatalf("unexpected rewritten file: %q", rewritten)
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := NewLatencyStats(latencies)
+	if stats.P50 != 50 || stats.P95 != 95 || stats.P99 != 99 || stats.Max != 100 || stats.Mean != 50.5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := map[error]bool{
+		&HTTPError{StatusCode: 429}: true,
+		&HTTPError{StatusCode: 503}: true,
+		&HTTPError{StatusCode: 501}: false,
+		&HTTPError{StatusCode: 400}: false,
+		context.Canceled:            false,
+		context.DeadlineExceeded:    true,
+	}
+
+	for err, expected := range cases {
+		if IsRetryable(err) != expected {
+			t.Fatalf("IsRetryable(%v) should be %v", err, expected)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	err := Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return &HTTPError{StatusCode: 503}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %d calls (%v)", calls, err)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return &HTTPError{StatusCode: 400}
+	})
+	if calls != 1 || err == nil {
+		t.Fatalf("non retryable error should not be retried, got %d calls", calls)
+	}
+
+	// Waits honor Retry-After and end with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = Retry(ctx, 3, time.Millisecond, func() error {
+		return &HTTPError{StatusCode: 429, RetryAfter: time.Minute}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("expected the wait to end with the context, got %v after %s", err, time.Since(start))
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CatalogModel is a curated model with a recommended endpoint configuration
+type CatalogModel struct {
+	Repository  string       `json:"repoId"`
+	Task        EndpointTask `json:"task"`
+	Description string       `json:"description,omitempty"`
+	License     string       `json:"license,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	// Configuration the model was validated with
+	Recommended CatalogConfig `json:"endpointConfig"`
+}
+
+// CatalogConfig is the recommended provider, compute and model configuration of a catalog model
+type CatalogConfig struct {
+	Type     EndpointType     `json:"type,omitempty"`
+	Provider EndpointProvider `json:"provider"`
+	Compute  EndpointCompute  `json:"compute"`
+	Model    EndpointModel    `json:"model"`
+}
+
+// CatalogFilter selects catalog models, empty fields match all of them
+type CatalogFilter struct {
+	Task   string
+	Search string
+}
+
+// ListCatalogModels - List the curated catalog models
+func (c *Client) ListCatalogModels(filter CatalogFilter) ([]CatalogModel, error) {
+	query := url.Values{}
+	if filter.Task != "" {
+		query.Set("task", filter.Task)
+	}
+	if filter.Search != "" {
+		query.Set("search", filter.Search)
+	}
+
+	endpoint := fmt.Sprintf("%s/v2/catalog/repo", c.Host)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAuthRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Items []CatalogModel `json:"items"`
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Items, nil
+}
+
+// GetCatalogModel - Get the catalog model of a repository
+func (c *Client) GetCatalogModel(repository string) (*CatalogModel, error) {
+	models, err := c.ListCatalogModels(CatalogFilter{Search: repository})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range models {
+		if models[i].Repository == repository {
+			return &models[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("model %s is not in the catalog", repository)
+}
+
+// Endpoint - endpoint named name deploying the model with its recommended configuration
+func (m CatalogModel) Endpoint(name string) Endpoint {
+	config := m.Recommended
+
+	endpointType := config.Type
+	if endpointType == "" {
+		endpointType = TypeProtected
+	}
+
+	model := config.Model
+	if model.Repository == "" {
+		model.Repository = m.Repository
+	}
+	if model.Task == "" {
+		model.Task = m.Task
+	}
+	fromCatalog := true
+	model.FromCatalog = &fromCatalog
+
+	return Endpoint{
+		Name:     name,
+		Type:     endpointType,
+		Provider: config.Provider,
+		Compute:  config.Compute,
+		Model:    model,
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGetCatalogModel(t *testing.T) {
+	var query string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		query = req.URL.RawQuery
+		body := `{"items":[
+			{"repoId":"meta-llama/Llama-3.1-8B-Instruct-GGUF","task":"text-generation"},
+			{"repoId":"meta-llama/Llama-3.1-8B-Instruct","task":"text-generation","endpointConfig":{
+				"provider":{"vendor":"aws","region":"us-east-1"},
+				"compute":{"accelerator":"gpu","instanceType":"nvidia-l4","instanceSize":"x1","scaling":{"minReplica":0,"maxReplica":1,"scaleToZeroTimeout":15}},
+				"model":{"framework":"pytorch","image":{"tgi":{"url":"ghcr.io/huggingface/text-generation-inference:3.0.1","port":80}},"env":{"MAX_TOTAL_TOKENS":"8192"}}}}]}`
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})
+
+	model, err := client.GetCatalogModel("meta-llama/Llama-3.1-8B-Instruct")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if query != "search=meta-llama%2FLlama-3.1-8B-Instruct" {
+		t.Fatalf("expected the repository as search query, got %s", query)
+	}
+
+	endpoint := model.Endpoint("llama")
+	if endpoint.Name != "llama" || endpoint.Type != TypeProtected || endpoint.Provider.Vendor != "aws" || endpoint.Compute.InstanceType != "nvidia-l4" {
+		t.Fatalf("unexpected endpoint: %+v", endpoint)
+	}
+	if endpoint.Model.Repository != model.Repository || endpoint.Model.Task != TaskTextGeneration || endpoint.Model.FromCatalog == nil || !*endpoint.Model.FromCatalog {
+		t.Fatalf("expected the model of the catalog entry, got %+v", endpoint.Model)
+	}
+	if endpoint.Model.Image.TGI == nil || endpoint.Model.Env["MAX_TOTAL_TOKENS"] != "8192" {
+		t.Fatalf("expected the recommended image and env, got %+v", endpoint.Model)
+	}
+
+	if _, err := client.GetCatalogModel("gpt2"); err == nil {
+		t.Fatal("expected an error for a model missing from the catalog")
+	}
+}
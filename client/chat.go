@@ -0,0 +1,349 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Default model name sent to endpoints serving a single model
+const DefaultChatModel string = "tgi"
+
+// ChatClient calls the OpenAI-compatible /v1/chat/completions route of an endpoint
+type ChatClient struct {
+	*InferenceClient
+}
+
+// NewChatClient - Create a chat client for an endpoint (tgi, llama.cpp or any OpenAI-compatible image)
+func (c *Client) NewChatClient(endpoint EndpointWithStatus) (*ChatClient, error) {
+	inferenceClient, err := c.NewInferenceClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatClient{InferenceClient: inferenceClient}, nil
+}
+
+type ChatRole string
+
+const (
+	ChatRoleSystem    ChatRole = "system"
+	ChatRoleUser      ChatRole = "user"
+	ChatRoleAssistant ChatRole = "assistant"
+	ChatRoleTool      ChatRole = "tool"
+)
+
+type ChatMessage struct {
+	Role       ChatRole   `json:"role"`
+	Content    string     `json:"content"`
+	Name       string     `json:"name,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string            `json:"name,omitempty"`
+	Arguments FunctionArguments `json:"arguments,omitempty"`
+}
+
+// FunctionArguments holds the json encoded arguments of a function call. OpenAI
+// sends them as a string while some TGI versions send a json object, both are accepted.
+type FunctionArguments string
+
+func (a *FunctionArguments) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = FunctionArguments(s)
+		return nil
+	}
+
+	if string(data) == "null" {
+		*a = ""
+		return nil
+	}
+
+	*a = FunctionArguments(data)
+	return nil
+}
+
+type ResponseFormatType string
+
+const (
+	ResponseFormatText       ResponseFormatType = "text"
+	ResponseFormatJSONObject ResponseFormatType = "json_object"
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+type ResponseFormat struct {
+	Type       ResponseFormatType `json:"type"`
+	JSONSchema *JSONSchemaFormat  `json:"json_schema,omitempty"`
+	// TGI grammar style schema, sent alongside type json_object
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict *bool           `json:"strict,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type ChatCompletionRequest struct {
+	Model            string          `json:"model"`
+	Messages         []ChatMessage   `json:"messages"`
+	MaxTokens        *int            `json:"max_tokens,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
+	Stop             []string        `json:"stop,omitempty"`
+	Seed             *int64          `json:"seed,omitempty"`
+	Tools            []Tool          `json:"tools,omitempty"`
+	ToolChoice       interface{}     `json:"tool_choice,omitempty"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add - accumulate another usage, e.g. across the turns of a conversation
+func (u *Usage) Add(other *Usage) {
+	if other == nil {
+		return
+	}
+
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+type ChatChoice struct {
+	Index        int         `json:"index"`
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type ChatCompletionResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *Usage       `json:"usage,omitempty"`
+}
+
+type ChatDelta struct {
+	Role      ChatRole   `json:"role,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+type ChatChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason,omitempty"`
+}
+
+type ChatCompletionChunk struct {
+	ID      string            `json:"id"`
+	Object  string            `json:"object"`
+	Created int64             `json:"created"`
+	Model   string            `json:"model"`
+	Choices []ChatChunkChoice `json:"choices"`
+	Usage   *Usage            `json:"usage,omitempty"`
+}
+
+func (cc *ChatClient) prepare(request *ChatCompletionRequest) error {
+	if len(request.Messages) == 0 {
+		return errors.New("at least one message is required")
+	}
+
+	if request.Model == "" {
+		request.Model = DefaultChatModel
+	}
+
+	return nil
+}
+
+// ChatCompletion - Call /v1/chat/completions and return the whole completion
+func (cc *ChatClient) ChatCompletion(request ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	if err := cc.prepare(&request); err != nil {
+		return nil, err
+	}
+	request.Stream = false
+	request.StreamOptions = nil
+
+	var response ChatCompletionResponse
+	err := cc.PostJSON("/v1/chat/completions", request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ChatCompletionStream - Call /v1/chat/completions with streaming, deltas are read with Next
+func (cc *ChatClient) ChatCompletionStream(request ChatCompletionRequest) (*ChatStream, error) {
+	if err := cc.prepare(&request); err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	if request.StreamOptions == nil {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+
+	body, err := cc.PostStream("/v1/chat/completions", request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatStream{body: body, events: NewSSEReader(body), toolCalls: map[int]*ToolCall{}}, nil
+}
+
+// ChatStream iterates over the deltas of a streamed chat completion and
+// accumulates them into the final assistant message
+type ChatStream struct {
+	body   io.ReadCloser
+	events *SSEReader
+
+	content      strings.Builder
+	toolCalls    map[int]*ToolCall
+	finishReason string
+	usage        *Usage
+}
+
+// Next - read the next chunk, returns io.EOF once the completion is over
+func (s *ChatStream) Next() (*ChatCompletionChunk, error) {
+	for {
+		event, err := s.events.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		data := strings.TrimSpace(event.Data)
+		if data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			return nil, io.EOF
+		}
+
+		if strings.HasPrefix(data, `{"error"`) {
+			var chatErr struct {
+				Error json.RawMessage `json:"error"`
+			}
+			json.Unmarshal([]byte(data), &chatErr)
+			return nil, fmt.Errorf("chat completion error: %s", string(chatErr.Error))
+		}
+
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, err
+		}
+
+		s.accumulate(&chunk)
+
+		return &chunk, nil
+	}
+}
+
+func (s *ChatStream) accumulate(chunk *ChatCompletionChunk) {
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+
+		s.content.WriteString(choice.Delta.Content)
+		if choice.FinishReason != nil {
+			s.finishReason = *choice.FinishReason
+		}
+
+		for i, delta := range choice.Delta.ToolCalls {
+			index := i
+			if delta.Index != nil {
+				index = *delta.Index
+			}
+
+			call, ok := s.toolCalls[index]
+			if !ok {
+				call = &ToolCall{Type: "function"}
+				s.toolCalls[index] = call
+			}
+			if delta.ID != "" {
+				call.ID = delta.ID
+			}
+			if delta.Type != "" {
+				call.Type = delta.Type
+			}
+			if delta.Function.Name != "" {
+				call.Function.Name = delta.Function.Name
+			}
+			call.Function.Arguments += delta.Function.Arguments
+		}
+	}
+}
+
+// Message - assistant message accumulated from the deltas read so far
+func (s *ChatStream) Message() ChatMessage {
+	message := ChatMessage{Role: ChatRoleAssistant, Content: s.content.String()}
+
+	indexes := make([]int, 0, len(s.toolCalls))
+	for index := range s.toolCalls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		message.ToolCalls = append(message.ToolCalls, *s.toolCalls[index])
+	}
+
+	return message
+}
+
+// FinishReason - finish reason of the first choice, empty until the last chunk
+func (s *ChatStream) FinishReason() string {
+	return s.finishReason
+}
+
+// Usage - token usage reported by the server, nil if it was not sent
+func (s *ChatStream) Usage() *Usage {
+	return s.usage
+}
+
+// Close - release the underlying connection
+func (s *ChatStream) Close() error {
+	return s.body.Close()
+}
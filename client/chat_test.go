@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChatCompletion(t *testing.T) {
+	var request ChatCompletionRequest
+	client := newTestClient(func(req *http.Request) *http.Response {
+		if !strings.HasSuffix(req.URL.Path, "/v1/chat/completions") {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		json.NewDecoder(req.Body).Decode(&request)
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(bytes.NewBufferString(`{
+				"id": "1",
+				"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {"role": "assistant", "content": "", "tool_calls": [{"id": "0", "type": "function", "function": {"name": "get_weather", "arguments": {"city": "Paris"}}}]}}],
+				"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
+			}`)),
+		}
+	})
+
+	chat, _ := client.NewChatClient(testEndpoint(TypeProtected, TaskTextGeneration))
+	response, err := chat.ChatCompletion(ChatCompletionRequest{
+		Messages: []ChatMessage{{Role: ChatRoleUser, Content: "weather in Paris?"}},
+		Tools:    []Tool{{Type: "function", Function: FunctionDefinition{Name: "get_weather"}}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if request.Model != DefaultChatModel || request.Stream {
+		t.Fatalf("unexpected request: %+v", request)
+	}
+
+	call := response.Choices[0].Message.ToolCalls[0]
+	if call.Function.Name != "get_weather" || call.Function.Arguments != `{"city": "Paris"}` {
+		t.Fatalf("unexpected tool call: %+v", call)
+	}
+
+	if response.Usage.TotalTokens != 15 {
+		t.Fatalf("unexpected usage: %+v", response.Usage)
+	}
+}
+
+func TestChatCompletionStream(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(bytes.NewBufferString(
+				`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}` + "\n\n" +
+					`data: {"choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n\n" +
+					`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"c1","function":{"name":"f","arguments":"{\"a\""}}]}}]}` + "\n\n" +
+					`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":":1}"}}]},"finish_reason":"stop"}]}` + "\n\n" +
+					`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}` + "\n\n" +
+					"data: [DONE]\n\n",
+			)),
+		}
+	})
+
+	chat, _ := client.NewChatClient(testEndpoint(TypeProtected, TaskTextGeneration))
+	stream, err := chat.ChatCompletionStream(ChatCompletionRequest{Messages: []ChatMessage{{Role: ChatRoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer stream.Close()
+
+	chunks := 0
+	for {
+		_, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		chunks++
+	}
+
+	if chunks != 5 {
+		t.Fatalf("expected 5 chunks, got %d", chunks)
+	}
+
+	message := stream.Message()
+	if message.Content != "Hello" || len(message.ToolCalls) != 1 || message.ToolCalls[0].Function.Arguments != `{"a":1}` {
+		t.Fatalf("unexpected accumulated message: %+v", message)
+	}
+
+	if stream.FinishReason() != "stop" || stream.Usage().TotalTokens != 5 {
+		t.Fatalf("unexpected finish reason or usage: %s %+v", stream.FinishReason(), stream.Usage())
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	usage := Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}
+	usage.Add(&Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+	usage.Add(nil)
+
+	if usage.TotalTokens != 5 || usage.PromptTokens != 2 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
@@ -1,31 +1,47 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
 // Default host url is huggingface API url
 const HostURL string = "https://api.endpoints.huggingface.cloud"
 
+// Default hub url is huggingface website url
+const HubURL string = "https://huggingface.co"
+
+// Environment variable overriding the hub url, as in huggingface_hub
+const HubEndpointEnvVar string = "HF_ENDPOINT"
+
 type Client struct {
-	Host   string
-	Token  string
-	Client *http.Client
+	Host    string
+	HubHost string
+	Token   string
+	// Optional token source, takes precedence over Token when set
+	TokenProvider TokenProvider
+	Client        *http.Client
 }
 
 func NewClient(host, token *string) (*Client, error) {
 	c := Client{
 		Client: &http.Client{Timeout: 10 * time.Second},
 		// Default Huggingface Endpoints URL
-		Host: HostURL,
+		Host:    HostURL,
+		HubHost: HubURL,
 	}
 
 	if host != nil && *host != "" {
 		c.Host = *host
 	}
+	if hubHost := os.Getenv(HubEndpointEnvVar); hubHost != "" {
+		c.HubHost = strings.TrimRight(hubHost, "/")
+	}
 
 	// If token not provided, return empty client
 	if token == nil {
@@ -37,6 +53,84 @@ func NewClient(host, token *string) (*Client, error) {
 	return &c, nil
 }
 
+// NewClientWithTokenProvider - create a client authenticating with a token provider
+func NewClientWithTokenProvider(host *string, provider TokenProvider) (*Client, error) {
+	if provider == nil {
+		return nil, errors.New("token provider is required")
+	}
+
+	c, err := NewClient(host, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.TokenProvider = provider
+
+	return c, nil
+}
+
+// resolveToken - token from the provider if any, static token otherwise
+func (c *Client) resolveToken(req *http.Request) (*string, error) {
+	if c.TokenProvider == nil {
+		return &c.Token, nil
+	}
+
+	token, err := c.TokenProvider.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// doAuthRequest - doRequest authenticated with the client credentials
+func (c *Client) doAuthRequest(req *http.Request) ([]byte, error) {
+	authToken, err := c.resolveToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doRequest(req, authToken)
+}
+
+// doAuthStreamRequest - doStreamRequest authenticated with the client credentials
+func (c *Client) doAuthStreamRequest(req *http.Request) (io.ReadCloser, error) {
+	authToken, err := c.resolveToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doStreamRequest(req, authToken)
+}
+
+// Do - send req authenticated with the client credentials and return the response unread,
+// for packages built on the client such as hub. A status code >= 400 is returned as an *HTTPError,
+// otherwise the caller must close the response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	authToken, err := c.resolveToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req.Header.Set("Authorization", "Bearer "+*authToken)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, newHTTPError(resp, bodyBytes)
+	}
+
+	return resp, nil
+}
+
 // doRequest - for normal HTTP APIs (returns whole response body)
 func (c *Client) doRequest(req *http.Request, authToken *string) ([]byte, error) {
 	if c.Client == nil {
@@ -46,7 +140,9 @@ func (c *Client) doRequest(req *http.Request, authToken *string) ([]byte, error)
 	if authToken != nil {
 		req.Header.Set("Authorization", "Bearer "+*authToken)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.Client.Do(req)
@@ -58,7 +154,7 @@ func (c *Client) doRequest(req *http.Request, authToken *string) ([]byte, error)
 	// Check for HTTP errors (status >= 400)
 	if resp.StatusCode >= 400 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newHTTPError(resp, bodyBytes)
 	}
 
 	return io.ReadAll(resp.Body)
@@ -84,7 +180,7 @@ func (c *Client) doStreamRequest(req *http.Request, authToken *string) (io.ReadC
 	if resp.StatusCode >= 400 {
 		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newHTTPError(resp, bodyBytes)
 	}
 
 	return resp.Body, nil
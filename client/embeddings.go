@@ -0,0 +1,302 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// Default TEI --max-batch-tokens
+	DefaultMaxBatchTokens = 16384
+	// Default TEI --max-client-batch-size
+	DefaultMaxBatchSize = 32
+	// Default number of batches sent in parallel
+	DefaultEmbeddingConcurrency = 4
+	// Rough characters per token ratio used to estimate batch sizes client side
+	charsPerToken = 4
+)
+
+type EmbeddingBackend string
+
+const (
+	EmbeddingBackendTEI      EmbeddingBackend = "tei"
+	EmbeddingBackendLlamaCpp EmbeddingBackend = "llamacpp"
+)
+
+// EmbeddingClient calls the embedding and reranking routes of TEI and llama.cpp endpoints
+type EmbeddingClient struct {
+	*InferenceClient
+	Backend EmbeddingBackend
+	// Batching limits, derived from the endpoint image when known
+	MaxBatchTokens int
+	MaxBatchSize   int
+	Concurrency    int
+	// Concurrent requests accepted by the image, 0 when unknown
+	MaxConcurrency int
+}
+
+// NewEmbeddingClient - Create an embedding client for an endpoint deployed with a TEI image
+// or a llama.cpp image in embeddings or reranking mode
+func (c *Client) NewEmbeddingClient(endpoint EndpointWithStatus) (*EmbeddingClient, error) {
+	image := endpoint.Model.Image
+
+	ec := &EmbeddingClient{
+		MaxBatchTokens: DefaultMaxBatchTokens,
+		MaxBatchSize:   DefaultMaxBatchSize,
+		Concurrency:    DefaultEmbeddingConcurrency,
+	}
+
+	switch {
+	case image.TEI != nil:
+		ec.Backend = EmbeddingBackendTEI
+		if image.TEI.MaxBatchTokens != nil && *image.TEI.MaxBatchTokens > 0 {
+			ec.MaxBatchTokens = *image.TEI.MaxBatchTokens
+		}
+		if image.TEI.MaxConcurrentRequests != nil && *image.TEI.MaxConcurrentRequests > 0 {
+			ec.MaxConcurrency = *image.TEI.MaxConcurrentRequests
+		}
+	case image.LlamaCpp != nil && image.LlamaCpp.Mode != nil:
+		ec.Backend = EmbeddingBackendLlamaCpp
+		if image.LlamaCpp.CtxSize > 0 {
+			ec.MaxBatchTokens = image.LlamaCpp.CtxSize
+		}
+		ec.MaxConcurrency = image.LlamaCpp.NParallel
+	default:
+		return nil, fmt.Errorf("endpoint %s is neither deployed with a tei image nor with a llamacpp image in embeddings/reranking mode", endpoint.Name)
+	}
+	ec.SetConcurrency(ec.Concurrency)
+
+	inferenceClient, err := c.NewInferenceClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	ec.InferenceClient = inferenceClient
+
+	return ec, nil
+}
+
+type EmbedRequest struct {
+	Inputs     []string `json:"inputs"`
+	Normalize  *bool    `json:"normalize,omitempty"`
+	Truncate   *bool    `json:"truncate,omitempty"`
+	PromptName *string  `json:"prompt_name,omitempty"`
+}
+
+type RerankRequest struct {
+	Query      string   `json:"query"`
+	Texts      []string `json:"texts"`
+	RawScores  *bool    `json:"raw_scores,omitempty"`
+	ReturnText *bool    `json:"return_text,omitempty"`
+	Truncate   *bool    `json:"truncate,omitempty"`
+}
+
+type RerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+	Text  *string `json:"text,omitempty"`
+}
+
+// llama.cpp /v1/rerank payloads
+type llamaCppRerankRequest struct {
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type llamaCppRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// EmbeddingsRequest is the OpenAI-compatible /v1/embeddings payload
+type EmbeddingsRequest struct {
+	Input          []string `json:"input"`
+	Model          string   `json:"model"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+	Dimensions     *int     `json:"dimensions,omitempty"`
+}
+
+type EmbeddingsResponse struct {
+	Object string          `json:"object"`
+	Model  string          `json:"model"`
+	Data   []EmbeddingData `json:"data"`
+	Usage  *Usage          `json:"usage,omitempty"`
+}
+
+type EmbeddingData struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// Embed - Embed the inputs in a single call (/embed for tei, /v1/embeddings for llama.cpp)
+func (ec *EmbeddingClient) Embed(request EmbedRequest) ([][]float64, error) {
+	if ec.Backend == EmbeddingBackendLlamaCpp {
+		response, err := ec.Embeddings(EmbeddingsRequest{Input: request.Inputs})
+		if err != nil {
+			return nil, err
+		}
+
+		vectors := make([][]float64, len(request.Inputs))
+		for _, data := range response.Data {
+			if data.Index >= 0 && data.Index < len(vectors) {
+				vectors[data.Index] = data.Embedding
+			}
+		}
+
+		return vectors, nil
+	}
+
+	var vectors [][]float64
+	err := ec.PostJSON("/embed", request, &vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+// Embeddings - Call the OpenAI-compatible /v1/embeddings route
+func (ec *EmbeddingClient) Embeddings(request EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	if request.Model == "" {
+		request.Model = DefaultChatModel
+	}
+
+	var response EmbeddingsResponse
+	err := ec.PostJSON("/v1/embeddings", request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// Rerank - Score texts against a query, results are sorted by decreasing score
+func (ec *EmbeddingClient) Rerank(request RerankRequest) ([]RerankResult, error) {
+	if ec.Backend == EmbeddingBackendLlamaCpp {
+		var response llamaCppRerankResponse
+		err := ec.PostJSON("/v1/rerank", llamaCppRerankRequest{Query: request.Query, Documents: request.Texts}, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]RerankResult, 0, len(response.Results))
+		for _, result := range response.Results {
+			results = append(results, RerankResult{Index: result.Index, Score: result.RelevanceScore})
+		}
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+
+		return results, nil
+	}
+
+	var results []RerankResult
+	err := ec.PostJSON("/rerank", request, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetConcurrency - Set the number of batches sent at once, capped by MaxConcurrency
+func (ec *EmbeddingClient) SetConcurrency(concurrency int) {
+	if ec.MaxConcurrency > 0 && concurrency > ec.MaxConcurrency {
+		concurrency = ec.MaxConcurrency
+	}
+	ec.Concurrency = concurrency
+}
+
+// EmbedBatched - Embed any number of inputs, splitting them in batches honoring
+// MaxBatchTokens and MaxBatchSize and sending up to Concurrency batches at once.
+// The vectors are returned in the order of the inputs.
+func (ec *EmbeddingClient) EmbedBatched(inputs []string, normalize *bool, truncate *bool) ([][]float64, error) {
+	batches := ec.splitBatches(inputs)
+	vectors := make([][]float64, len(inputs))
+
+	concurrency := ec.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, batch := range batches {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(batch embedBatch) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, err := ec.Embed(EmbedRequest{Inputs: inputs[batch.start:batch.end], Normalize: normalize, Truncate: truncate})
+			if err == nil && len(result) != batch.end-batch.start {
+				err = fmt.Errorf("expected %d embeddings, got %d", batch.end-batch.start, len(result))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("batch [%d:%d]: %w", batch.start, batch.end, err)
+				}
+				return
+			}
+			copy(vectors[batch.start:batch.end], result)
+		}(batch)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return vectors, nil
+}
+
+type embedBatch struct {
+	start int
+	end   int
+}
+
+// splitBatches - contiguous batches whose estimated token count fits MaxBatchTokens.
+// An input larger than the limit gets a batch of its own and is left to server truncation.
+func (ec *EmbeddingClient) splitBatches(inputs []string) []embedBatch {
+	maxTokens := ec.MaxBatchTokens
+	if maxTokens < 1 {
+		maxTokens = DefaultMaxBatchTokens
+	}
+	maxSize := ec.MaxBatchSize
+	if maxSize < 1 {
+		maxSize = DefaultMaxBatchSize
+	}
+
+	var batches []embedBatch
+	start, tokens := 0, 0
+
+	for i, input := range inputs {
+		estimate := len(input)/charsPerToken + 1
+		if i > start && (tokens+estimate > maxTokens || i-start >= maxSize) {
+			batches = append(batches, embedBatch{start: start, end: i})
+			start, tokens = i, 0
+		}
+		tokens += estimate
+	}
+
+	if start < len(inputs) {
+		batches = append(batches, embedBatch{start: start, end: len(inputs)})
+	}
+
+	return batches
+}
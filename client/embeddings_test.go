@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func teiEndpoint(maxBatchTokens, maxConcurrentRequests int) EndpointWithStatus {
+	endpoint := testEndpoint(TypeProtected, TaskSentenceEmbeddings)
+	endpoint.Model.Image.TEI = &TEIImage{
+		URL:                   "ghcr.io/huggingface/text-embeddings-inference:latest",
+		MaxBatchTokens:        &maxBatchTokens,
+		MaxConcurrentRequests: &maxConcurrentRequests,
+	}
+	return endpoint
+}
+
+func TestNewEmbeddingClientLimits(t *testing.T) {
+	client := newTestClient(nil)
+
+	ec, err := client.NewEmbeddingClient(teiEndpoint(512, 2))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if ec.Backend != EmbeddingBackendTEI || ec.MaxBatchTokens != 512 || ec.Concurrency != 2 {
+		t.Fatalf("unexpected limits: %+v", ec)
+	}
+
+	ec.SetConcurrency(8)
+	if ec.Concurrency != 2 {
+		t.Fatalf("expected the concurrency to be capped by the image, got %d", ec.Concurrency)
+	}
+	ec.SetConcurrency(1)
+	if ec.Concurrency != 1 {
+		t.Fatalf("expected a lower concurrency to be kept, got %d", ec.Concurrency)
+	}
+
+	if _, err := client.NewEmbeddingClient(tgiEndpoint()); err == nil {
+		t.Fatalf("expected error for tgi endpoint")
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	ec := &EmbeddingClient{MaxBatchTokens: 10, MaxBatchSize: 3}
+
+	// Each 8 character input is estimated at 3 tokens
+	inputs := []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd", strings.Repeat("e", 100), "ffffffff"}
+	batches := ec.splitBatches(inputs)
+
+	expected := []embedBatch{{0, 3}, {3, 4}, {4, 5}, {5, 6}}
+	if len(batches) != len(expected) {
+		t.Fatalf("unexpected batches: %+v", batches)
+	}
+	for i := range expected {
+		if batches[i] != expected[i] {
+			t.Fatalf("unexpected batches: %+v", batches)
+		}
+	}
+}
+
+func TestEmbedBatched(t *testing.T) {
+	var calls int32
+	client := newTestClient(func(req *http.Request) *http.Response {
+		atomic.AddInt32(&calls, 1)
+
+		var request EmbedRequest
+		json.NewDecoder(req.Body).Decode(&request)
+
+		// Encode the input length as the single embedding value to check ordering
+		var vectors []string
+		for _, input := range request.Inputs {
+			vectors = append(vectors, fmt.Sprintf("[%d]", len(input)))
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString("[" + strings.Join(vectors, ",") + "]")),
+		}
+	})
+
+	ec, _ := client.NewEmbeddingClient(teiEndpoint(16384, 3))
+	ec.MaxBatchSize = 2
+
+	var inputs []string
+	for i := 1; i <= 7; i++ {
+		inputs = append(inputs, strings.Repeat("x", i))
+	}
+
+	vectors, err := ec.EmbedBatched(inputs, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if calls != 4 {
+		t.Fatalf("expected 4 batches, got %d", calls)
+	}
+
+	for i, vector := range vectors {
+		if int(vector[0]) != i+1 {
+			t.Fatalf("embedding %d out of order: %v", i, vector)
+		}
+	}
+}
+
+func TestRerankLlamaCpp(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		if !strings.HasSuffix(req.URL.Path, "/v1/rerank") {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"results":[{"index":1,"relevance_score":0.7}]}`)),
+		}
+	})
+
+	mode := ModelModeReranking
+	endpoint := testEndpoint(TypeProtected, TaskSentenceRanking)
+	endpoint.Model.Image.LlamaCpp = &LlamaCppImage{Mode: &mode, CtxSize: 4096, NParallel: 1}
+
+	ec, err := client.NewEmbeddingClient(endpoint)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	results, err := ec.Rerank(RerankRequest{Query: "q", Texts: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(results) != 1 || results[0].Index != 1 || results[0].Score != 0.7 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -20,7 +20,7 @@ func (c *Client) ListEndpoints(namespace string, tags *string) ([]EndpointWithSt
 		return nil, err
 	}
 
-	body, err := c.doRequest(req, &c.Token)
+	body, err := c.doAuthRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +48,7 @@ func (c *Client) CreateEndpoint(namespace string, endpoint Endpoint) (*EndpointW
 		return nil, err
 	}
 
-	body, err := c.doRequest(req, &c.Token)
+	body, err := c.doAuthRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +69,7 @@ func (c *Client) GetEndpoint(namespace, name string) (*EndpointWithStatus, error
 		return nil, err
 	}
 
-	body, err := c.doRequest(req, &c.Token)
+	body, err := c.doAuthRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +95,7 @@ func (c *Client) UpdateEndpoint(namespace, name string, endpointUpdate EndpointU
 		return nil, err
 	}
 
-	body, err := c.doRequest(req, &c.Token)
+	body, err := c.doAuthRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +116,7 @@ func (c *Client) DeleteEndpoint(namespace, name string) error {
 		return err
 	}
 
-	_, err = c.doRequest(req, &c.Token)
+	_, err = c.doAuthRequest(req)
 	return err
 }
 
@@ -132,7 +132,7 @@ func (c *Client) GetEndpointLogs(namespace, name string, replicaID *string) ([]b
 		return nil, err
 	}
 
-	return c.doRequest(req, &c.Token)
+	return c.doAuthRequest(req)
 }
 
 // StreamEndpointLogs - Stream logs from an endpoint using SSE (optionally filter by replica ID)
@@ -147,7 +147,7 @@ func (c *Client) StreamEndpointLogs(namespace, name string, replicaID *string) (
 		return nil, err
 	}
 
-	return c.doStreamRequest(req, &c.Token)
+	return c.doAuthStreamRequest(req)
 }
 
 // GetEndpointMetrics - Get all metrics for an endpoint (plural version)
@@ -164,7 +164,7 @@ func (c *Client) GetEndpointMetrics(namespace, name string, metricsRequest Metri
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.doRequest(req, &c.Token)
+	return c.doAuthRequest(req)
 }
 
 // GetEndpointMetric - Get metrics from an endpoint
@@ -181,7 +181,7 @@ func (c *Client) GetEndpointMetric(namespace, name, metricType string, metricReq
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.doRequest(req, &c.Token)
+	return c.doAuthRequest(req)
 }
 
 // PauseEndpoint - Pause a running endpoint
@@ -191,7 +191,7 @@ func (c *Client) PauseEndpoint(namespace, name string) error {
 		return err
 	}
 
-	_, err = c.doRequest(req, &c.Token)
+	_, err = c.doAuthRequest(req)
 	return err
 }
 
@@ -202,7 +202,7 @@ func (c *Client) GetEndpointReplicasStatuses(namespace, name string) ([]byte, er
 		return nil, err
 	}
 
-	return c.doRequest(req, &c.Token)
+	return c.doAuthRequest(req)
 }
 
 // ResumeEndpoint - Resume a paused endpoint
@@ -212,7 +212,7 @@ func (c *Client) ResumeEndpoint(namespace, name string) error {
 		return err
 	}
 
-	_, err = c.doRequest(req, &c.Token)
+	_, err = c.doAuthRequest(req)
 	return err
 }
 
@@ -223,7 +223,7 @@ func (c *Client) ScaleEndpointToZero(namespace, name string) error {
 		return err
 	}
 
-	_, err = c.doRequest(req, &c.Token)
+	_, err = c.doAuthRequest(req)
 	return err
 }
 
@@ -234,5 +234,5 @@ func (c *Client) GetEndpointSSE(namespace, name string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	return c.doStreamRequest(req, &c.Token)
+	return c.doAuthStreamRequest(req)
 }
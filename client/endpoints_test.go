@@ -164,7 +164,7 @@ func TestGetEndpointMetrics(t *testing.T) {
 		}
 	})
 
-	data, err := client.GetEndpointMetrics("namespace", "endpoint")
+	data, err := client.GetEndpointMetrics("namespace", "endpoint", MetricsRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -182,7 +182,7 @@ func TestGetEndpointMetric(t *testing.T) {
 		}
 	})
 
-	data, err := client.GetEndpointMetric("namespace", "endpoint", "hardwareUsage")
+	data, err := client.GetEndpointMetric("namespace", "endpoint", "hardwareUsage", MetricRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPError is returned for api responses with a status code >= 400
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	// Delay requested by the server through the Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP error %d: %s", e.StatusCode, e.Body)
+}
+
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	httpErr := &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			httpErr.RetryAfter = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			httpErr.RetryAfter = time.Until(date)
+		}
+	}
+
+	return httpErr
+}
+
+// IsNotFound - whether the error is an HTTP 404
+func IsNotFound(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// IsRetryable - whether the request that failed with err may succeed if sent again:
+// timeouts, rate limiting, server errors and network failures
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		case http.StatusNotImplemented:
+			return false
+		}
+		return httpErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Retry - call fn until it succeeds, fails with an error which is not retryable or retries are exhausted,
+// waiting backoff then twice longer between calls, or longer when the server asks to with Retry-After.
+// The context error is returned when ctx is done while waiting.
+func Retry(ctx context.Context, retries int, backoff time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !IsRetryable(err) {
+			return err
+		}
+
+		delay := backoff
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > delay {
+			delay = httpErr.RetryAfter
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
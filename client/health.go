@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Route probed when the image does not define one
+	DefaultHealthRoute = "/health"
+	// Timeout of the http probe of the health route
+	DefaultHealthTimeout = 10 * time.Second
+)
+
+type HealthStatus string
+
+const (
+	// Running with all target replicas ready and answering the probe
+	HealthHealthy HealthStatus = "healthy"
+	// Answering the probe with fewer ready replicas than targeted
+	HealthDegraded HealthStatus = "degraded"
+	// Not serving yet, or stopped (pending, initializing, paused, scaled to zero)
+	HealthNotReady HealthStatus = "notReady"
+	// Failed, or running without answering the probe
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// HealthReport combines the endpoint status reported by the api with a probe
+// of the health route of its image
+type HealthReport struct {
+	Name          string        `json:"name"`
+	Health        HealthStatus  `json:"health"`
+	State         EndpointState `json:"state"`
+	ReadyReplica  int           `json:"readyReplica"`
+	TargetReplica int           `json:"targetReplica"`
+	URL           string        `json:"url,omitempty"`
+	Route         string        `json:"route"`
+	Port          int           `json:"port,omitempty"`
+	// Whether the health route was called, scaled to zero endpoints are not
+	// probed to avoid waking them up
+	Probed bool `json:"probed"`
+	// Status code of a failed probe
+	StatusCode int       `json:"statusCode,omitempty"`
+	LatencyMs  float64   `json:"latencyMs,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// HealthRoute - health route and container port declared by the image
+func HealthRoute(image EndpointModelImage) (string, int) {
+	var route *string
+	port := 0
+
+	switch {
+	case image.TGI != nil:
+		route, port = image.TGI.HealthRoute, image.TGI.Port
+	case image.TGINeuron != nil:
+		route, port = image.TGINeuron.HealthRoute, image.TGINeuron.Port
+	case image.TEI != nil:
+		route, port = image.TEI.HealthRoute, image.TEI.Port
+	case image.LlamaCpp != nil:
+		route, port = image.LlamaCpp.HealthRoute, image.LlamaCpp.Port
+	case image.Custom != nil:
+		route, port = image.Custom.HealthRoute, image.Custom.Port
+	}
+
+	if route == nil || *route == "" {
+		return DefaultHealthRoute, port
+	}
+	if !strings.HasPrefix(*route, "/") {
+		return "/" + *route, port
+	}
+
+	return *route, port
+}
+
+// HealthCheck - Check the health of an endpoint with the default probe timeout
+func (c *Client) HealthCheck(endpoint EndpointWithStatus) (*HealthReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultHealthTimeout)
+	defer cancel()
+
+	return c.HealthCheckContext(ctx, endpoint)
+}
+
+// HealthCheckContext - Check the health of an endpoint, the context bounds the http probe
+func (c *Client) HealthCheckContext(ctx context.Context, endpoint EndpointWithStatus) (*HealthReport, error) {
+	route, port := HealthRoute(endpoint.Model.Image)
+	report := &HealthReport{
+		Name:          endpoint.Name,
+		State:         endpoint.Status.State,
+		ReadyReplica:  endpoint.Status.ReadyReplica,
+		TargetReplica: endpoint.Status.TargetReplica,
+		Route:         route,
+		Port:          port,
+		Message:       endpoint.Status.Message,
+		CheckedAt:     time.Now(),
+	}
+	if endpoint.Status.URL != nil {
+		report.URL = *endpoint.Status.URL
+	}
+
+	switch endpoint.Status.State {
+	case StateFailed, StateUpdateFailed:
+		report.Health = HealthUnhealthy
+		if endpoint.Status.ErrorMessage != nil {
+			report.Message = *endpoint.Status.ErrorMessage
+		}
+		return report, nil
+	case StatePaused, StateScaledToZero:
+		report.Health = HealthNotReady
+		return report, nil
+	}
+
+	if endpoint.Status.ReadyReplica == 0 || report.URL == "" {
+		report.Health = HealthNotReady
+		return report, nil
+	}
+
+	inference, err := c.NewInferenceClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	_, err = inference.GetContext(ctx, route)
+	report.Probed = true
+	report.LatencyMs = float64(time.Since(start)) / float64(time.Millisecond)
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		report.StatusCode = httpErr.StatusCode
+		report.Message = fmt.Sprintf("health route returned %d", httpErr.StatusCode)
+	} else if err != nil {
+		report.Message = err.Error()
+	}
+
+	switch {
+	case err != nil:
+		report.Health = HealthUnhealthy
+	case endpoint.Status.State != StateRunning || endpoint.Status.ReadyReplica < endpoint.Status.TargetReplica:
+		report.Health = HealthDegraded
+	default:
+		report.Health = HealthHealthy
+	}
+
+	return report, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestHealthRoute(t *testing.T) {
+	route := "ready"
+	cases := map[string]EndpointModelImage{
+		DefaultHealthRoute: {HuggingFace: &HuggingFaceImage{}},
+		"/ready":           {TEI: &TEIImage{HealthRoute: &route, Port: 80}},
+	}
+
+	for expected, image := range cases {
+		if got, _ := HealthRoute(image); got != expected {
+			t.Fatalf("expected route %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	var probes []string
+	status := 200
+	client := newTestClient(func(req *http.Request) *http.Response {
+		probes = append(probes, req.URL.Path)
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(""))}
+	})
+
+	endpoint := testEndpoint(TypeProtected, TaskTextGeneration)
+	endpoint.Status.ReadyReplica = 1
+	endpoint.Status.TargetReplica = 2
+
+	report, err := client.HealthCheck(endpoint)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Health != HealthDegraded || !report.Probed || probes[0] != "/health" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	endpoint.Status.ReadyReplica = 2
+	report, _ = client.HealthCheck(endpoint)
+	if report.Health != HealthHealthy {
+		t.Fatalf("expected healthy endpoint, got %+v", report)
+	}
+
+	status = 503
+	report, _ = client.HealthCheck(endpoint)
+	if report.Health != HealthUnhealthy || report.StatusCode != 503 {
+		t.Fatalf("expected unhealthy endpoint, got %+v", report)
+	}
+
+	endpoint.Status.State = StateScaledToZero
+	report, _ = client.HealthCheck(endpoint)
+	if report.Health != HealthNotReady || report.Probed || len(probes) != 3 {
+		t.Fatalf("scaled to zero endpoint should not be probed: %+v", report)
+	}
+}
@@ -0,0 +1,483 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default timeout of inference calls, model calls are much slower than management ones
+const DefaultInferenceTimeout = 2 * time.Minute
+
+// InferenceClient calls the inference api exposed by a deployed endpoint
+type InferenceClient struct {
+	URL  string
+	Task EndpointTask
+	// Whether requests carry the bearer token, false for public endpoints
+	Authenticated bool
+
+	client *Client
+	// Context of the calls, set by WithContext
+	ctx context.Context
+}
+
+// NewInferenceClient - Create an inference client targeting the endpoint Status.URL
+func (c *Client) NewInferenceClient(endpoint EndpointWithStatus) (*InferenceClient, error) {
+	if endpoint.Status.URL == nil || *endpoint.Status.URL == "" {
+		return nil, fmt.Errorf("endpoint %s has no url (state: %s)", endpoint.Name, endpoint.Status.State)
+	}
+
+	return c.NewInferenceClientForURL(*endpoint.Status.URL, endpoint.Model.Task, endpoint.Type != TypePublic), nil
+}
+
+// NewInferenceClientForURL - Create an inference client targeting an arbitrary url
+func (c *Client) NewInferenceClientForURL(url string, task EndpointTask, authenticated bool) *InferenceClient {
+	inferenceClient := *c
+	inferenceClient.Client = &http.Client{Timeout: DefaultInferenceTimeout}
+	if c.Client != nil {
+		inferenceClient.Client.Transport = c.Client.Transport
+	}
+
+	return &InferenceClient{
+		URL:           strings.TrimRight(url, "/"),
+		Task:          task,
+		Authenticated: authenticated,
+		client:        &inferenceClient,
+	}
+}
+
+// SetTimeout - Override the timeout of inference calls (0 disables it)
+func (ic *InferenceClient) SetTimeout(timeout time.Duration) {
+	ic.client.Client.Timeout = timeout
+}
+
+// compatibleTasks lists the endpoint tasks able to serve each inference task
+var compatibleTasks = map[EndpointTask][]EndpointTask{
+	TaskTextGeneration:      {TaskTextGeneration, "text2text-generation", "conversational"},
+	TaskTextClassification:  {TaskTextClassification, "sentiment-analysis", "zero-shot-classification"},
+	TaskTokenClassification: {TaskTokenClassification, "ner"},
+	TaskSummarization:       {TaskSummarization, "text2text-generation"},
+	TaskTranslation:         {TaskTranslation, "text2text-generation"},
+	TaskFillMask:            {TaskFillMask},
+	TaskFeatureExtraction:   {TaskFeatureExtraction, TaskSentenceEmbeddings},
+	TaskImageClassification: {TaskImageClassification},
+	TaskTextToImage:         {TaskTextToImage},
+}
+
+// checkTask - error when the endpoint is known to serve another task
+func (ic *InferenceClient) checkTask(task EndpointTask) error {
+	if ic.Task == "" || ic.Task == "custom" {
+		return nil
+	}
+
+	for _, compatible := range compatibleTasks[task] {
+		if ic.Task == compatible {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("endpoint task is %s, cannot run %s", ic.Task, task)
+}
+
+// WithContext - copy of the client whose calls are bound to ctx
+func (ic *InferenceClient) WithContext(ctx context.Context) *InferenceClient {
+	bound := *ic
+	bound.ctx = ctx
+
+	return &bound
+}
+
+func (ic *InferenceClient) context() context.Context {
+	if ic.ctx == nil {
+		return context.Background()
+	}
+
+	return ic.ctx
+}
+
+// Post - send a raw payload to a route of the endpoint and return the response body
+func (ic *InferenceClient) Post(path, contentType string, body io.Reader) ([]byte, error) {
+	return ic.PostContext(ic.context(), path, contentType, body)
+}
+
+// PostContext - Post bound to a context, for cancellable calls
+func (ic *InferenceClient) PostContext(ctx context.Context, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", ic.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	if ic.Authenticated {
+		return ic.client.doAuthRequest(req)
+	}
+
+	return ic.client.doRequest(req, nil)
+}
+
+// PostJSON - send a json payload to a route of the endpoint and decode the json response into result
+func (ic *InferenceClient) PostJSON(path string, payload interface{}, result interface{}) error {
+	body, err := ic.postJSONBody(path, payload)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+// Get - call a GET route of the endpoint and return the response body
+func (ic *InferenceClient) Get(path string) ([]byte, error) {
+	return ic.GetContext(ic.context(), path)
+}
+
+// GetContext - Get bound to a context, for cancellable calls
+func (ic *InferenceClient) GetContext(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", ic.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if ic.Authenticated {
+		return ic.client.doAuthRequest(req)
+	}
+
+	return ic.client.doRequest(req, nil)
+}
+
+// PostStream - send a json payload to a streaming route of the endpoint, caller must close the reader
+func (ic *InferenceClient) PostStream(path string, payload interface{}) (io.ReadCloser, error) {
+	rb, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ic.context(), "POST", ic.URL+path, bytes.NewReader(rb))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Streams are bounded by the caller, not by the client timeout
+	streamClient := *ic.client
+	streamClient.Client = &http.Client{Transport: ic.client.Client.Transport}
+
+	if ic.Authenticated {
+		return streamClient.doAuthStreamRequest(req)
+	}
+
+	return streamClient.doStreamRequest(req, nil)
+}
+
+type TextGenerationRequest struct {
+	Inputs     string                    `json:"inputs"`
+	Parameters *TextGenerationParameters `json:"parameters,omitempty"`
+}
+
+type TextGenerationParameters struct {
+	MaxNewTokens      *int     `json:"max_new_tokens,omitempty"`
+	Temperature       *float64 `json:"temperature,omitempty"`
+	TopK              *int     `json:"top_k,omitempty"`
+	TopP              *float64 `json:"top_p,omitempty"`
+	RepetitionPenalty *float64 `json:"repetition_penalty,omitempty"`
+	DoSample          *bool    `json:"do_sample,omitempty"`
+	ReturnFullText    *bool    `json:"return_full_text,omitempty"`
+	Stop              []string `json:"stop,omitempty"`
+	Seed              *int64   `json:"seed,omitempty"`
+}
+
+type TextGenerationResponse struct {
+	GeneratedText string `json:"generated_text"`
+}
+
+type TextClassificationRequest struct {
+	Inputs     string                        `json:"inputs"`
+	Parameters *TextClassificationParameters `json:"parameters,omitempty"`
+}
+
+type TextClassificationParameters struct {
+	TopK            *int     `json:"top_k,omitempty"`
+	FunctionToApply *string  `json:"function_to_apply,omitempty"`
+	CandidateLabels []string `json:"candidate_labels,omitempty"`
+}
+
+type ClassificationResult struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+type TokenClassificationRequest struct {
+	Inputs     string                         `json:"inputs"`
+	Parameters *TokenClassificationParameters `json:"parameters,omitempty"`
+}
+
+type TokenClassificationParameters struct {
+	AggregationStrategy *string  `json:"aggregation_strategy,omitempty"`
+	IgnoreLabels        []string `json:"ignore_labels,omitempty"`
+}
+
+type TokenClassificationResult struct {
+	EntityGroup string  `json:"entity_group,omitempty"`
+	Entity      string  `json:"entity,omitempty"`
+	Score       float64 `json:"score"`
+	Word        string  `json:"word"`
+	Start       *int    `json:"start,omitempty"`
+	End         *int    `json:"end,omitempty"`
+}
+
+type SummarizationRequest struct {
+	Inputs     string                   `json:"inputs"`
+	Parameters *SummarizationParameters `json:"parameters,omitempty"`
+}
+
+type SummarizationParameters struct {
+	MinLength  *int    `json:"min_length,omitempty"`
+	MaxLength  *int    `json:"max_length,omitempty"`
+	Truncation *string `json:"truncation,omitempty"`
+}
+
+type SummarizationResponse struct {
+	SummaryText string `json:"summary_text"`
+}
+
+type TranslationRequest struct {
+	Inputs     string                 `json:"inputs"`
+	Parameters *TranslationParameters `json:"parameters,omitempty"`
+}
+
+type TranslationParameters struct {
+	SrcLang   *string `json:"src_lang,omitempty"`
+	TgtLang   *string `json:"tgt_lang,omitempty"`
+	MaxLength *int    `json:"max_length,omitempty"`
+}
+
+type TranslationResponse struct {
+	TranslationText string `json:"translation_text"`
+}
+
+type FillMaskRequest struct {
+	Inputs     string              `json:"inputs"`
+	Parameters *FillMaskParameters `json:"parameters,omitempty"`
+}
+
+type FillMaskParameters struct {
+	TopK    *int     `json:"top_k,omitempty"`
+	Targets []string `json:"targets,omitempty"`
+}
+
+type FillMaskResult struct {
+	Score    float64 `json:"score"`
+	Token    int     `json:"token"`
+	TokenStr string  `json:"token_str"`
+	Sequence string  `json:"sequence"`
+}
+
+type FeatureExtractionRequest struct {
+	Inputs     []string                     `json:"inputs"`
+	Parameters *FeatureExtractionParameters `json:"parameters,omitempty"`
+}
+
+type FeatureExtractionParameters struct {
+	Normalize *bool `json:"normalize,omitempty"`
+	Truncate  *bool `json:"truncate,omitempty"`
+}
+
+type TextToImageRequest struct {
+	Inputs     string                 `json:"inputs"`
+	Parameters *TextToImageParameters `json:"parameters,omitempty"`
+}
+
+type TextToImageParameters struct {
+	NegativePrompt    string   `json:"negative_prompt,omitempty"`
+	Width             *int     `json:"width,omitempty"`
+	Height            *int     `json:"height,omitempty"`
+	NumInferenceSteps *int     `json:"num_inference_steps,omitempty"`
+	GuidanceScale     *float64 `json:"guidance_scale,omitempty"`
+	Seed              *int64   `json:"seed,omitempty"`
+}
+
+// TextGeneration - Run the text-generation task
+func (ic *InferenceClient) TextGeneration(request TextGenerationRequest) ([]TextGenerationResponse, error) {
+	if err := ic.checkTask(TaskTextGeneration); err != nil {
+		return nil, err
+	}
+
+	var result []TextGenerationResponse
+	err := ic.PostJSON("", request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TextClassification - Run the text-classification task
+func (ic *InferenceClient) TextClassification(request TextClassificationRequest) ([]ClassificationResult, error) {
+	if err := ic.checkTask(TaskTextClassification); err != nil {
+		return nil, err
+	}
+
+	body, err := ic.postJSONBody("", request)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeClassification(body)
+}
+
+// TokenClassification - Run the token-classification task
+func (ic *InferenceClient) TokenClassification(request TokenClassificationRequest) ([]TokenClassificationResult, error) {
+	if err := ic.checkTask(TaskTokenClassification); err != nil {
+		return nil, err
+	}
+
+	var result []TokenClassificationResult
+	err := ic.PostJSON("", request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Summarization - Run the summarization task
+func (ic *InferenceClient) Summarization(request SummarizationRequest) ([]SummarizationResponse, error) {
+	if err := ic.checkTask(TaskSummarization); err != nil {
+		return nil, err
+	}
+
+	var result []SummarizationResponse
+	err := ic.PostJSON("", request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Translation - Run the translation task
+func (ic *InferenceClient) Translation(request TranslationRequest) ([]TranslationResponse, error) {
+	if err := ic.checkTask(TaskTranslation); err != nil {
+		return nil, err
+	}
+
+	var result []TranslationResponse
+	err := ic.PostJSON("", request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FillMask - Run the fill-mask task
+func (ic *InferenceClient) FillMask(request FillMaskRequest) ([]FillMaskResult, error) {
+	if err := ic.checkTask(TaskFillMask); err != nil {
+		return nil, err
+	}
+
+	var result []FillMaskResult
+	err := ic.PostJSON("", request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FeatureExtraction - Run the feature-extraction task, returning one pooled vector per input
+func (ic *InferenceClient) FeatureExtraction(request FeatureExtractionRequest) ([][]float64, error) {
+	if err := ic.checkTask(TaskFeatureExtraction); err != nil {
+		return nil, err
+	}
+
+	body, err := ic.postJSONBody("", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var vectors [][]float64
+	if err := json.Unmarshal(body, &vectors); err == nil {
+		return vectors, nil
+	}
+
+	// Single input answered with a flat vector
+	var vector []float64
+	if err := json.Unmarshal(body, &vector); err != nil {
+		return nil, fmt.Errorf("unexpected feature-extraction response: %w", err)
+	}
+
+	return [][]float64{vector}, nil
+}
+
+// ImageClassification - Run the image-classification task on raw image bytes
+func (ic *InferenceClient) ImageClassification(image []byte, contentType string) ([]ClassificationResult, error) {
+	if err := ic.checkTask(TaskImageClassification); err != nil {
+		return nil, err
+	}
+
+	if len(image) == 0 {
+		return nil, errors.New("image is empty")
+	}
+
+	if contentType == "" {
+		contentType = http.DetectContentType(image)
+	}
+
+	body, err := ic.Post("", contentType, bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeClassification(body)
+}
+
+// TextToImage - Run the text-to-image task, returning the image bytes
+func (ic *InferenceClient) TextToImage(request TextToImageRequest) ([]byte, error) {
+	if err := ic.checkTask(TaskTextToImage); err != nil {
+		return nil, err
+	}
+
+	if request.Inputs == "" {
+		return nil, errors.New("prompt is empty")
+	}
+
+	return ic.postJSONBody("", request)
+}
+
+func (ic *InferenceClient) postJSONBody(path string, payload interface{}) ([]byte, error) {
+	rb, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return ic.Post(path, "application/json", bytes.NewReader(rb))
+}
+
+// decodeClassification - accept both flat and per-input nested label lists
+func decodeClassification(body []byte) ([]ClassificationResult, error) {
+	var result []ClassificationResult
+	if err := json.Unmarshal(body, &result); err == nil {
+		return result, nil
+	}
+
+	var nested [][]ClassificationResult
+	if err := json.Unmarshal(body, &nested); err != nil {
+		return nil, fmt.Errorf("unexpected classification response: %w", err)
+	}
+
+	if len(nested) == 0 {
+		return nil, nil
+	}
+
+	return nested[0], nil
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func testEndpoint(endpointType EndpointType, task EndpointTask) EndpointWithStatus {
+	url := "https://test.endpoints.huggingface.cloud/"
+	return EndpointWithStatus{
+		Name:   "endpoint",
+		Type:   endpointType,
+		Model:  EndpointModel{Task: task},
+		Status: EndpointStatus{State: StateRunning, URL: &url},
+	}
+}
+
+func TestNewInferenceClientRequiresURL(t *testing.T) {
+	client := newTestClient(nil)
+
+	if _, err := client.NewInferenceClient(EndpointWithStatus{Name: "pending"}); err == nil {
+		t.Fatalf("expected error for endpoint without url")
+	}
+}
+
+func TestInferenceAuthentication(t *testing.T) {
+	var authorization string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		authorization = req.Header.Get("Authorization")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`[{"generated_text":"hello"}]`)),
+		}
+	})
+
+	protected, _ := client.NewInferenceClient(testEndpoint(TypeProtected, TaskTextGeneration))
+	if _, err := protected.TextGeneration(TextGenerationRequest{Inputs: "hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if authorization != "Bearer fake-token" {
+		t.Fatalf("protected endpoint should receive token, got %q", authorization)
+	}
+
+	public, _ := client.NewInferenceClient(testEndpoint(TypePublic, TaskTextGeneration))
+	if _, err := public.TextGeneration(TextGenerationRequest{Inputs: "hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if authorization != "" {
+		t.Fatalf("public endpoint should not receive token, got %q", authorization)
+	}
+}
+
+func TestInferenceTaskMismatch(t *testing.T) {
+	client := newTestClient(nil)
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, TaskFillMask))
+	if _, err := inference.Summarization(SummarizationRequest{Inputs: "text"}); err == nil {
+		t.Fatalf("expected error when calling summarization on a fill-mask endpoint")
+	}
+}
+
+func TestTextClassificationNested(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`[[{"label":"POSITIVE","score":0.9}]]`)),
+		}
+	})
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, TaskTextClassification))
+	result, err := inference.TextClassification(TextClassificationRequest{Inputs: "great"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result) != 1 || result[0].Label != "POSITIVE" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestFeatureExtraction(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`[0.1,0.2,0.3]`)),
+		}
+	})
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, TaskSentenceEmbeddings))
+	vectors, err := inference.FeatureExtraction(FeatureExtractionRequest{Inputs: []string{"text"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(vectors) != 1 || len(vectors[0]) != 3 {
+		t.Fatalf("unexpected vectors: %+v", vectors)
+	}
+}
+
+func TestImageClassification(t *testing.T) {
+	var contentType string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		contentType = req.Header.Get("Content-Type")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`[{"label":"cat","score":0.8}]`)),
+		}
+	})
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, TaskImageClassification))
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	result, err := inference.ImageClassification(png, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if contentType != "image/png" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+
+	if len(result) != 1 || result[0].Label != "cat" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	DefaultLoadTestConcurrency = 8
+	DefaultLoadTestDuration    = time.Minute
+)
+
+// Upper bounds, in milliseconds, of the latency histogram buckets
+var LatencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// PayloadTemplate renders a request payload per load test request. Templates use the
+// text/template syntax with .Index (request number), .Line (next line of the inputs,
+// cycling) and the functions randInt MIN MAX, randFloat and pick "a" "b" ...
+type PayloadTemplate struct {
+	template *template.Template
+	lines    []string
+	random   *rand.Rand
+	mu       sync.Mutex
+}
+
+type payloadData struct {
+	Index int
+	Line  string
+}
+
+// NewPayloadTemplate - parse a payload template, lines feed the .Line field (optional)
+func NewPayloadTemplate(text string, lines []string) (*PayloadTemplate, error) {
+	pt := &PayloadTemplate{lines: lines, random: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+	funcs := template.FuncMap{
+		"randInt": func(min, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + pt.random.Intn(max-min)
+		},
+		"randFloat": func() float64 {
+			return pt.random.Float64()
+		},
+		"pick": func(values ...string) string {
+			if len(values) == 0 {
+				return ""
+			}
+			return values[pt.random.Intn(len(values))]
+		},
+		// JSON string, Go escapes as \x01 are not valid JSON
+		"quote": func(s string) string {
+			quoted, _ := json.Marshal(s)
+			return string(quoted)
+		},
+	}
+
+	tmpl, err := template.New("payload").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	pt.template = tmpl
+
+	return pt, nil
+}
+
+// Render - payload of the request number index
+func (pt *PayloadTemplate) Render(index int) ([]byte, error) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	data := payloadData{Index: index}
+	if len(pt.lines) > 0 {
+		data.Line = pt.lines[index%len(pt.lines)]
+	}
+
+	var buf bytes.Buffer
+	if err := pt.template.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LoadTestOptions configures a load test. With Rate set, requests are started at
+// a fixed rate (open loop) with at most Concurrency in flight, otherwise Concurrency
+// workers send requests back to back (closed loop).
+type LoadTestOptions struct {
+	Route       string
+	Payload     *PayloadTemplate
+	Duration    time.Duration
+	Rate        float64
+	Concurrency int
+	// Stop after this many requests, 0 for no limit
+	MaxRequests int
+}
+
+type HistogramBucket struct {
+	// Upper bound in milliseconds, 0 for the overflow bucket
+	UpperMs float64 `json:"upperMs"`
+	Count   int     `json:"count"`
+}
+
+// LoadTestReport is the outcome of a load test
+type LoadTestReport struct {
+	Start       time.Time         `json:"start"`
+	Stop        time.Time         `json:"stop"`
+	Duration    string            `json:"duration"`
+	Mode        string            `json:"mode"`
+	Requests    int               `json:"requests"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Dropped     int               `json:"dropped"`
+	ErrorRate   float64           `json:"errorRate"`
+	Throughput  float64           `json:"throughput"`
+	Latency     LatencyStats      `json:"latency"`
+	Histogram   []HistogramBucket `json:"histogram"`
+	StatusCodes map[string]int    `json:"statusCodes"`
+	Errors      map[string]int    `json:"errors,omitempty"`
+	// Endpoint metrics over the run window, filled by CorrelateMetrics
+	Metrics map[string][]MetricSummary `json:"metrics,omitempty"`
+}
+
+type loadTestSample struct {
+	latency time.Duration
+	status  string
+	err     error
+}
+
+// RunLoadTest - drive requests against the endpoint and report latencies, throughput and errors
+func (ic *InferenceClient) RunLoadTest(ctx context.Context, options LoadTestOptions) (*LoadTestReport, error) {
+	if options.Payload == nil {
+		return nil, errors.New("payload template is required")
+	}
+	if options.Duration <= 0 {
+		options.Duration = DefaultLoadTestDuration
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = DefaultLoadTestConcurrency
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.Duration)
+	defer cancel()
+
+	report := &LoadTestReport{
+		Start:       time.Now(),
+		Mode:        "closed-loop",
+		StatusCodes: map[string]int{},
+		Errors:      map[string]int{},
+	}
+	if options.Rate > 0 {
+		report.Mode = "open-loop"
+	}
+
+	samples := make(chan loadTestSample, options.Concurrency)
+	var counter int
+	var counterMu sync.Mutex
+
+	// next - reserve the index of the next request, false once MaxRequests is reached
+	next := func() (int, bool) {
+		counterMu.Lock()
+		defer counterMu.Unlock()
+		if options.MaxRequests > 0 && counter >= options.MaxRequests {
+			return 0, false
+		}
+		counter++
+		return counter - 1, true
+	}
+
+	send := func(index int) {
+		sample := loadTestSample{status: "error"}
+		payload, err := options.Payload.Render(index)
+		if err != nil {
+			sample.err = err
+			samples <- sample
+			return
+		}
+
+		start := time.Now()
+		_, err = ic.PostContext(ctx, options.Route, "application/json", bytes.NewReader(payload))
+		sample.latency = time.Since(start)
+		sample.err = err
+
+		var httpErr *HTTPError
+		switch {
+		case err == nil:
+			sample.status = "200"
+		case errors.As(err, &httpErr):
+			sample.status = strconv.Itoa(httpErr.StatusCode)
+		}
+
+		// Requests cut by the end of the run are not counted
+		if ctx.Err() != nil && err != nil {
+			return
+		}
+		samples <- sample
+	}
+
+	var wg sync.WaitGroup
+	dropped := 0
+
+	if options.Rate > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			inFlight := make(chan struct{}, options.Concurrency)
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / options.Rate))
+			defer ticker.Stop()
+
+			var requests sync.WaitGroup
+			defer requests.Wait()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+
+				index, ok := next()
+				if !ok {
+					return
+				}
+
+				select {
+				case inFlight <- struct{}{}:
+				default:
+					// Client side saturation, the target rate cannot be sustained
+					dropped++
+					continue
+				}
+
+				requests.Add(1)
+				go func(index int) {
+					defer requests.Done()
+					defer func() { <-inFlight }()
+					send(index)
+				}(index)
+			}
+		}()
+	} else {
+		for i := 0; i < options.Concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for ctx.Err() == nil {
+					index, ok := next()
+					if !ok {
+						return
+					}
+					send(index)
+				}
+			}()
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	var latencies []time.Duration
+	histogram := make([]int, len(LatencyBuckets)+1)
+
+	for sample := range samples {
+		report.Requests++
+		report.StatusCodes[sample.status]++
+
+		if sample.err != nil {
+			report.Failed++
+			report.Errors[errorKind(sample.err)]++
+			continue
+		}
+
+		report.Succeeded++
+		latencies = append(latencies, sample.latency)
+
+		ms := float64(sample.latency) / float64(time.Millisecond)
+		bucket := len(LatencyBuckets)
+		for i, upper := range LatencyBuckets {
+			if ms <= upper {
+				bucket = i
+				break
+			}
+		}
+		histogram[bucket]++
+	}
+
+	report.Stop = time.Now()
+	elapsed := report.Stop.Sub(report.Start)
+	report.Duration = elapsed.Round(time.Millisecond).String()
+	report.Dropped = dropped
+	report.Latency = NewLatencyStats(latencies)
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Failed) / float64(report.Requests)
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(report.Succeeded) / elapsed.Seconds()
+	}
+
+	for i, count := range histogram {
+		bucket := HistogramBucket{Count: count}
+		if i < len(LatencyBuckets) {
+			bucket.UpperMs = LatencyBuckets[i]
+		}
+		report.Histogram = append(report.Histogram, bucket)
+	}
+
+	return report, nil
+}
+
+// errorKind - short error label used to group load test failures
+func errorKind(err error) string {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return fmt.Sprintf("HTTP %d", httpErr.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	message := err.Error()
+	if len(message) > 80 {
+		message = message[:80]
+	}
+
+	return message
+}
+
+// CorrelateMetrics - attach the summaries of endpoint metrics over the report
+// window, widened by margin to account for the metrics scraping interval
+func (c *Client) CorrelateMetrics(report *LoadTestReport, namespace, name string, metrics []string, margin time.Duration) error {
+	report.Metrics = map[string][]MetricSummary{}
+	step := "1m"
+
+	var errs []string
+	for _, metric := range metrics {
+		series, err := c.GetEndpointMetricSeries(namespace, name, metric, report.Start.Add(-margin), report.Stop.Add(margin), &step)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", metric, err))
+			continue
+		}
+
+		for _, s := range series {
+			report.Metrics[metric] = append(report.Metrics[metric], s.Summary())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not fetch metrics: %v", errs)
+	}
+
+	return nil
+}
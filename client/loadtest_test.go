@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPayloadTemplate(t *testing.T) {
+	pt, err := NewPayloadTemplate(`{"inputs": {{quote .Line}}, "n": {{.Index}}, "k": {{randInt 1 2}}}`, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	payload, _ := pt.Render(3)
+	if string(payload) != `{"inputs": "b", "n": 3, "k": 1}` {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+
+	pt, _ = NewPayloadTemplate(`{"inputs": {{quote .Line}}}`, []string{"bell\x07 del\x7f \"quoted\""})
+	payload, _ = pt.Render(0)
+	var decoded struct{ Inputs string }
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.Inputs != "bell\x07 del\x7f \"quoted\"" {
+		t.Fatalf("expected a valid json string, got %s (%v)", payload, err)
+	}
+}
+
+func TestRunLoadTest(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		if strings.Contains(string(body), `"n": 1`) {
+			return &http.Response{StatusCode: 500, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("boom"))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("{}"))}
+	})
+
+	inference, _ := client.NewInferenceClient(testEndpoint(TypeProtected, ""))
+	payload, _ := NewPayloadTemplate(`{"n": {{.Index}}}`, nil)
+
+	report, err := inference.RunLoadTest(context.Background(), LoadTestOptions{
+		Payload:     payload,
+		Duration:    5 * time.Second,
+		Concurrency: 2,
+		MaxRequests: 10,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if report.Requests != 10 || report.Succeeded != 9 || report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if report.StatusCodes["500"] != 1 || report.Errors["HTTP 500"] != 1 || report.ErrorRate != 0.1 {
+		t.Fatalf("unexpected errors: %+v %+v", report.StatusCodes, report.Errors)
+	}
+
+	histogramTotal := 0
+	for _, bucket := range report.Histogram {
+		histogramTotal += bucket.Count
+	}
+	if histogramTotal != 9 {
+		t.Fatalf("histogram should count successful requests, got %d", histogramTotal)
+	}
+}
+
+func TestParseMetricSeries(t *testing.T) {
+	cases := map[string]int{
+		`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"replica":"a"},"values":[[1700000060,"2"],[1700000000,"1"]]}]}}`: 2,
+		`[[1700000000000, 1.5], [1700000060000, null]]`:             1,
+		`{"data":[{"timestamp":"2024-01-01T00:00:00Z","value":3}]}`: 1,
+	}
+
+	for data, points := range cases {
+		series, err := ParseMetricSeries([]byte(data))
+		if err != nil {
+			t.Fatalf("expected no error for %s, got %v", data, err)
+		}
+		if len(series) != 1 || len(series[0].Points) != points {
+			t.Fatalf("unexpected series for %s: %+v", data, series)
+		}
+	}
+
+	series, _ := ParseMetricSeries([]byte(`{"data":{"result":[{"metric":{"replica":"a"},"values":[[1700000060,"2"],[1700000000,"1"]]}]}}`))
+	summary := series[0].Summary()
+	if series[0].Labels["replica"] != "a" || summary.Last != 2 || summary.Min != 1 || summary.From.Unix() != 1700000000 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	if _, err := ParseMetricSeries([]byte(`{"unexpected": true}`)); err == nil {
+		t.Fatalf("expected error for unrecognized response")
+	}
+}
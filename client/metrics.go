@@ -0,0 +1,383 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricPoint is a single sample of a metric series
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// MetricSeries is a metric time series, labels identify the series when
+// the api returns several of them (e.g. one per replica)
+type MetricSeries struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Points []MetricPoint     `json:"points"`
+}
+
+// MetricSummary aggregates the points of a series
+type MetricSummary struct {
+	Points int       `json:"points"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+	Mean   float64   `json:"mean"`
+	Last   float64   `json:"last"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// Summary - min, max, mean and last value of the series
+func (s MetricSeries) Summary() MetricSummary {
+	summary := MetricSummary{Points: len(s.Points)}
+	if len(s.Points) == 0 {
+		return summary
+	}
+
+	summary.Min = math.Inf(1)
+	summary.Max = math.Inf(-1)
+	total := 0.0
+	for _, point := range s.Points {
+		summary.Min = math.Min(summary.Min, point.Value)
+		summary.Max = math.Max(summary.Max, point.Value)
+		total += point.Value
+	}
+	summary.Mean = total / float64(len(s.Points))
+	summary.Last = s.Points[len(s.Points)-1].Value
+	summary.From = s.Points[0].Time
+	summary.To = s.Points[len(s.Points)-1].Time
+
+	return summary
+}
+
+// GetEndpointMetricSeries - Get a metric from an endpoint parsed as time series
+func (c *Client) GetEndpointMetricSeries(namespace, name, metricType string, from, to time.Time, step *string) ([]MetricSeries, error) {
+	data, err := c.GetEndpointMetric(namespace, name, metricType, MetricRequest{
+		From: uint32(from.Unix()),
+		To:   uint32(to.Unix()),
+		Step: step,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMetricSeries(data)
+}
+
+// ParseMetricSeries - parse a metric response into time series. Prometheus style
+// matrices ({"data":{"result":[{"metric":{},"values":[[ts,"v"]]}]}}), lists of
+// [timestamp, value] pairs and lists of {"timestamp","value"} objects are accepted.
+func ParseMetricSeries(data []byte) ([]MetricSeries, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	series, ok := findSeries(raw, nil)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized metric response: %.200s", string(data))
+	}
+
+	for i := range series {
+		sort.Slice(series[i].Points, func(a, b int) bool {
+			return series[i].Points[a].Time.Before(series[i].Points[b].Time)
+		})
+	}
+
+	return series, nil
+}
+
+func findSeries(raw interface{}, labels map[string]string) ([]MetricSeries, bool) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if metric, ok := v["metric"].(map[string]interface{}); ok {
+			labels = map[string]string{}
+			for key, value := range metric {
+				labels[key] = fmt.Sprint(value)
+			}
+		}
+		for _, key := range []string{"values", "points", "result", "data", "series", "items"} {
+			if inner, ok := v[key]; ok {
+				if series, ok := findSeries(inner, labels); ok {
+					return series, true
+				}
+			}
+		}
+		// Single sample object
+		if point, ok := parsePoint(v); ok {
+			return []MetricSeries{{Labels: labels, Points: []MetricPoint{point}}}, true
+		}
+		return nil, false
+	case []interface{}:
+		if len(v) == 0 {
+			return []MetricSeries{}, true
+		}
+
+		points := make([]MetricPoint, 0, len(v))
+		parsed := 0
+		for _, item := range v {
+			point, ok := parsePoint(item)
+			if !ok {
+				break
+			}
+			parsed++
+			// Missing samples are sent as null
+			if !math.IsNaN(point.Value) {
+				points = append(points, point)
+			}
+		}
+		if parsed == len(v) {
+			return []MetricSeries{{Labels: labels, Points: points}}, true
+		}
+
+		// List of series
+		var all []MetricSeries
+		for _, item := range v {
+			series, ok := findSeries(item, labels)
+			if !ok {
+				return nil, false
+			}
+			all = append(all, series...)
+		}
+		return all, true
+	}
+
+	return nil, false
+}
+
+func parsePoint(raw interface{}) (MetricPoint, bool) {
+	switch v := raw.(type) {
+	case []interface{}:
+		if len(v) != 2 {
+			return MetricPoint{}, false
+		}
+		t, ok := parseTimestamp(v[0])
+		if !ok {
+			return MetricPoint{}, false
+		}
+		value, ok := parseValue(v[1])
+		return MetricPoint{Time: t, Value: value}, ok
+	case map[string]interface{}:
+		var t time.Time
+		found := false
+		for _, key := range []string{"timestamp", "time", "ts", "date", "x"} {
+			if rawTime, ok := v[key]; ok {
+				t, found = parseTimestamp(rawTime)
+				break
+			}
+		}
+		if !found {
+			return MetricPoint{}, false
+		}
+		for _, key := range []string{"value", "y", "v"} {
+			if rawValue, ok := v[key]; ok {
+				value, ok := parseValue(rawValue)
+				return MetricPoint{Time: t, Value: value}, ok
+			}
+		}
+	}
+
+	return MetricPoint{}, false
+}
+
+func parseTimestamp(raw interface{}) (time.Time, bool) {
+	switch v := raw.(type) {
+	case float64:
+		// Milliseconds when beyond year 33658 in seconds
+		if v > 1e12 {
+			return time.Unix(0, int64(v*float64(time.Millisecond))).UTC(), true
+		}
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC(), true
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return parseTimestamp(f)
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseValue(raw interface{}) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	case nil:
+		return math.NaN(), true
+	}
+
+	return 0, false
+}
+
+// Maximum number of points requested per metric query, longer ranges are split in chunks
+const MaxMetricChunkPoints = 1000
+
+// GetEndpointMetricRange - Get a metric over a range of any length, split in chunks of at
+// most MaxMetricChunkPoints steps (or chunk when set). Chunks are merged per series.
+func (c *Client) GetEndpointMetricRange(namespace, name, metricType string, from, to time.Time, step, chunk time.Duration) ([]MetricSeries, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid step: %s", step)
+	}
+	if chunk <= 0 {
+		chunk = step * MaxMetricChunkPoints
+	}
+	stepText := FormatMetricStep(step)
+
+	var merged []MetricSeries
+	index := map[string]int{}
+	for start := from; start.Before(to); start = start.Add(chunk) {
+		stop := start.Add(chunk)
+		if stop.After(to) {
+			stop = to
+		}
+
+		series, err := c.GetEndpointMetricSeries(namespace, name, metricType, start, stop, &stepText)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch %s from %s to %s: %w", metricType, start.Format(time.RFC3339), stop.Format(time.RFC3339), err)
+		}
+
+		for _, s := range series {
+			key := labelsKey(s.Labels)
+			i, ok := index[key]
+			if !ok {
+				i = len(merged)
+				index[key] = i
+				merged = append(merged, MetricSeries{Labels: s.Labels})
+			}
+			merged[i].Points = append(merged[i].Points, s.Points...)
+		}
+	}
+
+	for i := range merged {
+		merged[i].Points = dedupePoints(merged[i].Points)
+	}
+
+	return merged, nil
+}
+
+// FormatMetricStep - step in the api notation (30s, 5m, 1h)
+func FormatMetricStep(step time.Duration) string {
+	switch {
+	case step%time.Hour == 0:
+		return fmt.Sprintf("%dh", step/time.Hour)
+	case step%time.Minute == 0:
+		return fmt.Sprintf("%dm", step/time.Minute)
+	}
+
+	return fmt.Sprintf("%ds", step/time.Second)
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%q,", key, labels[key])
+	}
+
+	return b.String()
+}
+
+// dedupePoints - sort points and drop duplicated timestamps of chunk boundaries
+func dedupePoints(points []MetricPoint) []MetricPoint {
+	sort.SliceStable(points, func(a, b int) bool {
+		return points[a].Time.Before(points[b].Time)
+	})
+
+	deduped := points[:0]
+	for _, point := range points {
+		if len(deduped) > 0 && deduped[len(deduped)-1].Time.Equal(point.Time) {
+			deduped[len(deduped)-1] = point
+			continue
+		}
+		deduped = append(deduped, point)
+	}
+
+	return deduped
+}
+
+// MetricColumn is a named series of a MetricTable
+type MetricColumn struct {
+	Name   string
+	Series MetricSeries
+}
+
+// MetricColumns - columns of the series of a metric, named after the metric and
+// the series labels when there are several series, e.g. cpu-usage{replica="a"}
+func MetricColumns(metric string, series []MetricSeries) []MetricColumn {
+	columns := make([]MetricColumn, 0, len(series))
+	for _, s := range series {
+		name := metric
+		if len(series) > 1 && len(s.Labels) > 0 {
+			key := labelsKey(s.Labels)
+			name = metric + "{" + strings.TrimSuffix(key, ",") + "}"
+		}
+		columns = append(columns, MetricColumn{Name: name, Series: s})
+	}
+
+	return columns
+}
+
+// MetricTable aligns several series on a shared time axis, Values[row][column]
+// is nil when the series has no point at that time
+type MetricTable struct {
+	Columns []string
+	Times   []time.Time
+	Values  [][]*float64
+}
+
+// AlignMetricSeries - align columns on a time axis, point times being truncated to step
+func AlignMetricSeries(columns []MetricColumn, step time.Duration) *MetricTable {
+	table := &MetricTable{}
+	rows := map[int64]map[int]float64{}
+
+	for i, column := range columns {
+		table.Columns = append(table.Columns, column.Name)
+		for _, point := range column.Series.Points {
+			t := point.Time
+			if step > 0 {
+				t = t.Truncate(step)
+			}
+			key := t.UnixNano()
+			if rows[key] == nil {
+				rows[key] = map[int]float64{}
+			}
+			rows[key][i] = point.Value
+		}
+	}
+
+	keys := make([]int64, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+
+	for _, key := range keys {
+		values := make([]*float64, len(columns))
+		for i, value := range rows[key] {
+			v := value
+			values[i] = &v
+		}
+		table.Times = append(table.Times, time.Unix(0, key).UTC())
+		table.Values = append(table.Values, values)
+	}
+
+	return table
+}
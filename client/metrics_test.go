@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetEndpointMetricRange(t *testing.T) {
+	var requests []MetricRequest
+	client := newTestClient(func(req *http.Request) *http.Response {
+		var request MetricRequest
+		json.NewDecoder(req.Body).Decode(&request)
+		requests = append(requests, request)
+
+		// Points at both bounds, the boundary point is returned by two chunks
+		body := fmt.Sprintf(`[[%d, %d], [%d, %d]]`, request.From, request.From, request.To, request.To)
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})
+
+	from := time.Unix(1700000000, 0)
+	series, err := client.GetEndpointMetricRange("ns", "endpoint", "cpu-usage", from, from.Add(150*time.Minute), time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(requests) != 3 || *requests[0].Step != "1m" || requests[2].To != 1700000000+150*60 {
+		t.Fatalf("unexpected chunks: %+v", requests)
+	}
+
+	if len(series) != 1 || len(series[0].Points) != 4 {
+		t.Fatalf("chunks should be merged without duplicates: %+v", series)
+	}
+}
+
+func TestAlignMetricSeries(t *testing.T) {
+	base := time.Unix(1700000040, 0)
+	columns := append(
+		MetricColumns("cpu-usage", []MetricSeries{{Points: []MetricPoint{{base, 1}, {base.Add(time.Minute), 2}}}}),
+		MetricColumns("p95-latency", []MetricSeries{
+			{Labels: map[string]string{"replica": "a"}, Points: []MetricPoint{{base.Add(65 * time.Second), 10}}},
+			{Labels: map[string]string{"replica": "b"}, Points: []MetricPoint{{base, 20}}},
+		})...,
+	)
+
+	table := AlignMetricSeries(columns, time.Minute)
+
+	if len(table.Columns) != 3 || table.Columns[1] != `p95-latency{replica="a"}` {
+		t.Fatalf("unexpected columns: %v", table.Columns)
+	}
+
+	if len(table.Times) != 2 || !table.Times[0].Equal(base.Truncate(time.Minute)) {
+		t.Fatalf("unexpected time axis: %v", table.Times)
+	}
+
+	if table.Values[0][1] != nil || *table.Values[0][2] != 20 || *table.Values[1][1] != 10 || table.Values[1][2] != nil {
+		t.Fatalf("unexpected alignment: %+v", table.Values)
+	}
+}
@@ -131,6 +131,20 @@ const (
 
 type EndpointTask string
 
+const (
+	TaskTextGeneration      EndpointTask = "text-generation"
+	TaskTextClassification  EndpointTask = "text-classification"
+	TaskTokenClassification EndpointTask = "token-classification"
+	TaskSummarization       EndpointTask = "summarization"
+	TaskTranslation         EndpointTask = "translation"
+	TaskFillMask            EndpointTask = "fill-mask"
+	TaskFeatureExtraction   EndpointTask = "feature-extraction"
+	TaskSentenceEmbeddings  EndpointTask = "sentence-embeddings"
+	TaskSentenceRanking     EndpointTask = "sentence-ranking"
+	TaskImageClassification EndpointTask = "image-classification"
+	TaskTextToImage         EndpointTask = "text-to-image"
+)
+
 type EndpointModelImage struct {
 	HuggingFace       *HuggingFaceImage       `json:"huggingface,omitempty"`
 	HuggingFaceNeuron *HuggingFaceNeuronImage `json:"huggingfaceNeuron,omitempty"`
@@ -0,0 +1,266 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Average number of hours in a month, used for monthly estimates
+const HoursPerMonth = 730
+
+// Region of the catalog prices applying to every region of their vendor
+const AnyRegion = "*"
+
+// Price is the hourly price of one replica of an instance
+type Price struct {
+	Vendor       string          `json:"vendor"`
+	Region       string          `json:"region"`
+	Accelerator  AcceleratorType `json:"accelerator"`
+	InstanceType string          `json:"instanceType"`
+	InstanceSize string          `json:"instanceSize"`
+	PricePerHour float64         `json:"pricePerHour"`
+}
+
+func (p Price) key() string {
+	return strings.Join([]string{p.Vendor, p.Region, string(p.Accelerator), p.InstanceType, p.InstanceSize}, "/")
+}
+
+// PricingCatalog lists instance prices by vendor/region/accelerator/instanceType/instanceSize
+type PricingCatalog struct {
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Prices    []Price   `json:"prices"`
+
+	index map[string]Price
+}
+
+// PricingPath - default location of the pricing catalog, in the huggingface cache:
+// $HF_HOME/endpoint-pricing.json, else ~/.cache/huggingface/endpoint-pricing.json
+func PricingPath() (string, error) {
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "endpoint-pricing.json"), nil
+	}
+
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = filepath.Join(userHome, ".cache")
+	}
+
+	return filepath.Join(cacheDir, "huggingface", "endpoint-pricing.json"), nil
+}
+
+// LoadPricingCatalog - read a pricing catalog file
+func LoadPricingCatalog(path string) (*PricingCatalog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog PricingCatalog
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return nil, fmt.Errorf("invalid pricing catalog %s: %w", path, err)
+	}
+	catalog.buildIndex()
+
+	return &catalog, nil
+}
+
+// Save - write the catalog file, creating its directory
+func (p *PricingCatalog) Save(path string) error {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+func (p *PricingCatalog) buildIndex() {
+	p.index = make(map[string]Price, len(p.Prices))
+	for _, price := range p.Prices {
+		p.index[price.key()] = price
+	}
+}
+
+// Lookup - price of an endpoint compute in a vendor region, falling back to the
+// vendor price for any region
+func (p *PricingCatalog) Lookup(provider EndpointProvider, compute EndpointCompute) (Price, bool) {
+	if p.index == nil {
+		p.buildIndex()
+	}
+
+	price := Price{
+		Vendor:       provider.Vendor,
+		Region:       provider.Region,
+		Accelerator:  compute.Accelerator,
+		InstanceType: compute.InstanceType,
+		InstanceSize: compute.InstanceSize,
+	}
+	if found, ok := p.index[price.key()]; ok {
+		return found, true
+	}
+
+	price.Region = AnyRegion
+	found, ok := p.index[price.key()]
+
+	return found, ok
+}
+
+// FetchPricingCatalog - build a catalog from the computes of all the available provider regions
+func (c *Client) FetchPricingCatalog() (*PricingCatalog, error) {
+	computes, err := c.ListComputes(ComputeFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &PricingCatalog{Currency: "USD", UpdatedAt: time.Now().UTC()}
+	for _, compute := range computes {
+		catalog.Prices = append(catalog.Prices, Price{
+			Vendor:       compute.Vendor,
+			Region:       compute.Region,
+			Accelerator:  compute.Accelerator,
+			InstanceType: compute.InstanceType,
+			InstanceSize: compute.InstanceSize,
+			PricePerHour: compute.PricePerHour,
+		})
+	}
+
+	sort.SliceStable(catalog.Prices, func(i, j int) bool {
+		return catalog.Prices[i].key() < catalog.Prices[j].key()
+	})
+	catalog.buildIndex()
+
+	return catalog, nil
+}
+
+// EndpointCost is the estimated cost of an endpoint over a window
+type EndpointCost struct {
+	Namespace string        `json:"namespace"`
+	Endpoint  string        `json:"endpoint"`
+	Tags      []string      `json:"tags,omitempty"`
+	State     EndpointState `json:"state"`
+	Instance  string        `json:"instance"`
+	Priced    bool          `json:"priced"`
+	Price     float64       `json:"pricePerHour"`
+	// Average number of running replicas over the window
+	Replicas float64 `json:"replicas"`
+	// Replicas read from the running-replicas metric, or from the current status
+	ReplicasSource string  `json:"replicasSource"`
+	Hourly         float64 `json:"hourly"`
+	Monthly        float64 `json:"monthly"`
+	// Cost of the replicas which ran over the window
+	Window float64 `json:"window"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// EstimateEndpointCost - estimate the cost of an endpoint from its compute price and the
+// running-replicas metric over [from, to], the current replicas are used without metric data
+func (c *Client) EstimateEndpointCost(namespace string, endpoint EndpointWithStatus, catalog *PricingCatalog, from, to time.Time) EndpointCost {
+	cost := EndpointCost{
+		Namespace: namespace,
+		Endpoint:  endpoint.Name,
+		Tags:      endpoint.Tags,
+		State:     endpoint.Status.State,
+		Instance:  fmt.Sprintf("%s/%s %s %s", endpoint.Provider.Vendor, endpoint.Provider.Region, endpoint.Compute.InstanceType, endpoint.Compute.InstanceSize),
+	}
+
+	if price, ok := catalog.Lookup(endpoint.Provider, endpoint.Compute); ok {
+		cost.Priced = true
+		cost.Price = price.PricePerHour
+	}
+
+	step := to.Sub(from) / MaxMetricChunkPoints
+	step = step.Truncate(time.Minute)
+	if step < time.Minute {
+		step = time.Minute
+	}
+
+	series, err := c.GetEndpointMetricRange(namespace, endpoint.Name, "running-replicas", from, to, step, 0)
+	if err != nil {
+		cost.Error = err.Error()
+	}
+
+	var points int
+	for _, s := range series {
+		if len(s.Points) > 0 {
+			// Series are split per replica or revision, their means add up
+			cost.Replicas += s.Summary().Mean
+			points += len(s.Points)
+		}
+	}
+
+	cost.ReplicasSource = "metrics"
+	if points == 0 {
+		cost.ReplicasSource = "status"
+		cost.Replicas = 0
+		if endpoint.Status.State != StatePaused && endpoint.Status.State != StateScaledToZero {
+			cost.Replicas = float64(endpoint.Status.ReadyReplica)
+		}
+	}
+
+	cost.Hourly = cost.Price * cost.Replicas
+	cost.Monthly = cost.Hourly * HoursPerMonth
+	cost.Window = cost.Hourly * to.Sub(from).Hours()
+
+	return cost
+}
+
+// CostSummary is the cost of a group of endpoints
+type CostSummary struct {
+	Endpoints int     `json:"endpoints"`
+	Unpriced  int     `json:"unpriced,omitempty"`
+	Hourly    float64 `json:"hourly"`
+	Monthly   float64 `json:"monthly"`
+	Window    float64 `json:"window"`
+}
+
+func (s *CostSummary) add(cost EndpointCost) {
+	s.Endpoints++
+	if !cost.Priced {
+		s.Unpriced++
+	}
+	s.Hourly += cost.Hourly
+	s.Monthly += cost.Monthly
+	s.Window += cost.Window
+}
+
+// Tag grouping the endpoints without tags
+const Untagged = "(untagged)"
+
+// AggregateCosts - sum the costs per tag and per namespace, endpoints with several tags
+// count in each of them
+func AggregateCosts(costs []EndpointCost) (byTag, byNamespace map[string]CostSummary) {
+	byTag = map[string]CostSummary{}
+	byNamespace = map[string]CostSummary{}
+
+	for _, cost := range costs {
+		tags := cost.Tags
+		if len(tags) == 0 {
+			tags = []string{Untagged}
+		}
+		for _, tag := range tags {
+			summary := byTag[tag]
+			summary.add(cost)
+			byTag[tag] = summary
+		}
+
+		summary := byNamespace[cost.Namespace]
+		summary.add(cost)
+		byNamespace[cost.Namespace] = summary
+	}
+
+	return byTag, byNamespace
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPricingCatalog(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		body := `{"vendors":[{"name":"aws","status":"available","regions":[
+			{"name":"us-east-1","status":"available"},{"name":"eu-west-1","status":"unavailable"}]}]}`
+		if strings.HasSuffix(req.URL.Path, "/compute") {
+			body = `{"items":[{"accelerator":"gpu","instanceType":"nvidia-a10g","instanceSize":"x1","pricePerHour":1.0}]}`
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})
+
+	catalog, err := client.FetchPricingCatalog()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(catalog.Prices) != 1 || catalog.Prices[0].Vendor != "aws" || catalog.Prices[0].Region != "us-east-1" {
+		t.Fatalf("expected the price of the available region only, got %+v", catalog.Prices)
+	}
+
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	if err := catalog.Save(path); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	loaded, err := LoadPricingCatalog(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	compute := EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1"}
+	if price, ok := loaded.Lookup(EndpointProvider{Vendor: "aws", Region: "us-east-1"}, compute); !ok || price.PricePerHour != 1 {
+		t.Fatalf("expected the saved price, got %+v %t", price, ok)
+	}
+	if _, ok := loaded.Lookup(EndpointProvider{Vendor: "aws", Region: "eu-west-1"}, compute); ok {
+		t.Fatal("expected no price in another region")
+	}
+
+	loaded.Prices = append(loaded.Prices, Price{Vendor: "aws", Region: AnyRegion, Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1", PricePerHour: 1.2})
+	loaded.buildIndex()
+	if price, ok := loaded.Lookup(EndpointProvider{Vendor: "aws", Region: "eu-west-1"}, compute); !ok || price.PricePerHour != 1.2 {
+		t.Fatalf("expected the any region price, got %+v %t", price, ok)
+	}
+}
+
+func TestEstimateEndpointCost(t *testing.T) {
+	metrics := `[[1700000000, 1], [1700000060, 3]]`
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(metrics))}
+	})
+
+	catalog := &PricingCatalog{Prices: []Price{{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1", PricePerHour: 1.5}}}
+	endpoint := testEndpoint(TypeProtected, TaskTextGeneration)
+	endpoint.Tags = []string{"dev", "llm"}
+	endpoint.Provider = EndpointProvider{Vendor: "aws", Region: "us-east-1"}
+	endpoint.Compute = EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1"}
+	endpoint.Status.ReadyReplica = 1
+
+	to := time.Unix(1700000120, 0)
+	from := to.Add(-2 * time.Hour)
+
+	cost := client.EstimateEndpointCost("org", endpoint, catalog, from, to)
+	if !cost.Priced || cost.Replicas != 2 || cost.ReplicasSource != "metrics" || cost.Hourly != 3 || cost.Monthly != 3*HoursPerMonth || cost.Window != 6 {
+		t.Fatalf("unexpected cost from metrics: %+v", cost)
+	}
+
+	metrics = `[]`
+	fallback := client.EstimateEndpointCost("org", endpoint, catalog, from, to)
+	if fallback.Replicas != 1 || fallback.ReplicasSource != "status" || fallback.Hourly != 1.5 {
+		t.Fatalf("unexpected cost from status: %+v", fallback)
+	}
+
+	untagged := fallback
+	untagged.Tags = nil
+	untagged.Namespace = "other"
+	byTag, byNamespace := AggregateCosts([]EndpointCost{cost, fallback, untagged})
+	if byTag["dev"].Endpoints != 2 || byTag["dev"].Hourly != 4.5 || byTag[Untagged].Endpoints != 1 {
+		t.Fatalf("unexpected costs by tag: %+v", byTag)
+	}
+	if math.Abs(byNamespace["org"].Monthly-4.5*HoursPerMonth) > 1e-9 || byNamespace["other"].Hourly != 1.5 {
+		t.Fatalf("unexpected costs by namespace: %+v", byNamespace)
+	}
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Status of the available vendors, regions and computes
+const ProviderAvailable = "available"
+
+// ErrInvalidCompute is wrapped by the errors of ValidateCompute for vendors, regions or
+// instances missing from the catalog
+var ErrInvalidCompute = errors.New("invalid compute")
+
+// Provider is a cloud vendor hosting endpoints
+type Provider struct {
+	Name    string           `json:"name"`
+	Status  string           `json:"status"`
+	Regions []ProviderRegion `json:"regions"`
+}
+
+type ProviderRegion struct {
+	Name   string `json:"name"`
+	Label  string `json:"label,omitempty"`
+	Status string `json:"status"`
+}
+
+// ProviderCompute is an instance offered in a vendor region
+type ProviderCompute struct {
+	ID           string          `json:"id"`
+	Vendor       string          `json:"vendor"`
+	Region       string          `json:"region"`
+	Accelerator  AcceleratorType `json:"accelerator"`
+	InstanceType string          `json:"instanceType"`
+	InstanceSize string          `json:"instanceSize"`
+	// Model of the accelerator, e.g. NVIDIA A10G or Intel Sapphire Rapids
+	Architecture    string  `json:"architecture,omitempty"`
+	NumAccelerators int     `json:"numAccelerators"`
+	Vcpus           float64 `json:"vcpus,omitempty"`
+	MemoryGb        float64 `json:"memoryGb,omitempty"`
+	// Memory of all the accelerators, for gpu and neuron instances
+	GpuMemoryGb  float64 `json:"gpuMemoryGb,omitempty"`
+	PricePerHour float64 `json:"pricePerHour"`
+	Status       string  `json:"status"`
+}
+
+// Available - whether the compute can be deployed to
+func (c ProviderCompute) Available() bool {
+	return c.Status == "" || c.Status == ProviderAvailable
+}
+
+// ComputeFilter selects the computes of the catalog, empty fields match all of them
+type ComputeFilter struct {
+	Vendor       string
+	Region       string
+	Accelerator  AcceleratorType
+	InstanceType string
+	// Skip the computes which are not available
+	AvailableOnly bool
+}
+
+// Matches - whether a compute is selected by the filter
+func (f ComputeFilter) Matches(compute ProviderCompute) bool {
+	return (f.Vendor == "" || f.Vendor == compute.Vendor) &&
+		(f.Region == "" || f.Region == compute.Region) &&
+		(f.Accelerator == "" || f.Accelerator == compute.Accelerator) &&
+		(f.InstanceType == "" || f.InstanceType == compute.InstanceType) &&
+		(!f.AvailableOnly || compute.Available())
+}
+
+// ListProviders - List the vendors and regions endpoints can be deployed to
+func (c *Client) ListProviders() ([]Provider, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v2/provider", c.Host), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAuthRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Vendors []Provider `json:"vendors"`
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Vendors, nil
+}
+
+// ListProviderComputes - List the instances offered in a vendor region
+func (c *Client) ListProviderComputes(vendor, region string) ([]ProviderCompute, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v2/provider/%s/%s/compute", c.Host, vendor, region), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAuthRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Items []ProviderCompute `json:"items"`
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Items {
+		if result.Items[i].Vendor == "" {
+			result.Items[i].Vendor = vendor
+		}
+		if result.Items[i].Region == "" {
+			result.Items[i].Region = region
+		}
+	}
+
+	return result.Items, nil
+}
+
+// ListComputes - List the computes of the available regions selected by the filter, sorted
+// by vendor, region, accelerator and price
+func (c *Client) ListComputes(filter ComputeFilter) ([]ProviderCompute, error) {
+	providers, err := c.ListProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	var computes []ProviderCompute
+	for _, provider := range providers {
+		if filter.Vendor != "" && filter.Vendor != provider.Name {
+			continue
+		}
+		for _, region := range provider.Regions {
+			if (filter.Region != "" && filter.Region != region.Name) || (region.Status != "" && region.Status != ProviderAvailable) {
+				continue
+			}
+
+			regionComputes, err := c.ListProviderComputes(provider.Name, region.Name)
+			if err != nil {
+				return nil, fmt.Errorf("could not list computes of %s/%s: %w", provider.Name, region.Name, err)
+			}
+			for _, compute := range regionComputes {
+				if filter.Matches(compute) {
+					computes = append(computes, compute)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(computes, func(i, j int) bool {
+		a, b := computes[i], computes[j]
+		if a.Vendor != b.Vendor {
+			return a.Vendor < b.Vendor
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Accelerator != b.Accelerator {
+			return a.Accelerator < b.Accelerator
+		}
+		return a.PricePerHour < b.PricePerHour
+	})
+
+	return computes, nil
+}
+
+// ValidateCompute - check that the vendor, region and instance of an endpoint exist in the
+// catalog and are available. Errors for values missing from the catalog wrap ErrInvalidCompute
+// and list the accepted ones.
+func (c *Client) ValidateCompute(provider EndpointProvider, compute EndpointCompute) error {
+	providers, err := c.ListProviders()
+	if err != nil {
+		return err
+	}
+
+	var vendor *Provider
+	var vendors []string
+	for i := range providers {
+		vendors = append(vendors, providers[i].Name)
+		if providers[i].Name == provider.Vendor {
+			vendor = &providers[i]
+		}
+	}
+	if vendor == nil {
+		return fmt.Errorf("%w: unknown vendor %q, expected one of %s", ErrInvalidCompute, provider.Vendor, strings.Join(vendors, ", "))
+	}
+
+	var region *ProviderRegion
+	var regions []string
+	for i := range vendor.Regions {
+		regions = append(regions, vendor.Regions[i].Name)
+		if vendor.Regions[i].Name == provider.Region {
+			region = &vendor.Regions[i]
+		}
+	}
+	if region == nil {
+		return fmt.Errorf("%w: unknown region %q of %s, expected one of %s", ErrInvalidCompute, provider.Region, vendor.Name, strings.Join(regions, ", "))
+	}
+	if region.Status != "" && region.Status != ProviderAvailable {
+		return fmt.Errorf("%w: region %s of %s is %s", ErrInvalidCompute, region.Name, vendor.Name, region.Status)
+	}
+
+	computes, err := c.ListProviderComputes(vendor.Name, region.Name)
+	if err != nil {
+		return err
+	}
+
+	// Suggest the sizes of the instance type when it exists, else the instance types of the accelerator
+	var sizes, types []string
+	seen := map[string]bool{}
+	for _, candidate := range computes {
+		if candidate.Accelerator != compute.Accelerator {
+			continue
+		}
+		if candidate.InstanceType == compute.InstanceType {
+			if candidate.InstanceSize == compute.InstanceSize {
+				if !candidate.Available() {
+					return fmt.Errorf("%w: %s %s is %s in %s/%s", ErrInvalidCompute, compute.InstanceType, compute.InstanceSize, candidate.Status, vendor.Name, region.Name)
+				}
+				return nil
+			}
+			sizes = append(sizes, candidate.InstanceSize)
+		} else if !seen[candidate.InstanceType] {
+			seen[candidate.InstanceType] = true
+			types = append(types, candidate.InstanceType)
+		}
+	}
+
+	if len(sizes) > 0 {
+		return fmt.Errorf("%w: unknown size %s of %s in %s/%s, expected one of %s", ErrInvalidCompute, compute.InstanceSize,
+			compute.InstanceType, vendor.Name, region.Name, strings.Join(sizes, ", "))
+	}
+	if len(types) == 0 {
+		return fmt.Errorf("%w: no %s instance in %s/%s", ErrInvalidCompute, compute.Accelerator, vendor.Name, region.Name)
+	}
+
+	return fmt.Errorf("%w: unknown %s instance type %s in %s/%s, expected one of %s", ErrInvalidCompute, compute.Accelerator,
+		compute.InstanceType, vendor.Name, region.Name, strings.Join(types, ", "))
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newCatalogTestClient() *Client {
+	return newTestClient(func(req *http.Request) *http.Response {
+		body := `{"vendors":[
+			{"name":"aws","status":"available","regions":[{"name":"us-east-1","status":"available"},{"name":"eu-west-1","status":"unavailable"}]},
+			{"name":"gcp","status":"available","regions":[{"name":"us-east4","status":"available"}]}]}`
+		if strings.HasSuffix(req.URL.Path, "/compute") {
+			body = `{"items":[
+				{"accelerator":"gpu","instanceType":"nvidia-l4","instanceSize":"x1","numAccelerators":1,"gpuMemoryGb":24,"pricePerHour":0.8,"status":"available"},
+				{"accelerator":"gpu","instanceType":"nvidia-a10g","instanceSize":"x1","numAccelerators":1,"gpuMemoryGb":24,"pricePerHour":1.0,"status":"available"},
+				{"accelerator":"gpu","instanceType":"nvidia-a10g","instanceSize":"x4","numAccelerators":4,"gpuMemoryGb":96,"pricePerHour":5.0,"status":"unavailable"},
+				{"accelerator":"cpu","instanceType":"intel-spr","instanceSize":"x2","pricePerHour":0.067,"status":"available"}]}`
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})
+}
+
+func TestListComputes(t *testing.T) {
+	client := newCatalogTestClient()
+
+	computes, err := client.ListComputes(ComputeFilter{Region: "us-east-1", Accelerator: AcceleratorGPU, AvailableOnly: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(computes) != 2 || computes[0].InstanceType != "nvidia-l4" || computes[0].Vendor != "aws" || computes[1].InstanceType != "nvidia-a10g" {
+		t.Fatalf("expected the available gpus of us-east-1 sorted by price, got %+v", computes)
+	}
+
+	all, err := client.ListComputes(ComputeFilter{})
+	if err != nil || len(all) != 8 {
+		t.Fatalf("expected the computes of the 2 available regions, got %d %v", len(all), err)
+	}
+}
+
+func TestValidateCompute(t *testing.T) {
+	client := newCatalogTestClient()
+	aws := EndpointProvider{Vendor: "aws", Region: "us-east-1"}
+
+	if err := client.ValidateCompute(aws, EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1"}); err != nil {
+		t.Fatalf("expected a valid compute, got %v", err)
+	}
+
+	cases := map[string]struct {
+		provider EndpointProvider
+		compute  EndpointCompute
+	}{
+		"unknown vendor \"azure\", expected one of aws, gcp": {EndpointProvider{Vendor: "azure"}, EndpointCompute{}},
+		"unknown region \"us-west-2\"":                       {EndpointProvider{Vendor: "aws", Region: "us-west-2"}, EndpointCompute{}},
+		"region eu-west-1 of aws is unavailable":             {EndpointProvider{Vendor: "aws", Region: "eu-west-1"}, EndpointCompute{}},
+		"unknown size x2 of nvidia-a10g":                     {aws, EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x2"}},
+		"nvidia-a10g x4 is unavailable":                      {aws, EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x4"}},
+		"expected one of nvidia-l4, nvidia-a10g":             {aws, EndpointCompute{Accelerator: AcceleratorGPU, InstanceType: "nvidia-a100", InstanceSize: "x1"}},
+		"no neuron instance":                                 {aws, EndpointCompute{Accelerator: AcceleratorNeuron, InstanceType: "inf2", InstanceSize: "x1"}},
+	}
+
+	for expected, c := range cases {
+		err := client.ValidateCompute(c.provider, c.compute)
+		if !errors.Is(err, ErrInvalidCompute) || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected an invalid compute error containing %q, got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default url of the serverless inference providers router
+const RouterURL string = "https://router.huggingface.co"
+
+const (
+	DefaultRouterRetries      = 2
+	DefaultRouterRetryBackoff = time.Second
+)
+
+// InferenceProvider is a serverless provider reached through the router
+type InferenceProvider string
+
+const (
+	// First live provider of the model, in the order of the account preferences
+	InferenceProviderAuto        InferenceProvider = "auto"
+	InferenceProviderHFInference InferenceProvider = "hf-inference"
+	InferenceProviderCerebras    InferenceProvider = "cerebras"
+	InferenceProviderFalAI       InferenceProvider = "fal-ai"
+	InferenceProviderFireworks   InferenceProvider = "fireworks-ai"
+	InferenceProviderGroq        InferenceProvider = "groq"
+	InferenceProviderHyperbolic  InferenceProvider = "hyperbolic"
+	InferenceProviderNebius      InferenceProvider = "nebius"
+	InferenceProviderNovita      InferenceProvider = "novita"
+	InferenceProviderReplicate   InferenceProvider = "replicate"
+	InferenceProviderSambanova   InferenceProvider = "sambanova"
+	InferenceProviderTogether    InferenceProvider = "together"
+)
+
+// Tasks of the provider mappings
+const (
+	RouterTaskConversational    = "conversational"
+	RouterTaskFeatureExtraction = "feature-extraction"
+	RouterTaskTextToImage       = "text-to-image"
+)
+
+// ErrNoProvider is returned when no provider serves a model for a task
+var ErrNoProvider = errors.New("no inference provider")
+
+// ProviderMapping is a provider serving a model, under its own model id
+type ProviderMapping struct {
+	Provider   InferenceProvider `json:"provider"`
+	ProviderID string            `json:"providerId"`
+	// live, or staging while the provider is being onboarded
+	Status string `json:"status"`
+	Task   string `json:"task"`
+}
+
+// RouterClient calls the serverless inference providers through the router,
+// with the credentials, request types and errors of dedicated endpoints
+type RouterClient struct {
+	*InferenceClient
+	// Provider serving the calls, InferenceProviderAuto by default
+	Provider InferenceProvider
+	// Number of retries of a call failing with a retryable error
+	Retries      int
+	RetryBackoff time.Duration
+
+	// Shared by the copies bound to a context
+	mappings *providerMappings
+}
+
+type providerMappings struct {
+	mu     sync.Mutex
+	models map[string][]ProviderMapping
+}
+
+// NewRouterClient - Create a router client calling provider, auto when empty
+func (c *Client) NewRouterClient(provider InferenceProvider) *RouterClient {
+	if provider == "" {
+		provider = InferenceProviderAuto
+	}
+
+	return &RouterClient{
+		InferenceClient: c.NewInferenceClientForURL(RouterURL, "", true),
+		Provider:        provider,
+		Retries:         DefaultRouterRetries,
+		RetryBackoff:    DefaultRouterRetryBackoff,
+		mappings:        &providerMappings{models: map[string][]ProviderMapping{}},
+	}
+}
+
+// WithContext - copy of the router client whose calls and retries are bound to ctx
+func (rc *RouterClient) WithContext(ctx context.Context) *RouterClient {
+	bound := *rc
+	bound.InferenceClient = rc.InferenceClient.WithContext(ctx)
+
+	return &bound
+}
+
+// GetProviderMappings - List the providers serving a model, in the order of the account preferences
+func (rc *RouterClient) GetProviderMappings(model string) ([]ProviderMapping, error) {
+	rc.mappings.mu.Lock()
+	cached, ok := rc.mappings.models[model]
+	rc.mappings.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	query := url.Values{"expand[]": {"inferenceProviderMapping"}}
+	req, err := http.NewRequestWithContext(rc.context(), "GET", fmt.Sprintf("%s/api/models/%s?%s", rc.client.hubHost(), model, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := rc.client.doAuthRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the providers of model %s: %w", model, err)
+	}
+
+	var info struct {
+		Mapping json.RawMessage `json:"inferenceProviderMapping"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	var mappings []ProviderMapping
+	if err := json.Unmarshal(info.Mapping, &mappings); err != nil {
+		// Mappings keyed by provider, without preference order
+		var byProvider map[InferenceProvider]ProviderMapping
+		if err := json.Unmarshal(info.Mapping, &byProvider); err != nil {
+			return nil, fmt.Errorf("unexpected provider mapping of model %s: %w", model, err)
+		}
+		for provider, mapping := range byProvider {
+			mapping.Provider = provider
+			mappings = append(mappings, mapping)
+		}
+		sort.Slice(mappings, func(i, j int) bool { return mappings[i].Provider < mappings[j].Provider })
+	}
+
+	rc.mappings.mu.Lock()
+	rc.mappings.models[model] = mappings
+	rc.mappings.mu.Unlock()
+
+	return mappings, nil
+}
+
+// SelectProvider - mapping of the provider serving task for model: the client provider,
+// or the first live provider when auto
+func (rc *RouterClient) SelectProvider(model, task string) (*ProviderMapping, error) {
+	if model == "" {
+		return nil, errors.New("a model is required to call the router")
+	}
+
+	mappings, err := rc.GetProviderMappings(model)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Task != task {
+			continue
+		}
+		if rc.Provider == InferenceProviderAuto && mapping.Status == "live" || mapping.Provider == rc.Provider {
+			return &mapping, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w serves %s for %s (provider: %s)", ErrNoProvider, task, model, rc.Provider)
+}
+
+// retry - Retry with the retries of the router client
+func (rc *RouterClient) retry(fn func() error) error {
+	return Retry(rc.context(), rc.Retries, rc.RetryBackoff, fn)
+}
+
+// route - inference client of a route of the router
+func (rc *RouterClient) route(path string) *InferenceClient {
+	route := *rc.InferenceClient
+	route.URL = rc.URL + path
+
+	return &route
+}
+
+// ChatCompletion - Run a chat completion on the provider of request.Model, a hub model id
+func (rc *RouterClient) ChatCompletion(request ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	mapping, err := rc.SelectProvider(request.Model, RouterTaskConversational)
+	if err != nil {
+		return nil, err
+	}
+	// The router maps the hub model id to the provider model id
+	request.Model += ":" + string(mapping.Provider)
+
+	chat := &ChatClient{InferenceClient: rc.InferenceClient}
+	var response *ChatCompletionResponse
+	err = rc.retry(func() error {
+		response, err = chat.ChatCompletion(request)
+		return err
+	})
+
+	return response, err
+}
+
+// ChatCompletionStream - Run a streamed chat completion on the provider of request.Model, a hub model id
+func (rc *RouterClient) ChatCompletionStream(request ChatCompletionRequest) (*ChatStream, error) {
+	mapping, err := rc.SelectProvider(request.Model, RouterTaskConversational)
+	if err != nil {
+		return nil, err
+	}
+	request.Model += ":" + string(mapping.Provider)
+
+	chat := &ChatClient{InferenceClient: rc.InferenceClient}
+	var stream *ChatStream
+	err = rc.retry(func() error {
+		stream, err = chat.ChatCompletionStream(request)
+		return err
+	})
+
+	return stream, err
+}
+
+// Embeddings - Embed request.Input with the provider of request.Model, a hub model id
+func (rc *RouterClient) Embeddings(request EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	mapping, err := rc.SelectProvider(request.Model, RouterTaskFeatureExtraction)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Provider == InferenceProviderHFInference {
+		vectors, err := rc.hfFeatureExtraction(mapping, FeatureExtractionRequest{Inputs: request.Input})
+		if err != nil {
+			return nil, err
+		}
+
+		response := &EmbeddingsResponse{Object: "list", Model: request.Model}
+		for i, vector := range vectors {
+			response.Data = append(response.Data, EmbeddingData{Object: "embedding", Index: i, Embedding: vector})
+		}
+		return response, nil
+	}
+
+	// Other providers expose the OpenAI-compatible route under their own model id
+	request.Model = mapping.ProviderID
+	var response EmbeddingsResponse
+	err = rc.retry(func() error {
+		return rc.route("/"+string(mapping.Provider)+"/v1/embeddings").PostJSON("", request, &response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// FeatureExtraction - Embed request.Inputs with the provider of a hub model, one vector per input
+func (rc *RouterClient) FeatureExtraction(model string, request FeatureExtractionRequest) ([][]float64, error) {
+	mapping, err := rc.SelectProvider(model, RouterTaskFeatureExtraction)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Provider == InferenceProviderHFInference {
+		return rc.hfFeatureExtraction(mapping, request)
+	}
+
+	response, err := rc.Embeddings(EmbeddingsRequest{Input: request.Inputs, Model: model})
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float64, len(request.Inputs))
+	for _, data := range response.Data {
+		if data.Index >= 0 && data.Index < len(vectors) {
+			vectors[data.Index] = data.Embedding
+		}
+	}
+
+	return vectors, nil
+}
+
+func (rc *RouterClient) hfFeatureExtraction(mapping *ProviderMapping, request FeatureExtractionRequest) ([][]float64, error) {
+	var vectors [][]float64
+	err := rc.retry(func() error {
+		var err error
+		vectors, err = rc.route("/hf-inference/models/" + mapping.ProviderID + "/pipeline/feature-extraction").FeatureExtraction(request)
+		return err
+	})
+
+	return vectors, err
+}
+
+// TextToImage - Generate an image with the provider of a hub model, returning the image bytes
+func (rc *RouterClient) TextToImage(model string, request TextToImageRequest) ([]byte, error) {
+	if request.Inputs == "" {
+		return nil, errors.New("prompt is empty")
+	}
+
+	mapping, err := rc.SelectProvider(model, RouterTaskTextToImage)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := TextToImageParameters{}
+	if request.Parameters != nil {
+		parameters = *request.Parameters
+	}
+
+	var image []byte
+	err = rc.retry(func() error {
+		var err error
+		switch mapping.Provider {
+		case InferenceProviderHFInference:
+			image, err = rc.route("/hf-inference/models/" + mapping.ProviderID).TextToImage(request)
+		case InferenceProviderFalAI:
+			image, err = rc.falTextToImage(mapping, request.Inputs, parameters)
+		case InferenceProviderTogether, InferenceProviderNebius:
+			image, err = rc.openAITextToImage(mapping, request.Inputs, parameters)
+		default:
+			err = fmt.Errorf("text-to-image through %s is not supported", mapping.Provider)
+		}
+		return err
+	})
+
+	return image, err
+}
+
+// falTextToImage - fal-ai answers with the urls of the generated images
+func (rc *RouterClient) falTextToImage(mapping *ProviderMapping, prompt string, parameters TextToImageParameters) ([]byte, error) {
+	payload := map[string]interface{}{"prompt": prompt}
+	if parameters.NegativePrompt != "" {
+		payload["negative_prompt"] = parameters.NegativePrompt
+	}
+	if parameters.Width != nil && parameters.Height != nil {
+		payload["image_size"] = map[string]int{"width": *parameters.Width, "height": *parameters.Height}
+	}
+	if parameters.NumInferenceSteps != nil {
+		payload["num_inference_steps"] = *parameters.NumInferenceSteps
+	}
+	if parameters.GuidanceScale != nil {
+		payload["guidance_scale"] = *parameters.GuidanceScale
+	}
+	if parameters.Seed != nil {
+		payload["seed"] = *parameters.Seed
+	}
+
+	var response struct {
+		Images []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+	}
+	if err := rc.route("/fal-ai/"+mapping.ProviderID).PostJSON("", payload, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Images) == 0 {
+		return nil, errors.New("fal-ai returned no image")
+	}
+
+	req, err := http.NewRequestWithContext(rc.context(), "GET", response.Images[0].URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc.client.doRequest(req, nil)
+}
+
+// openAITextToImage - OpenAI-compatible image generation, answering with base64 images
+func (rc *RouterClient) openAITextToImage(mapping *ProviderMapping, prompt string, parameters TextToImageParameters) ([]byte, error) {
+	payload := map[string]interface{}{"prompt": prompt, "model": mapping.ProviderID, "response_format": "b64_json"}
+	if parameters.NegativePrompt != "" {
+		payload["negative_prompt"] = parameters.NegativePrompt
+	}
+	if parameters.Width != nil {
+		payload["width"] = *parameters.Width
+	}
+	if parameters.Height != nil {
+		payload["height"] = *parameters.Height
+	}
+	if parameters.NumInferenceSteps != nil {
+		steps := "num_inference_steps"
+		if mapping.Provider == InferenceProviderTogether {
+			steps = "steps"
+		}
+		payload[steps] = *parameters.NumInferenceSteps
+	}
+	if parameters.Seed != nil {
+		payload["seed"] = *parameters.Seed
+	}
+
+	var response struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+		} `json:"data"`
+	}
+	if err := rc.route("/"+string(mapping.Provider)+"/v1/images/generations").PostJSON("", payload, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("%s returned no image", mapping.Provider)
+	}
+
+	return base64.StdEncoding.DecodeString(response.Data[0].B64JSON)
+}
+
+// ChatCompleter runs chat completions, implemented by ChatClient, RouterClient and FallbackChatClient
+type ChatCompleter interface {
+	ChatCompletion(request ChatCompletionRequest) (*ChatCompletionResponse, error)
+	ChatCompletionStream(request ChatCompletionRequest) (*ChatStream, error)
+}
+
+// FallbackChatClient chats with a dedicated endpoint, and with the serverless router when the
+// endpoint is not running or answers with a retryable error, e.g. while paused or scaled to zero
+type FallbackChatClient struct {
+	Client    *Client
+	Namespace string
+	Name      string
+	Router    *RouterClient
+	// Hub model id sent to the router, defaults to the repository of the endpoint
+	Model string
+}
+
+// NewFallbackChatClient - Create a chat client of an endpoint falling back to router
+func (c *Client) NewFallbackChatClient(namespace, name string, router *RouterClient) *FallbackChatClient {
+	return &FallbackChatClient{Client: c, Namespace: namespace, Name: name, Router: router}
+}
+
+// dedicated - chat client of the endpoint when it is running, and the model to fall back to
+func (f *FallbackChatClient) dedicated() (*ChatClient, string, error) {
+	endpoint, err := f.Client.GetEndpoint(f.Namespace, f.Name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	model := f.Model
+	if model == "" {
+		model = endpoint.Model.Repository
+	}
+
+	if endpoint.Status.State != StateRunning {
+		return nil, model, nil
+	}
+
+	// A running endpoint which can not chat is misconfigured, not a reason to pay for serverless calls
+	chat, err := f.Client.NewChatClient(*endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return chat, model, nil
+}
+
+// ChatCompletion - ChatCompletion on the endpoint, on the router as a fallback
+func (f *FallbackChatClient) ChatCompletion(request ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	chat, model, err := f.dedicated()
+	if err != nil {
+		return nil, err
+	}
+
+	if chat != nil {
+		response, err := chat.ChatCompletion(request)
+		if err == nil || !IsRetryable(err) {
+			return response, err
+		}
+	}
+
+	request.Model = model
+	return f.Router.ChatCompletion(request)
+}
+
+// ChatCompletionStream - ChatCompletionStream on the endpoint, on the router as a fallback
+func (f *FallbackChatClient) ChatCompletionStream(request ChatCompletionRequest) (*ChatStream, error) {
+	chat, model, err := f.dedicated()
+	if err != nil {
+		return nil, err
+	}
+
+	if chat != nil {
+		stream, err := chat.ChatCompletionStream(request)
+		if err == nil || !IsRetryable(err) {
+			return stream, err
+		}
+	}
+
+	request.Model = model
+	return f.Router.ChatCompletionStream(request)
+}
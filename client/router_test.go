@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProviderMapping = `{"id":"meta-llama/Llama-3.1-8B-Instruct","inferenceProviderMapping":[
+	{"provider":"sambanova","providerId":"Meta-Llama-3.1-8B-Instruct","status":"staging","task":"conversational"},
+	{"provider":"together","providerId":"meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo","status":"live","task":"conversational"},
+	{"provider":"hf-inference","providerId":"meta-llama/Llama-3.1-8B-Instruct","status":"live","task":"conversational"},
+	{"provider":"nebius","providerId":"BAAI/bge-en-icl","status":"live","task":"feature-extraction"},
+	{"provider":"hf-inference","providerId":"black-forest-labs/FLUX.1-dev","status":"live","task":"text-to-image"}]}`
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+}
+
+func TestGetProviderMappings(t *testing.T) {
+	var calls int
+	client := newTestClient(func(req *http.Request) *http.Response {
+		calls++
+		if req.URL.Host != "huggingface.co" || req.URL.Query().Get("expand[]") != "inferenceProviderMapping" {
+			t.Errorf("unexpected request %s", req.URL)
+		}
+		if strings.HasSuffix(req.URL.Path, "/keyed") {
+			return jsonResponse(200, `{"inferenceProviderMapping":{"together":{"providerId":"t","status":"live","task":"conversational"},"groq":{"providerId":"g","status":"live","task":"conversational"}}}`)
+		}
+		return jsonResponse(200, testProviderMapping)
+	})
+	router := client.NewRouterClient("")
+
+	mappings, err := router.GetProviderMappings("meta-llama/Llama-3.1-8B-Instruct")
+	if err != nil || len(mappings) != 5 || mappings[1].Provider != InferenceProviderTogether {
+		t.Fatalf("unexpected mappings %+v (%v)", mappings, err)
+	}
+	router.GetProviderMappings("meta-llama/Llama-3.1-8B-Instruct")
+	if calls != 1 {
+		t.Fatalf("expected the mappings to be cached, got %d calls", calls)
+	}
+
+	keyed, err := router.GetProviderMappings("org/keyed")
+	if err != nil || len(keyed) != 2 || keyed[0].Provider != InferenceProviderGroq || keyed[0].ProviderID != "g" {
+		t.Fatalf("unexpected keyed mappings %+v (%v)", keyed, err)
+	}
+}
+
+func TestSelectProvider(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return jsonResponse(200, testProviderMapping)
+	})
+	model := "meta-llama/Llama-3.1-8B-Instruct"
+
+	for provider, expected := range map[InferenceProvider]InferenceProvider{
+		InferenceProviderAuto:        InferenceProviderTogether,
+		InferenceProviderHFInference: InferenceProviderHFInference,
+		InferenceProviderSambanova:   InferenceProviderSambanova,
+		InferenceProviderGroq:        "",
+	} {
+		mapping, err := client.NewRouterClient(provider).SelectProvider(model, RouterTaskConversational)
+		if expected == "" {
+			if !errors.Is(err, ErrNoProvider) {
+				t.Fatalf("%s: expected no provider, got %+v (%v)", provider, mapping, err)
+			}
+			continue
+		}
+		if err != nil || mapping.Provider != expected {
+			t.Fatalf("%s: expected %s, got %+v (%v)", provider, expected, mapping, err)
+		}
+	}
+}
+
+func TestRouterChatCompletionRetries(t *testing.T) {
+	var attempts int
+	client := newTestClient(func(req *http.Request) *http.Response {
+		if req.URL.Host == "huggingface.co" {
+			return jsonResponse(200, testProviderMapping)
+		}
+
+		attempts++
+		if req.URL.String() != RouterURL+"/v1/chat/completions" || req.Header.Get("Authorization") != "Bearer fake-token" {
+			t.Errorf("unexpected request %s", req.URL)
+		}
+		var request ChatCompletionRequest
+		json.NewDecoder(req.Body).Decode(&request)
+		if request.Model != "meta-llama/Llama-3.1-8B-Instruct:together" {
+			t.Errorf("expected the provider suffix, got %s", request.Model)
+		}
+		if attempts == 1 {
+			return jsonResponse(http.StatusServiceUnavailable, `{"error":"overloaded"}`)
+		}
+		return jsonResponse(200, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hello"}}]}`)
+	})
+	router := client.NewRouterClient(InferenceProviderAuto)
+	router.RetryBackoff = time.Millisecond
+
+	response, err := router.ChatCompletion(ChatCompletionRequest{
+		Model:    "meta-llama/Llama-3.1-8B-Instruct",
+		Messages: []ChatMessage{{Role: ChatRoleUser, Content: "hi"}},
+	})
+	if err != nil || response.Choices[0].Message.Content != "hello" || attempts != 2 {
+		t.Fatalf("unexpected completion %+v after %d attempts (%v)", response, attempts, err)
+	}
+}
+
+func TestRouterEmbeddingsAndTextToImage(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		switch {
+		case req.URL.Host == "huggingface.co":
+			return jsonResponse(200, testProviderMapping)
+		case req.URL.Path == "/nebius/v1/embeddings":
+			var request EmbeddingsRequest
+			json.NewDecoder(req.Body).Decode(&request)
+			if request.Model != "BAAI/bge-en-icl" {
+				t.Errorf("expected the provider model id, got %s", request.Model)
+			}
+			return jsonResponse(200, `{"object":"list","data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}]}`)
+		case req.URL.Path == "/hf-inference/models/black-forest-labs/FLUX.1-dev":
+			return jsonResponse(200, "\x89PNG")
+		}
+		t.Errorf("unexpected request %s", req.URL)
+		return jsonResponse(404, "")
+	})
+	router := client.NewRouterClient(InferenceProviderAuto)
+
+	vectors, err := router.FeatureExtraction("model", FeatureExtractionRequest{Inputs: []string{"a", "b"}})
+	if err != nil || len(vectors) != 2 || vectors[0][0] != 0.1 || vectors[1][0] != 0.3 {
+		t.Fatalf("unexpected vectors %v (%v)", vectors, err)
+	}
+
+	image, err := router.TextToImage("model", TextToImageRequest{Inputs: "an astronaut riding a horse"})
+	if err != nil || string(image) != "\x89PNG" {
+		t.Fatalf("unexpected image %q (%v)", image, err)
+	}
+}
+
+func TestFallbackChatClient(t *testing.T) {
+	var state = StatePaused
+	var url = "https://test.endpoints.huggingface.cloud"
+	var dedicated, routed int
+	client := newTestClient(func(req *http.Request) *http.Response {
+		switch {
+		case req.URL.Host == "fake.api":
+			endpoint := EndpointWithStatus{
+				Name:   "endpoint",
+				Type:   TypeProtected,
+				Model:  EndpointModel{Repository: "meta-llama/Llama-3.1-8B-Instruct", Task: "text-generation"},
+				Status: EndpointStatus{State: state, URL: &url},
+			}
+			body, _ := json.Marshal(endpoint)
+			return jsonResponse(200, string(body))
+		case req.URL.Host == "huggingface.co":
+			return jsonResponse(200, testProviderMapping)
+		case req.URL.Host == "test.endpoints.huggingface.cloud":
+			dedicated++
+			return jsonResponse(200, `{"choices":[{"index":0,"message":{"role":"assistant","content":"dedicated"}}]}`)
+		}
+		routed++
+		return jsonResponse(200, `{"choices":[{"index":0,"message":{"role":"assistant","content":"serverless"}}]}`)
+	})
+	router := client.NewRouterClient(InferenceProviderAuto)
+	fallback := client.NewFallbackChatClient("namespace", "endpoint", router)
+	request := ChatCompletionRequest{Messages: []ChatMessage{{Role: ChatRoleUser, Content: "hi"}}}
+
+	response, err := fallback.ChatCompletion(request)
+	if err != nil || response.Choices[0].Message.Content != "serverless" || routed != 1 {
+		t.Fatalf("expected the paused endpoint to fall back to the router, got %+v (%v)", response, err)
+	}
+
+	state = StateRunning
+	response, err = fallback.ChatCompletion(request)
+	if err != nil || response.Choices[0].Message.Content != "dedicated" || dedicated != 1 {
+		t.Fatalf("expected the running endpoint to be used, got %+v (%v)", response, err)
+	}
+
+	// A running endpoint the client can not call is an error, not a fallback
+	url = ""
+	if _, err := fallback.ChatCompletion(request); err == nil || routed != 1 {
+		t.Fatalf("expected an error without falling back, got %v after %d routed calls", err, routed)
+	}
+}
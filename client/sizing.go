@@ -0,0 +1,410 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// Share of the accelerator memory usable by the model, the rest goes to the runtime
+	GPUMemoryUsable = 0.9
+	// Share of the instance memory usable by the model on cpu instances
+	CPUMemoryUsable = 0.8
+	// Default context of the kv cache estimate, capped by the model max position embeddings
+	DefaultSizingMaxTotalTokens = 4096
+)
+
+// ModelMetadata describes the weights and architecture of a hub model repository
+type ModelMetadata struct {
+	Repository   string `json:"repository"`
+	Revision     string `json:"revision,omitempty"`
+	PipelineTag  string `json:"pipelineTag,omitempty"`
+	Library      string `json:"library,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	ModelType    string `json:"modelType,omitempty"`
+	Parameters   int64  `json:"parameters"`
+	// Dtype holding most of the parameters (BF16, F16, F32, ...)
+	Dtype            string     `json:"dtype,omitempty"`
+	SafetensorsBytes int64      `json:"safetensorsBytes,omitempty"`
+	GGUFFiles        []RepoFile `json:"ggufFiles,omitempty"`
+	// Quantization method of already quantized weights (awq, gptq, ...)
+	QuantMethod           string `json:"quantMethod,omitempty"`
+	NumLayers             int    `json:"numLayers,omitempty"`
+	HiddenSize            int    `json:"hiddenSize,omitempty"`
+	NumAttentionHeads     int    `json:"numAttentionHeads,omitempty"`
+	NumKeyValueHeads      int    `json:"numKeyValueHeads,omitempty"`
+	HeadDim               int    `json:"headDim,omitempty"`
+	MaxPositionEmbeddings int    `json:"maxPositionEmbeddings,omitempty"`
+}
+
+// RepoFile is a file of a hub repository
+type RepoFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// modelConfig holds the config.json fields used for sizing, multimodal models nest
+// them in text_config
+type modelConfig struct {
+	NumHiddenLayers       int          `json:"num_hidden_layers"`
+	HiddenSize            int          `json:"hidden_size"`
+	NumAttentionHeads     int          `json:"num_attention_heads"`
+	NumKeyValueHeads      int          `json:"num_key_value_heads"`
+	HeadDim               int          `json:"head_dim"`
+	MaxPositionEmbeddings int          `json:"max_position_embeddings"`
+	TextConfig            *modelConfig `json:"text_config,omitempty"`
+	QuantizationConfig    *struct {
+		Method string `json:"quant_method"`
+	} `json:"quantization_config,omitempty"`
+}
+
+// GetModelMetadata - read the parameters, weights and architecture of a model repository
+// from the hub api and its config.json, revision defaults to main
+func (c *Client) GetModelMetadata(repository, revision string) (*ModelMetadata, error) {
+	if revision == "" {
+		revision = "main"
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/models/%s/revision/%s?blobs=true", c.hubHost(), repository, url.PathEscape(revision)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAuthRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get model %s: %w", repository, err)
+	}
+
+	var info struct {
+		PipelineTag string `json:"pipeline_tag"`
+		Library     string `json:"library_name"`
+		Safetensors *struct {
+			Parameters map[string]int64 `json:"parameters"`
+			Total      int64            `json:"total"`
+		} `json:"safetensors"`
+		GGUF *struct {
+			Total int64 `json:"total"`
+		} `json:"gguf"`
+		Config *struct {
+			Architectures []string `json:"architectures"`
+			ModelType     string   `json:"model_type"`
+		} `json:"config"`
+		Siblings []struct {
+			Filename string `json:"rfilename"`
+			Size     int64  `json:"size"`
+		} `json:"siblings"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	meta := &ModelMetadata{Repository: repository, Revision: revision, PipelineTag: info.PipelineTag, Library: info.Library}
+	if info.Config != nil {
+		meta.ModelType = info.Config.ModelType
+		if len(info.Config.Architectures) > 0 {
+			meta.Architecture = info.Config.Architectures[0]
+		}
+	}
+
+	if info.Safetensors != nil {
+		meta.Parameters = info.Safetensors.Total
+		var largest int64
+		for dtype, count := range info.Safetensors.Parameters {
+			if count > largest {
+				largest, meta.Dtype = count, dtype
+			}
+		}
+	}
+	if meta.Parameters == 0 && info.GGUF != nil {
+		meta.Parameters = info.GGUF.Total
+	}
+
+	for _, sibling := range info.Siblings {
+		switch {
+		case strings.HasSuffix(sibling.Filename, ".safetensors"):
+			meta.SafetensorsBytes += sibling.Size
+		case strings.HasSuffix(sibling.Filename, ".gguf"):
+			meta.GGUFFiles = append(meta.GGUFFiles, RepoFile{Path: sibling.Filename, Size: sibling.Size})
+		}
+	}
+
+	// The architecture only refines the kv cache estimate, models without config.json are still sized
+	req, err = http.NewRequest("GET", fmt.Sprintf("%s/%s/resolve/%s/config.json", c.hubHost(), repository, url.PathEscape(revision)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if body, err := c.doAuthRequest(req); err == nil {
+		var config modelConfig
+		if json.Unmarshal(body, &config) == nil {
+			meta.applyConfig(config)
+		}
+	}
+
+	return meta, nil
+}
+
+func (m *ModelMetadata) applyConfig(config modelConfig) {
+	if config.QuantizationConfig != nil {
+		m.QuantMethod = config.QuantizationConfig.Method
+	}
+	if config.NumHiddenLayers == 0 && config.TextConfig != nil {
+		config = *config.TextConfig
+	}
+
+	m.NumLayers = config.NumHiddenLayers
+	m.HiddenSize = config.HiddenSize
+	m.NumAttentionHeads = config.NumAttentionHeads
+	m.NumKeyValueHeads = config.NumKeyValueHeads
+	if m.NumKeyValueHeads == 0 {
+		m.NumKeyValueHeads = config.NumAttentionHeads
+	}
+	m.HeadDim = config.HeadDim
+	if m.HeadDim == 0 && config.NumAttentionHeads > 0 {
+		m.HeadDim = config.HiddenSize / config.NumAttentionHeads
+	}
+	m.MaxPositionEmbeddings = config.MaxPositionEmbeddings
+}
+
+// bytesPerParameter - storage size of a safetensors dtype
+func bytesPerParameter(dtype string) float64 {
+	switch strings.ToUpper(dtype) {
+	case "F64", "I64":
+		return 8
+	case "F32", "I32":
+		return 4
+	case "I8", "U8", "F8_E4M3", "F8_E5M2":
+		return 1
+	}
+
+	return 2
+}
+
+// SizingOptions are the serving constraints of a sizing
+type SizingOptions struct {
+	// Tokens of a request, input and output, kept in the kv cache
+	MaxTotalTokens int
+	// Requests served at once at MaxTotalTokens
+	Concurrency int
+	// Computes considered, all the available ones of the catalog when empty
+	Filter ComputeFilter
+}
+
+// MemoryEstimate is the memory needed to serve a model, in GB
+type MemoryEstimate struct {
+	Weights  float64 `json:"weightsGb"`
+	KVCache  float64 `json:"kvCacheGb"`
+	Overhead float64 `json:"overheadGb"`
+	Total    float64 `json:"totalGb"`
+}
+
+// EstimateMemory - memory of the weights at bytesPerParam (the stored weights when 0), of the
+// kv cache of maxTotalTokens for concurrency requests, and of the runtime
+func (m *ModelMetadata) EstimateMemory(bytesPerParam float64, maxTotalTokens, concurrency int) MemoryEstimate {
+	const gb = 1e9
+
+	weights := float64(m.SafetensorsBytes)
+	if bytesPerParam > 0 || weights == 0 {
+		if bytesPerParam == 0 {
+			bytesPerParam = bytesPerParameter(m.Dtype)
+		}
+		weights = float64(m.Parameters) * bytesPerParam
+	}
+
+	// Keys and values of every layer, stored in 16 bits
+	kvCache := 2 * float64(m.NumLayers) * float64(m.NumKeyValueHeads) * float64(m.HeadDim) * 2 * float64(maxTotalTokens) * float64(concurrency)
+
+	estimate := MemoryEstimate{
+		Weights:  weights / gb,
+		KVCache:  kvCache / gb,
+		Overhead: 1 + 0.1*weights/gb,
+	}
+	estimate.Total = estimate.Weights + estimate.KVCache + estimate.Overhead
+
+	return estimate
+}
+
+// HardwareRecommendation is a compute and image able to serve a model
+type HardwareRecommendation struct {
+	Vendor       string          `json:"vendor"`
+	Region       string          `json:"region"`
+	Accelerator  AcceleratorType `json:"accelerator"`
+	InstanceType string          `json:"instanceType"`
+	InstanceSize string          `json:"instanceSize"`
+	// tgi, tei, huggingface or llamacpp
+	Image    string        `json:"image"`
+	Quantize *QuantizeType `json:"quantize,omitempty"`
+	// GGUF file of the llamacpp image
+	ModelPath      string         `json:"modelPath,omitempty"`
+	MaxTotalTokens int            `json:"maxTotalTokens,omitempty"`
+	Memory         MemoryEstimate `json:"memory"`
+	AvailableGb    float64        `json:"availableGb"`
+	PricePerHour   float64        `json:"pricePerHour"`
+	Monthly        float64        `json:"monthly"`
+}
+
+// servingOption is an image able to serve a model with the memory it needs
+type servingOption struct {
+	image     string
+	quantize  *QuantizeType
+	modelPath string
+	memory    MemoryEstimate
+	gpuOnly   bool
+	// Ranked after the options serving the stored weights at the same price
+	degraded bool
+}
+
+// ggufSplit matches the parts of split gguf files, e.g. model-Q8_0-00001-of-00003.gguf
+var ggufSplit = regexp.MustCompile(`^(.*)-(\d{5})(-of-\d{5})\.gguf$`)
+
+// servingOptions - images suited to the model task, with the quantized variants of tgi
+// and the gguf files of llamacpp
+func (m *ModelMetadata) servingOptions(maxTotalTokens, concurrency int) []servingOption {
+	switch m.PipelineTag {
+	case "feature-extraction", "sentence-similarity", "text-ranking":
+		return []servingOption{{image: "tei", memory: m.EstimateMemory(0, 0, 0)}}
+	case "text-generation", "image-text-to-text", "text2text-generation":
+	default:
+		return []servingOption{{image: "huggingface", memory: m.EstimateMemory(0, 0, 0)}}
+	}
+
+	// tgi loads safetensors weights, the parameters of gguf-only repositories come from the gguf metadata
+	var options []servingOption
+	if m.SafetensorsBytes > 0 || m.Dtype != "" {
+		options = append(options, servingOption{image: "tgi", gpuOnly: true, memory: m.EstimateMemory(0, maxTotalTokens, concurrency)})
+
+		// Weights already quantized are not quantized again
+		if m.QuantMethod == "" && m.Parameters > 0 {
+			eetq, bitsandbytes := QuantizeEETQ, QuantizeBitsAndBytes
+			options = append(options,
+				servingOption{image: "tgi", quantize: &eetq, gpuOnly: true, degraded: true, memory: m.EstimateMemory(1, maxTotalTokens, concurrency)},
+				servingOption{image: "tgi", quantize: &bitsandbytes, gpuOnly: true, degraded: true, memory: m.EstimateMemory(0.5, maxTotalTokens, concurrency)},
+			)
+		}
+	}
+
+	// Split files are served from their first part and loaded with all their parts
+	splitSizes := map[string]int64{}
+	for _, file := range m.GGUFFiles {
+		if match := ggufSplit.FindStringSubmatch(file.Path); match != nil {
+			splitSizes[match[1]+match[3]] += file.Size
+		}
+	}
+
+	for _, file := range m.GGUFFiles {
+		size := file.Size
+		if match := ggufSplit.FindStringSubmatch(file.Path); match != nil {
+			if match[2] != "00001" {
+				continue
+			}
+			size = splitSizes[match[1]+match[3]]
+		}
+		memory := m.EstimateMemory(0, maxTotalTokens, concurrency)
+		memory.Weights = float64(size) / 1e9
+		memory.Overhead = 1 + 0.1*memory.Weights
+		memory.Total = memory.Weights + memory.KVCache + memory.Overhead
+		options = append(options, servingOption{image: "llamacpp", modelPath: file.Path, degraded: true, memory: memory})
+	}
+
+	return options
+}
+
+// RecommendHardware - computes of the catalog able to serve a model with each of its
+// serving options, cheapest first
+func (c *Client) RecommendHardware(meta *ModelMetadata, options SizingOptions) ([]HardwareRecommendation, error) {
+	filter := options.Filter
+	filter.AvailableOnly = true
+	computes, err := c.ListComputes(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta.Recommend(computes, options), nil
+}
+
+// Recommend - computes able to serve the model with each of its serving options, sorted by
+// price, stored weights before quantized ones, and memory headroom
+func (m *ModelMetadata) Recommend(computes []ProviderCompute, options SizingOptions) []HardwareRecommendation {
+	maxTotalTokens := options.MaxTotalTokens
+	if maxTotalTokens <= 0 {
+		maxTotalTokens = DefaultSizingMaxTotalTokens
+		if m.MaxPositionEmbeddings > 0 && m.MaxPositionEmbeddings < maxTotalTokens {
+			maxTotalTokens = m.MaxPositionEmbeddings
+		}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	type ranked struct {
+		HardwareRecommendation
+		degraded bool
+	}
+	var candidates []ranked
+	for _, option := range m.servingOptions(maxTotalTokens, concurrency) {
+		for _, compute := range computes {
+			if !compute.Available() || !options.Filter.Matches(compute) {
+				continue
+			}
+
+			var available float64
+			switch compute.Accelerator {
+			case AcceleratorGPU:
+				available = compute.GpuMemoryGb * GPUMemoryUsable
+			case AcceleratorCPU:
+				if option.gpuOnly {
+					continue
+				}
+				available = compute.MemoryGb * CPUMemoryUsable
+			default:
+				// Neuron instances need models compiled for them
+				continue
+			}
+			if option.memory.Total > available {
+				continue
+			}
+
+			recommendation := HardwareRecommendation{
+				Vendor:       compute.Vendor,
+				Region:       compute.Region,
+				Accelerator:  compute.Accelerator,
+				InstanceType: compute.InstanceType,
+				InstanceSize: compute.InstanceSize,
+				Image:        option.image,
+				Quantize:     option.quantize,
+				ModelPath:    option.modelPath,
+				Memory:       option.memory,
+				AvailableGb:  available,
+				PricePerHour: compute.PricePerHour,
+				Monthly:      compute.PricePerHour * HoursPerMonth,
+			}
+			if option.image == "tgi" || option.image == "llamacpp" {
+				recommendation.MaxTotalTokens = maxTotalTokens
+			}
+			candidates = append(candidates, ranked{recommendation, option.degraded})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.PricePerHour != b.PricePerHour {
+			return a.PricePerHour < b.PricePerHour
+		}
+		if a.degraded != b.degraded {
+			return !a.degraded
+		}
+		return a.AvailableGb-a.Memory.Total > b.AvailableGb-b.Memory.Total
+	})
+
+	recommendations := make([]HardwareRecommendation, len(candidates))
+	for i, candidate := range candidates {
+		recommendations[i] = candidate.HardwareRecommendation
+	}
+
+	return recommendations
+}
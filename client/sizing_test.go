@@ -0,0 +1,141 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetModelMetadata(t *testing.T) {
+	var paths []string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		paths = append(paths, req.URL.Path)
+		body := `{"pipeline_tag":"text-generation","library_name":"transformers",
+			"safetensors":{"parameters":{"BF16":8030261248,"F32":1024},"total":8030262272},
+			"config":{"architectures":["LlamaForCausalLM"],"model_type":"llama"},
+			"siblings":[{"rfilename":"config.json","size":826},{"rfilename":"model-00001-of-00002.safetensors","size":10000000000},
+				{"rfilename":"model-00002-of-00002.safetensors","size":6060000000},{"rfilename":"llama-Q4_K_M.gguf","size":4920000000}]}`
+		if strings.HasSuffix(req.URL.Path, "/config.json") {
+			body = `{"num_hidden_layers":32,"hidden_size":4096,"num_attention_heads":32,"num_key_value_heads":8,"max_position_embeddings":131072}`
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})
+
+	meta, err := client.GetModelMetadata("meta-llama/Llama-3.1-8B-Instruct", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if paths[0] != "/api/models/meta-llama/Llama-3.1-8B-Instruct/revision/main" || paths[1] != "/meta-llama/Llama-3.1-8B-Instruct/resolve/main/config.json" {
+		t.Fatalf("unexpected hub requests: %v", paths)
+	}
+	if meta.Parameters != 8030262272 || meta.Dtype != "BF16" || meta.SafetensorsBytes != 16060000000 || meta.Architecture != "LlamaForCausalLM" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	if meta.NumLayers != 32 || meta.NumKeyValueHeads != 8 || meta.HeadDim != 128 || len(meta.GGUFFiles) != 1 {
+		t.Fatalf("unexpected architecture: %+v", meta)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	meta := &ModelMetadata{
+		PipelineTag:           "text-generation",
+		Parameters:            8030261248,
+		Dtype:                 "BF16",
+		SafetensorsBytes:      16060000000,
+		GGUFFiles:             []RepoFile{{Path: "llama-Q4_K_M.gguf", Size: 4920000000}},
+		NumLayers:             32,
+		NumKeyValueHeads:      8,
+		HeadDim:               128,
+		MaxPositionEmbeddings: 131072,
+	}
+
+	memory := meta.EstimateMemory(0, 4096, 1)
+	if math.Abs(memory.KVCache-0.537) > 0.001 || math.Abs(memory.Total-19.2) > 0.01 {
+		t.Fatalf("unexpected memory estimate: %+v", memory)
+	}
+
+	computes := []ProviderCompute{
+		{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorGPU, InstanceType: "nvidia-a10g", InstanceSize: "x1", GpuMemoryGb: 24, PricePerHour: 1.0},
+		{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorGPU, InstanceType: "nvidia-l4", InstanceSize: "x1", GpuMemoryGb: 24, PricePerHour: 0.8},
+		{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorGPU, InstanceType: "nvidia-t4", InstanceSize: "x1", GpuMemoryGb: 16, PricePerHour: 0.5},
+		{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorCPU, InstanceType: "intel-spr", InstanceSize: "x4", MemoryGb: 16, PricePerHour: 0.13},
+		{Vendor: "aws", Region: "us-east-1", Accelerator: AcceleratorCPU, InstanceType: "intel-spr", InstanceSize: "x1", MemoryGb: 2, PricePerHour: 0.03},
+	}
+
+	recommendations := meta.Recommend(computes, SizingOptions{})
+	describe := func(r HardwareRecommendation) string {
+		quantize := ""
+		if r.Quantize != nil {
+			quantize = "+" + string(*r.Quantize)
+		}
+		return r.InstanceType + ":" + r.Image + quantize
+	}
+
+	var got []string
+	for _, r := range recommendations {
+		got = append(got, describe(r))
+	}
+	expected := "intel-spr:llamacpp nvidia-t4:tgi+bitsandbytes nvidia-t4:llamacpp nvidia-t4:tgi+eetq " +
+		"nvidia-l4:tgi nvidia-l4:tgi+bitsandbytes nvidia-l4:llamacpp nvidia-l4:tgi+eetq " +
+		"nvidia-a10g:tgi nvidia-a10g:tgi+bitsandbytes nvidia-a10g:llamacpp nvidia-a10g:tgi+eetq"
+	if strings.Join(got, " ") != expected {
+		t.Fatalf("expected %s, got %s", expected, strings.Join(got, " "))
+	}
+	if recommendations[0].ModelPath != "llama-Q4_K_M.gguf" || recommendations[0].MaxTotalTokens != 4096 {
+		t.Fatalf("unexpected llamacpp recommendation: %+v", recommendations[0])
+	}
+
+	// Longer contexts need a larger kv cache
+	long := meta.Recommend(computes, SizingOptions{MaxTotalTokens: 32768, Concurrency: 4})
+	for _, r := range long {
+		if r.Image == "tgi" && r.Quantize == nil {
+			t.Fatalf("expected bf16 weights with a 17GB kv cache not to fit, got %+v", r)
+		}
+	}
+
+	embeddings := &ModelMetadata{PipelineTag: "feature-extraction", Parameters: 335000000, Dtype: "F32"}
+	if r := embeddings.Recommend(computes, SizingOptions{}); len(r) == 0 || r[0].Image != "tei" || r[0].InstanceSize != "x4" || r[0].Accelerator != AcceleratorCPU {
+		t.Fatalf("expected tei on the smallest cpu fitting the model first, got %+v", r)
+	}
+}
+
+func TestServingOptionsSplitGGUF(t *testing.T) {
+	meta := &ModelMetadata{PipelineTag: "text-generation", GGUFFiles: []RepoFile{
+		{Path: "Q8_0/model-Q8_0-00001-of-00004.gguf", Size: 5e9},
+		{Path: "Q8_0/model-Q8_0-00002-of-00004.gguf", Size: 5e9},
+		{Path: "Q8_0/model-Q8_0-00003-of-00004.gguf", Size: 5e9},
+		{Path: "Q8_0/model-Q8_0-00004-of-00004.gguf", Size: 2e9},
+		{Path: "model-Q4_K_M.gguf", Size: 4e9},
+	}}
+
+	options := meta.servingOptions(4096, 1)
+	if len(options) != 2 {
+		t.Fatalf("expected one option per model, got %+v", options)
+	}
+	if options[0].modelPath != "Q8_0/model-Q8_0-00001-of-00004.gguf" || options[0].memory.Weights != 17 {
+		t.Fatalf("expected the split model to be sized with all its parts, got %+v", options[0])
+	}
+	if options[1].memory.Weights != 4 {
+		t.Fatalf("unexpected single file option %+v", options[1])
+	}
+}
+
+func TestServingOptionsGGUFOnly(t *testing.T) {
+	meta := &ModelMetadata{PipelineTag: "text-generation", Parameters: 8030261248, GGUFFiles: []RepoFile{
+		{Path: "model-Q4_K_M.gguf", Size: 4.9e9},
+		{Path: "model-Q8_0.gguf", Size: 8.5e9},
+	}}
+
+	options := meta.servingOptions(4096, 1)
+	if len(options) != 2 {
+		t.Fatalf("expected one llamacpp option per gguf file, got %+v", options)
+	}
+	for _, option := range options {
+		if option.image != "llamacpp" {
+			t.Fatalf("expected no tgi option without safetensors weights, got %+v", option)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SSEEvent is a single server-sent event
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry int
+}
+
+// SSEReader parses a server-sent events stream as described by the
+// text/event-stream specification
+type SSEReader struct {
+	reader *bufio.Reader
+}
+
+func NewSSEReader(r io.Reader) *SSEReader {
+	return &SSEReader{reader: bufio.NewReader(r)}
+}
+
+// Next - read the next event, returns io.EOF once the stream is exhausted
+func (r *SSEReader) Next() (*SSEEvent, error) {
+	event := &SSEEvent{}
+	var data []string
+	hasData := false
+
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			// Dispatch a trailing event not followed by a blank line
+			if err == io.EOF && hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		// Blank line dispatches the event
+		if line == "" {
+			if !hasData {
+				continue
+			}
+			event.Data = strings.Join(data, "\n")
+			return event, nil
+		}
+
+		// Comment line
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field = line[:i]
+			value = strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil {
+				event.Retry = retry
+			}
+		}
+	}
+}
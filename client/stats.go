@@ -0,0 +1,62 @@
+package client
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyStats summarizes a set of request latencies, in milliseconds
+type LatencyStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"minMs"`
+	Mean  float64 `json:"meanMs"`
+	P50   float64 `json:"p50Ms"`
+	P90   float64 `json:"p90Ms"`
+	P95   float64 `json:"p95Ms"`
+	P99   float64 `json:"p99Ms"`
+	Max   float64 `json:"maxMs"`
+}
+
+// NewLatencyStats - compute the latency summary of the given durations
+func NewLatencyStats(latencies []time.Duration) LatencyStats {
+	stats := LatencyStats{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sorted := make([]float64, len(latencies))
+	total := 0.0
+	for i, latency := range latencies {
+		sorted[i] = float64(latency) / float64(time.Millisecond)
+		total += sorted[i]
+	}
+	sort.Float64s(sorted)
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Mean = total / float64(len(sorted))
+	stats.P50 = Percentile(sorted, 50)
+	stats.P90 = Percentile(sorted, 90)
+	stats.P95 = Percentile(sorted, 95)
+	stats.P99 = Percentile(sorted, 99)
+
+	return stats
+}
+
+// Percentile - nearest-rank percentile of already sorted values
+func Percentile(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TGIClient calls the text-generation-inference routes of an endpoint
+type TGIClient struct {
+	*InferenceClient
+}
+
+// NewTGIClient - Create a TGI client for an endpoint deployed with a TGI image
+func (c *Client) NewTGIClient(endpoint EndpointWithStatus) (*TGIClient, error) {
+	if endpoint.Model.Image.TGI == nil && endpoint.Model.Image.TGINeuron == nil {
+		return nil, fmt.Errorf("endpoint %s is not deployed with a tgi image", endpoint.Name)
+	}
+
+	inferenceClient, err := c.NewInferenceClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TGIClient{InferenceClient: inferenceClient}, nil
+}
+
+type TGIGenerateRequest struct {
+	Inputs     string         `json:"inputs"`
+	Parameters *TGIParameters `json:"parameters,omitempty"`
+}
+
+type TGIParameters struct {
+	BestOf              *int        `json:"best_of,omitempty"`
+	DecoderInputDetails *bool       `json:"decoder_input_details,omitempty"`
+	Details             *bool       `json:"details,omitempty"`
+	DoSample            *bool       `json:"do_sample,omitempty"`
+	FrequencyPenalty    *float64    `json:"frequency_penalty,omitempty"`
+	Grammar             *TGIGrammar `json:"grammar,omitempty"`
+	MaxNewTokens        *int        `json:"max_new_tokens,omitempty"`
+	RepetitionPenalty   *float64    `json:"repetition_penalty,omitempty"`
+	ReturnFullText      *bool       `json:"return_full_text,omitempty"`
+	Seed                *int64      `json:"seed,omitempty"`
+	Stop                []string    `json:"stop,omitempty"`
+	Temperature         *float64    `json:"temperature,omitempty"`
+	TopK                *int        `json:"top_k,omitempty"`
+	TopNTokens          *int        `json:"top_n_tokens,omitempty"`
+	TopP                *float64    `json:"top_p,omitempty"`
+	Truncate            *int        `json:"truncate,omitempty"`
+	TypicalP            *float64    `json:"typical_p,omitempty"`
+	Watermark           *bool       `json:"watermark,omitempty"`
+}
+
+type TGIGrammarType string
+
+const (
+	TGIGrammarJSON  TGIGrammarType = "json"
+	TGIGrammarRegex TGIGrammarType = "regex"
+)
+
+// TGIGrammar constrains generation to a json schema or a regular expression
+type TGIGrammar struct {
+	Type  TGIGrammarType  `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NewTGIRegexGrammar - build a regex grammar, the pattern being sent as a json string
+func NewTGIRegexGrammar(pattern string) *TGIGrammar {
+	value, _ := json.Marshal(pattern)
+	return &TGIGrammar{Type: TGIGrammarRegex, Value: value}
+}
+
+type TGIToken struct {
+	ID      int      `json:"id"`
+	Text    string   `json:"text"`
+	Logprob *float64 `json:"logprob,omitempty"`
+	Special bool     `json:"special"`
+}
+
+type TGIFinishReason string
+
+const (
+	TGIFinishLength       TGIFinishReason = "length"
+	TGIFinishEOSToken     TGIFinishReason = "eos_token"
+	TGIFinishStopSequence TGIFinishReason = "stop_sequence"
+)
+
+type TGIDetails struct {
+	FinishReason    TGIFinishReason     `json:"finish_reason"`
+	GeneratedTokens int                 `json:"generated_tokens"`
+	Seed            *int64              `json:"seed,omitempty"`
+	Prefill         []TGIToken          `json:"prefill,omitempty"`
+	Tokens          []TGIToken          `json:"tokens,omitempty"`
+	TopTokens       [][]TGIToken        `json:"top_tokens,omitempty"`
+	BestOfSequences []TGIBestOfSequence `json:"best_of_sequences,omitempty"`
+	InputLength     *int                `json:"input_length,omitempty"`
+}
+
+type TGIBestOfSequence struct {
+	GeneratedText   string          `json:"generated_text"`
+	FinishReason    TGIFinishReason `json:"finish_reason"`
+	GeneratedTokens int             `json:"generated_tokens"`
+	Seed            *int64          `json:"seed,omitempty"`
+	Prefill         []TGIToken      `json:"prefill,omitempty"`
+	Tokens          []TGIToken      `json:"tokens,omitempty"`
+}
+
+type TGIGenerateResponse struct {
+	GeneratedText string      `json:"generated_text"`
+	Details       *TGIDetails `json:"details,omitempty"`
+}
+
+// TGIStreamResponse is one token of a /generate_stream response,
+// GeneratedText and Details are only set on the last one
+type TGIStreamResponse struct {
+	Index         int         `json:"index"`
+	Token         TGIToken    `json:"token"`
+	TopTokens     []TGIToken  `json:"top_tokens,omitempty"`
+	GeneratedText *string     `json:"generated_text,omitempty"`
+	Details       *TGIDetails `json:"details,omitempty"`
+}
+
+type tgiError struct {
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
+
+// Generate - Call the /generate route
+func (tc *TGIClient) Generate(request TGIGenerateRequest) (*TGIGenerateResponse, error) {
+	var response TGIGenerateResponse
+	err := tc.PostJSON("/generate", request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GenerateStream - Call the /generate_stream route, tokens are read with Next
+func (tc *TGIClient) GenerateStream(request TGIGenerateRequest) (*TGIStream, error) {
+	body, err := tc.PostStream("/generate_stream", request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TGIStream{body: body, events: NewSSEReader(body)}, nil
+}
+
+// TGIStream iterates over the tokens of a /generate_stream response
+type TGIStream struct {
+	body   io.ReadCloser
+	events *SSEReader
+}
+
+// Next - read the next token, returns io.EOF once generation is over
+func (s *TGIStream) Next() (*TGIStreamResponse, error) {
+	for {
+		event, err := s.events.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		data := strings.TrimSpace(event.Data)
+		if data == "" || data == "[DONE]" {
+			continue
+		}
+
+		if event.Event == "error" || strings.HasPrefix(data, `{"error"`) {
+			var tgiErr tgiError
+			if json.Unmarshal([]byte(data), &tgiErr) == nil && tgiErr.Error != "" {
+				return nil, fmt.Errorf("generation error (%s): %s", tgiErr.ErrorType, tgiErr.Error)
+			}
+			return nil, errors.New(data)
+		}
+
+		var response TGIStreamResponse
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			return nil, err
+		}
+
+		return &response, nil
+	}
+}
+
+// Close - release the underlying connection
+func (s *TGIStream) Close() error {
+	return s.body.Close()
+}
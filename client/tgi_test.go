@@ -0,0 +1,140 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func tgiEndpoint() EndpointWithStatus {
+	endpoint := testEndpoint(TypeProtected, TaskTextGeneration)
+	endpoint.Model.Image.TGI = &TGIImage{URL: "ghcr.io/huggingface/text-generation-inference:latest", Port: 80}
+	return endpoint
+}
+
+func TestSSEReader(t *testing.T) {
+	stream := ": comment\nevent: message\ndata: first\ndata: line\n\nid: 2\ndata: second\r\n\r\ndata: trailing"
+	reader := NewSSEReader(strings.NewReader(stream))
+
+	expected := []SSEEvent{
+		{Event: "message", Data: "first\nline"},
+		{ID: "2", Data: "second"},
+		{Data: "trailing"},
+	}
+
+	for _, want := range expected {
+		event, err := reader.Next()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if *event != want {
+			t.Fatalf("unexpected event: %+v, expected %+v", *event, want)
+		}
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestNewTGIClientRequiresTGIImage(t *testing.T) {
+	client := newTestClient(nil)
+
+	if _, err := client.NewTGIClient(testEndpoint(TypeProtected, TaskTextGeneration)); err == nil {
+		t.Fatalf("expected error for endpoint without tgi image")
+	}
+}
+
+func TestTGIGenerate(t *testing.T) {
+	var payload map[string]interface{}
+	client := newTestClient(func(req *http.Request) *http.Response {
+		if !strings.HasSuffix(req.URL.Path, "/generate") {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		json.NewDecoder(req.Body).Decode(&payload)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"generated_text":"world","details":{"finish_reason":"length","generated_tokens":1}}`)),
+		}
+	})
+
+	tgi, err := client.NewTGIClient(tgiEndpoint())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	maxNewTokens := 1
+	response, err := tgi.Generate(TGIGenerateRequest{
+		Inputs:     "hello",
+		Parameters: &TGIParameters{MaxNewTokens: &maxNewTokens, Grammar: NewTGIRegexGrammar("[a-z]+")},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if response.GeneratedText != "world" || response.Details.FinishReason != TGIFinishLength {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	grammar := payload["parameters"].(map[string]interface{})["grammar"].(map[string]interface{})
+	if grammar["type"] != "regex" || grammar["value"] != "[a-z]+" {
+		t.Fatalf("unexpected grammar payload: %+v", grammar)
+	}
+}
+
+func TestTGIGenerateStream(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(bytes.NewBufferString(
+				"data:{\"index\":1,\"token\":{\"id\":1,\"text\":\"Hel\",\"special\":false}}\n\n" +
+					"data:{\"index\":2,\"token\":{\"id\":2,\"text\":\"lo\",\"special\":false},\"generated_text\":\"Hello\",\"details\":{\"finish_reason\":\"eos_token\",\"generated_tokens\":2}}\n\n",
+			)),
+		}
+	})
+
+	tgi, _ := client.NewTGIClient(tgiEndpoint())
+	stream, err := tgi.GenerateStream(TGIGenerateRequest{Inputs: "hi"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer stream.Close()
+
+	var text string
+	var last *TGIStreamResponse
+	for {
+		response, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		text += response.Token.Text
+		last = response
+	}
+
+	if text != "Hello" || last.GeneratedText == nil || last.Details.GeneratedTokens != 2 {
+		t.Fatalf("unexpected stream result: %s %+v", text, last)
+	}
+}
+
+func TestTGIGenerateStreamError(t *testing.T) {
+	client := newTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString("data:{\"error\":\"Input validation error\",\"error_type\":\"validation\"}\n\n")),
+		}
+	})
+
+	tgi, _ := client.NewTGIClient(tgiEndpoint())
+	stream, _ := tgi.GenerateStream(TGIGenerateRequest{Inputs: "hi"})
+	defer stream.Close()
+
+	if _, err := stream.Next(); err == nil || !strings.Contains(err.Error(), "validation") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default environment variable read by the env token provider
+const TokenEnvVar string = "HF_TOKEN"
+
+// TokenProvider supplies the bearer token used to authenticate api calls.
+// Implementations may be called concurrently and on every request.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenProvider - always returns the same token
+type StaticTokenProvider string
+
+func (p StaticTokenProvider) Token(ctx context.Context) (string, error) {
+	if p == "" {
+		return "", errors.New("static token is empty")
+	}
+
+	return string(p), nil
+}
+
+// EnvTokenProvider - reads the token from an environment variable on every call
+type EnvTokenProvider struct {
+	Name string
+}
+
+func NewEnvTokenProvider(name string) *EnvTokenProvider {
+	if name == "" {
+		name = TokenEnvVar
+	}
+
+	return &EnvTokenProvider{Name: name}
+}
+
+func (p *EnvTokenProvider) Token(ctx context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(p.Name))
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s is not set", p.Name)
+	}
+
+	return token, nil
+}
+
+// FileTokenProvider - reads the token from a file, re-reading it only when
+// the file modification time or size changes
+type FileTokenProvider struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func NewFileTokenProvider(path string) *FileTokenProvider {
+	return &FileTokenProvider{Path: path}
+}
+
+func (p *FileTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if p.token != "" && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.token, nil
+	}
+
+	content, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", p.Path)
+	}
+
+	p.token = token
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+
+	return p.token, nil
+}
+
+// CommandTokenProvider - runs an external command and reads the token from its
+// standard output, in the spirit of git credential helpers. The output is either
+// the raw token or `key=value` lines, in which case the `password` or `token` key is used.
+type CommandTokenProvider struct {
+	Name string
+	Args []string
+}
+
+func NewCommandTokenProvider(name string, args ...string) *CommandTokenProvider {
+	return &CommandTokenProvider{Name: name, Args: args}
+}
+
+func (p *CommandTokenProvider) Token(ctx context.Context) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.Name, p.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("token command %s failed: %w: %s", p.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := parseCommandToken(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("token command %s returned no token", p.Name)
+	}
+
+	return token, nil
+}
+
+func parseCommandToken(output string) string {
+	output = strings.TrimSpace(output)
+	if !strings.Contains(output, "=") {
+		return output
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) == 2 {
+			values[parts[0]] = parts[1]
+		}
+	}
+
+	if token, ok := values["token"]; ok {
+		return token
+	}
+	if password, ok := values["password"]; ok {
+		return password
+	}
+
+	return output
+}
+
+// CachingTokenProvider - wraps another provider and caches its token for TTL.
+// Invalidate can be called to force a refresh, e.g. after a 401 response.
+type CachingTokenProvider struct {
+	Provider TokenProvider
+	TTL      time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func NewCachingTokenProvider(provider TokenProvider, ttl time.Duration) *CachingTokenProvider {
+	return &CachingTokenProvider{Provider: provider, TTL: ttl, now: time.Now}
+}
+
+func (p *CachingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.now == nil {
+		p.now = time.Now
+	}
+
+	if p.token != "" && p.now().Before(p.expiresAt) {
+		return p.token, nil
+	}
+
+	token, err := p.Provider.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = token
+	p.expiresAt = p.now().Add(p.TTL)
+
+	return p.token, nil
+}
+
+// Invalidate - drop the cached token so the next call hits the wrapped provider
+func (p *CachingTokenProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.token = ""
+	p.expiresAt = time.Time{}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type countingTokenProvider struct {
+	calls int
+}
+
+func (p *countingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.calls++
+	return "counted-token", nil
+}
+
+func TestStaticTokenProvider(t *testing.T) {
+	token, err := StaticTokenProvider("static-token").Token(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if token != "static-token" {
+		t.Fatalf("unexpected token: %s", token)
+	}
+
+	if _, err := StaticTokenProvider("").Token(context.Background()); err == nil {
+		t.Fatalf("expected error for empty static token")
+	}
+}
+
+func TestEnvTokenProvider(t *testing.T) {
+	os.Setenv("HF_CLIENT_TEST_TOKEN", " env-token\n")
+	defer os.Unsetenv("HF_CLIENT_TEST_TOKEN")
+
+	token, err := NewEnvTokenProvider("HF_CLIENT_TEST_TOKEN").Token(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if token != "env-token" {
+		t.Fatalf("unexpected token: %s", token)
+	}
+
+	if _, err := NewEnvTokenProvider("HF_CLIENT_TEST_UNSET").Token(context.Background()); err == nil {
+		t.Fatalf("expected error for unset variable")
+	}
+}
+
+func TestFileTokenProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewFileTokenProvider(path)
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token != "first-token" {
+		t.Fatalf("unexpected token: %s", token)
+	}
+
+	// Rotate the token, bumping the modification time so the change is detected
+	if err := os.WriteFile(path, []byte("rotated-token-value\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err = provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token != "rotated-token-value" {
+		t.Fatalf("unexpected token after rotation: %s", token)
+	}
+}
+
+func TestParseCommandToken(t *testing.T) {
+	cases := map[string]string{
+		"raw-token\n":                           "raw-token",
+		"protocol=https\npassword=helper-token": "helper-token",
+		"token=explicit\npassword=ignored":      "explicit",
+	}
+
+	for output, expected := range cases {
+		if token := parseCommandToken(output); token != expected {
+			t.Fatalf("parseCommandToken(%q) = %q, expected %q", output, token, expected)
+		}
+	}
+}
+
+func TestCachingTokenProvider(t *testing.T) {
+	inner := &countingTokenProvider{}
+	now := time.Now()
+
+	provider := NewCachingTokenProvider(inner, time.Minute)
+	provider.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Token(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected 1 call to the wrapped provider, got %d", inner.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	provider.Token(context.Background())
+	if inner.calls != 2 {
+		t.Fatalf("expected refresh after ttl, got %d calls", inner.calls)
+	}
+
+	provider.Invalidate()
+	provider.Token(context.Background())
+	if inner.calls != 3 {
+		t.Fatalf("expected refresh after invalidate, got %d calls", inner.calls)
+	}
+}
+
+func TestClientUsesTokenProvider(t *testing.T) {
+	var authorization string
+	client := newTestClient(func(req *http.Request) *http.Response {
+		authorization = req.Header.Get("Authorization")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"name":"fetched-endpoint"}`)),
+		}
+	})
+	client.TokenProvider = StaticTokenProvider("provided-token")
+
+	if _, err := client.GetEndpoint("namespace", "endpoint"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if authorization != "Bearer provided-token" {
+		t.Fatalf("unexpected authorization header: %s", authorization)
+	}
+}
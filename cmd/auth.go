@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whoamiNamespace string
+)
+
+func init() {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication",
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Validate a token and store it for later commands (reads --token, or stdin / prompt)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loginToken := token
+			if loginToken == "" {
+				var err error
+				loginToken, err = utils.ReadSecret("Token: ")
+				if err != nil {
+					return err
+				}
+			}
+
+			c, err := client.NewClient(&host, nil)
+			if err != nil {
+				return err
+			}
+
+			whoami, err := c.Login(loginToken)
+			if err != nil {
+				return err
+			}
+
+			path, err := client.TokenPath()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Logged in as %s, token stored at %s.\n", whoami.Name, path)
+
+			return nil
+		},
+	}
+
+	whoamiCmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the account, token role and namespaces the token can act on",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			whoami, err := c.WhoAmI()
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			if whoamiNamespace != "" && !whoami.CanAccessNamespace(whoamiNamespace) {
+				return fmt.Errorf("token of %s cannot act on namespace %s", whoami.Name, whoamiNamespace)
+			}
+
+			summary := struct {
+				Name       string                   `json:"name"`
+				Type       string                   `json:"type"`
+				Token      *client.AccessToken      `json:"token,omitempty"`
+				Namespaces []client.NamespaceAccess `json:"namespaces"`
+			}{
+				Name:       whoami.Name,
+				Type:       whoami.Type,
+				Token:      whoami.Auth.AccessToken,
+				Namespaces: whoami.Namespaces(),
+			}
+
+			printJSON(summary)
+
+			return nil
+		},
+	}
+
+	logoutCmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := client.Logout()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("Logged out successfully.")
+
+			return nil
+		},
+	}
+
+	whoamiCmd.Flags().StringVar(&whoamiNamespace, "namespace", "", "Fail unless the token can act on this namespace (optional)")
+
+	authCmd.AddCommand(loginCmd)
+	authCmd.AddCommand(whoamiCmd)
+	authCmd.AddCommand(logoutCmd)
+
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchInFile      string
+	batchOutFile     string
+	batchRoute       string
+	batchWorkers     int
+	batchRetries     int
+	batchRate        float64
+	batchRetryFailed bool
+)
+
+func init() {
+	batchCmd := &cobra.Command{
+		Use:   "batch [name]",
+		Short: "Run every json line of an input file through the endpoint inference api, resuming interrupted runs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := c.GetEndpoint(namespace, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			inference, err := c.NewInferenceClient(*endpoint)
+			if err != nil {
+				return err
+			}
+
+			input, err := os.Open(batchInFile)
+			if err != nil {
+				return err
+			}
+			defer input.Close()
+
+			// The results file doubles as the checkpoint of the run
+			done, err := client.LoadBatchCheckpoint(batchOutFile, batchRetryFailed)
+			if err != nil {
+				return err
+			}
+
+			output, err := os.OpenFile(batchOutFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			defer output.Close()
+
+			ctx, cancel := interruptible("rerun the same command to resume")
+			defer cancel()
+
+			summary, err := inference.RunBatch(ctx, input, output, client.BatchOptions{
+				Route:     batchRoute,
+				Workers:   batchWorkers,
+				Retries:   batchRetries,
+				RateLimit: batchRate,
+				Done:      done,
+			})
+			if summary != nil {
+				printJSON(summary)
+			}
+			if err != nil {
+				return err
+			}
+
+			if summary.Failed > 0 {
+				return fmt.Errorf("%d requests failed, rerun with --retry-failed to send them again", summary.Failed)
+			}
+
+			return nil
+		},
+	}
+
+	batchCmd.Flags().StringVar(&batchInFile, "in", "", "Input jsonl file, one request payload per line (required)")
+	batchCmd.Flags().StringVar(&batchOutFile, "out", "", "Results jsonl file, also used as checkpoint to resume (required)")
+	batchCmd.Flags().StringVar(&batchRoute, "route", "", "Endpoint route receiving the payloads (e.g. /generate, /v1/chat/completions)")
+	batchCmd.Flags().IntVar(&batchWorkers, "workers", client.DefaultBatchWorkers, "Number of concurrent requests")
+	batchCmd.Flags().IntVar(&batchRetries, "retries", client.DefaultBatchRetries, "Retries of a request failing with a retryable error")
+	batchCmd.Flags().Float64Var(&batchRate, "rate", 0, "Maximum requests per second (0 for no limit)")
+	batchCmd.Flags().BoolVar(&batchRetryFailed, "retry-failed", false, "Send again the requests that failed in a previous run")
+
+	batchCmd.MarkFlagRequired("in")
+	batchCmd.MarkFlagRequired("out")
+
+	endpointCmd.AddCommand(batchCmd)
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catalogVendor       string
+	catalogRegion       string
+	catalogAccelerator  string
+	catalogInstanceType string
+	catalogInstanceSize string
+	catalogAll          bool
+	catalogTask         string
+	catalogSearch       string
+	deployName          string
+	deployType          string
+	deployMinReplica    int
+	deployMaxReplica    int
+	deployDryRun        bool
+	sizeRevision        string
+	sizeMaxTotalTokens  int
+	sizeConcurrency     int
+	sizeLimit           int
+)
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Discover the vendors, regions, instances and curated models endpoints can deploy",
+}
+
+func init() {
+	providersCmd := &cobra.Command{
+		Use:   "providers",
+		Short: "List the vendors and their regions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			providers, err := c.ListProviders()
+			if err != nil {
+				return err
+			}
+
+			printJSON(providers)
+
+			return nil
+		},
+	}
+
+	instancesCmd := &cobra.Command{
+		Use:   "instances",
+		Short: "List the instances of the available regions, with their specs and prices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			computes, err := c.ListComputes(client.ComputeFilter{
+				Vendor:        catalogVendor,
+				Region:        catalogRegion,
+				Accelerator:   client.AcceleratorType(catalogAccelerator),
+				InstanceType:  catalogInstanceType,
+				AvailableOnly: !catalogAll,
+			})
+			if err != nil {
+				return err
+			}
+
+			printJSON(computes)
+
+			return nil
+		},
+	}
+
+	modelsCmd := &cobra.Command{
+		Use:   "models",
+		Short: "List the curated catalog models with their recommended configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			models, err := c.ListCatalogModels(client.CatalogFilter{Task: catalogTask, Search: catalogSearch})
+			if err != nil {
+				return err
+			}
+
+			printJSON(models)
+
+			return nil
+		},
+	}
+
+	deployCmd := &cobra.Command{
+		Use:   "deploy [model]",
+		Short: "Create an endpoint from the recommended configuration of a catalog model",
+		Long: `Create an endpoint from the recommended provider, compute, image and model configuration of a catalog model.
+
+The --vendor, --region, --accelerator, --instance-type, --instance-size, --type, --min-replica,
+--max-replica and --revision flags override the recommendation.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			model, err := c.GetCatalogModel(args[0])
+			if err != nil {
+				return err
+			}
+
+			endpoint := model.Endpoint(deployName)
+			flags := cmd.Flags()
+			if flags.Changed("vendor") {
+				endpoint.Provider.Vendor = catalogVendor
+			}
+			if flags.Changed("region") {
+				endpoint.Provider.Region = catalogRegion
+			}
+			if flags.Changed("accelerator") {
+				endpoint.Compute.Accelerator = client.AcceleratorType(catalogAccelerator)
+			}
+			if flags.Changed("instance-type") {
+				endpoint.Compute.InstanceType = catalogInstanceType
+			}
+			if flags.Changed("instance-size") {
+				endpoint.Compute.InstanceSize = catalogInstanceSize
+			}
+			if flags.Changed("type") {
+				endpoint.Type = client.EndpointType(deployType)
+			}
+			if flags.Changed("min-replica") {
+				endpoint.Compute.Scaling.MinReplica = deployMinReplica
+			}
+			if flags.Changed("max-replica") {
+				endpoint.Compute.Scaling.MaxReplica = deployMaxReplica
+			}
+
+			if flags.Changed("revision") || inferencePinCommit {
+				// Pin the recommended revision unless overridden
+				revision := inferenceRevision
+				if !flags.Changed("revision") && endpoint.Model.Revision != nil {
+					revision = *endpoint.Model.Revision
+				}
+				endpoint.Model.Revision, err = modelRevision(c, endpoint.Model.Repository, revision, inferencePinCommit)
+				if err != nil {
+					return err
+				}
+			}
+
+			if deployDryRun {
+				printJSON(endpoint)
+				return nil
+			}
+
+			if err := validateCompute(c, endpoint.Provider, endpoint.Compute); err != nil {
+				return err
+			}
+			if err := preflightModel(c, endpoint.Model); err != nil {
+				return err
+			}
+
+			createdEndpoint, err := c.CreateEndpoint(namespace, endpoint)
+			if err != nil {
+				return err
+			}
+
+			printJSON(createdEndpoint)
+
+			return nil
+		},
+	}
+
+	sizeCmd := &cobra.Command{
+		Use:   "size [repository]",
+		Short: "Recommend the hardware and image serving a model repository, cheapest first",
+		Long: `Recommend the computes and images able to serve a model repository, cheapest first.
+
+The parameter count, dtype, weights size and architecture of the model are read from the hub to
+estimate the memory of its weights, of the kv cache of --max-total-tokens for --concurrency requests
+and of the runtime. Text generation models are proposed on tgi, quantized with eetq (8 bits) or
+bitsandbytes (4 bits), and on llamacpp with each gguf file of the repository. Embedding models are
+proposed on tei and other tasks on the huggingface image.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			meta, err := c.GetModelMetadata(args[0], sizeRevision)
+			if err != nil {
+				return err
+			}
+
+			recommendations, err := c.RecommendHardware(meta, client.SizingOptions{
+				MaxTotalTokens: sizeMaxTotalTokens,
+				Concurrency:    sizeConcurrency,
+				Filter: client.ComputeFilter{
+					Vendor:      catalogVendor,
+					Region:      catalogRegion,
+					Accelerator: client.AcceleratorType(catalogAccelerator),
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if sizeLimit > 0 && len(recommendations) > sizeLimit {
+				recommendations = recommendations[:sizeLimit]
+			}
+
+			printJSON(struct {
+				Model           *client.ModelMetadata           `json:"model"`
+				Recommendations []client.HardwareRecommendation `json:"recommendations"`
+			}{meta, recommendations})
+
+			return nil
+		},
+	}
+
+	sizeCmd.Flags().StringVar(&sizeRevision, "revision", "", "Model revision (default main)")
+	sizeCmd.Flags().IntVar(&sizeMaxTotalTokens, "max-total-tokens", 0, "Tokens of a request, input and output (default 4096, capped by the model context)")
+	sizeCmd.Flags().IntVar(&sizeConcurrency, "concurrency", 1, "Requests served at once at --max-total-tokens")
+	sizeCmd.Flags().StringVar(&catalogVendor, "vendor", "", "Cloud vendor (aws, azure, gcp)")
+	sizeCmd.Flags().StringVar(&catalogRegion, "region", "", "Cloud region")
+	sizeCmd.Flags().StringVar(&catalogAccelerator, "accelerator", "", "Accelerator type (cpu, gpu)")
+	sizeCmd.Flags().IntVar(&sizeLimit, "limit", 10, "Maximum number of recommendations, 0 for all")
+
+	modelsCmd.Flags().StringVar(&catalogTask, "task", "", "Model task (text-generation, feature-extraction, etc.)")
+	modelsCmd.Flags().StringVar(&catalogSearch, "search", "", "Search in the model repositories")
+
+	deployCmd.Flags().StringVar(&namespace, "namespace", "", "Namespace (organization or user) (required)")
+	deployCmd.Flags().StringVar(&deployName, "name", "", "Endpoint name (required)")
+	deployCmd.Flags().StringVar(&catalogVendor, "vendor", "", "Cloud vendor (aws, azure, gcp)")
+	deployCmd.Flags().StringVar(&catalogRegion, "region", "", "Cloud region")
+	deployCmd.Flags().StringVar(&catalogAccelerator, "accelerator", "", "Accelerator type (cpu, gpu, neuron)")
+	deployCmd.Flags().StringVar(&catalogInstanceType, "instance-type", "", "Instance type")
+	deployCmd.Flags().StringVar(&catalogInstanceSize, "instance-size", "", "Instance size")
+	deployCmd.Flags().StringVar(&deployType, "type", "", "Endpoint type (public, protected, private)")
+	deployCmd.Flags().IntVar(&deployMinReplica, "min-replica", 0, "Endpoint minimum replica")
+	deployCmd.Flags().IntVar(&deployMaxReplica, "max-replica", 0, "Endpoint maximum replica")
+	deployCmd.Flags().StringVar(&inferenceRevision, "revision", "", "Model revision, branch, tag or commit")
+	deployCmd.Flags().BoolVar(&inferencePinCommit, "pin-revision", false, "Deploy the commit the revision (default main) points to, unaffected by later pushes")
+	deployCmd.Flags().BoolVar(&deployDryRun, "dry-run", false, "Print the endpoint without creating it")
+	deployCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip the validation of the vendor, region and instance against the catalog")
+	deployCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Skip the check of the repository, revision and model path on the hub")
+
+	deployCmd.MarkFlagRequired("namespace")
+	deployCmd.MarkFlagRequired("name")
+
+	instancesCmd.Flags().StringVar(&catalogVendor, "vendor", "", "Cloud vendor (aws, azure, gcp)")
+	instancesCmd.Flags().StringVar(&catalogRegion, "region", "", "Cloud region")
+	instancesCmd.Flags().StringVar(&catalogAccelerator, "accelerator", "", "Accelerator type (cpu, gpu, neuron)")
+	instancesCmd.Flags().StringVar(&catalogInstanceType, "instance-type", "", "Instance type")
+	instancesCmd.Flags().BoolVar(&catalogAll, "all", false, "Include the unavailable instances")
+
+	catalogCmd.AddCommand(providersCmd)
+	catalogCmd.AddCommand(instancesCmd)
+	catalogCmd.AddCommand(modelsCmd)
+	catalogCmd.AddCommand(deployCmd)
+	catalogCmd.AddCommand(sizeCmd)
+
+	rootCmd.AddCommand(catalogCmd)
+}
+
+// validateCompute - ErrInvalidCompute of the vendor, region and instance missing from the catalog
+func validateCompute(c *client.Client, provider client.EndpointProvider, compute client.EndpointCompute) error {
+	if skipValidation {
+		return nil
+	}
+
+	err := c.ValidateCompute(provider, compute)
+	if errors.Is(err, client.ErrInvalidCompute) {
+		return fmt.Errorf("%w (see `catalog instances`, or --skip-validation)", err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not validate the compute against the catalog: %v\n", err)
+	}
+
+	return nil
+}
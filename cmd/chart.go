@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chartMetrics   []string
+	chartStart     string
+	chartStop      string
+	chartStep      time.Duration
+	chartWidth     int
+	chartHeight    int
+	chartSparkline bool
+	chartASCII     bool
+	chartWatch     bool
+)
+
+func init() {
+	chartCmd := &cobra.Command{
+		Use:   "chart [name]",
+		Short: "Chart endpoint metrics in the terminal",
+		Long: `Chart endpoint metrics in the terminal, one chart per metric series stacked vertically.
+
+With --watch the charts are redrawn every --step over a window sliding with the current time.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, metric := range chartMetrics {
+				if !utils.IsMetricValid(metric) {
+					return fmt.Errorf("invalid metric: %s", metric)
+				}
+			}
+
+			start, err := utils.ParseTime(chartStart)
+			if err != nil {
+				return fmt.Errorf("invalid --start time format : %w", err)
+			}
+
+			stop, err := utils.ParseTime(chartStop)
+			if err != nil {
+				return fmt.Errorf("invalid --stop time format : %w", err)
+			}
+
+			if !start.Before(stop) {
+				return fmt.Errorf("--start must be before --stop")
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			// The watch window keeps the requested span and slides with the current time
+			span := stop.Sub(start)
+
+			for {
+				chart, err := renderCharts(c, args[0], start, stop)
+				if err != nil {
+					return err
+				}
+
+				if !chartWatch {
+					fmt.Print(chart)
+					return nil
+				}
+
+				// Clear the screen before redrawing
+				fmt.Print("\033[H\033[2J")
+				fmt.Print(chart)
+				fmt.Fprintf(os.Stderr, "Refreshed at %s, every %s (ctrl-c to quit)\n", time.Now().Format("15:04:05"), chartStep)
+
+				time.Sleep(chartStep)
+				stop = time.Now()
+				start = stop.Add(-span)
+			}
+		},
+	}
+
+	chartCmd.Flags().StringSliceVar(&chartMetrics, "metrics", []string{"pending-requests"}, "Metrics to chart, e.g. cpu-usage,p95-latency")
+	chartCmd.Flags().StringVar(&chartStart, "start", "-1h", "Chart start, a time or a duration relative to now ( '-6h' )")
+	chartCmd.Flags().StringVar(&chartStop, "stop", "now", "Chart stop, a time or a duration relative to now")
+	chartCmd.Flags().DurationVar(&chartStep, "step", time.Minute, "Resolution of the series and refresh interval with --watch")
+	chartCmd.Flags().IntVar(&chartWidth, "width", 0, "Chart width (defaults to the terminal width)")
+	chartCmd.Flags().IntVar(&chartHeight, "height", 8, "Chart height in rows")
+	chartCmd.Flags().BoolVar(&chartSparkline, "sparkline", false, "Draw a single line sparkline per series")
+	chartCmd.Flags().BoolVar(&chartASCII, "ascii", false, "Draw with ascii characters only")
+	chartCmd.Flags().BoolVar(&chartWatch, "watch", false, "Redraw the charts every step")
+
+	endpointCmd.AddCommand(chartCmd)
+}
+
+// renderCharts - fetch the metrics of the endpoint over [start, stop] and render them
+func renderCharts(c *client.Client, name string, start, stop time.Time) (string, error) {
+	width := chartWidth
+	if width <= 0 {
+		_, width = utils.TerminalSize()
+	}
+
+	var columns []client.MetricColumn
+	for _, metric := range chartMetrics {
+		series, err := c.GetEndpointMetricRange(namespace, name, metric, start, stop, chartStep, 0)
+		if err != nil {
+			return "", err
+		}
+		columns = append(columns, client.MetricColumns(metric, series)...)
+	}
+
+	var b strings.Builder
+	if chartSparkline {
+		titleWidth := 0
+		for _, column := range columns {
+			if len(column.Name) > titleWidth {
+				titleWidth = len(column.Name)
+			}
+		}
+
+		sparkWidth := width - titleWidth - 1
+		if sparkWidth < 10 {
+			sparkWidth = 10
+		}
+
+		for _, column := range columns {
+			values := utils.Resample(column.Series.Points, start, stop, sparkWidth)
+			fmt.Fprintf(&b, "%-*s %s\n", titleWidth, column.Name, utils.Sparkline(values, chartASCII))
+		}
+		for _, column := range columns {
+			fmt.Fprintf(&b, "%-*s %s\n", titleWidth, column.Name, utils.SeriesSummary(column.Series))
+		}
+
+		return b.String(), nil
+	}
+
+	options := utils.ChartOptions{Width: width, Height: chartHeight, ASCII: chartASCII}
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(utils.LineChart(column.Name, column.Series, start, stop, options))
+	}
+
+	if len(columns) == 0 {
+		b.WriteString("no data\n")
+	}
+
+	return b.String(), nil
+}
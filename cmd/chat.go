@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chatSystem      string
+	chatModel       string
+	chatStream      bool
+	chatMaxTokens   int
+	chatTemperature float64
+	chatToolsFile   string
+	chatSchemaFile  string
+	chatLoad        string
+	chatSave        string
+	chatFallback    string
+)
+
+// chatTranscript is the file format used to save and load conversations
+type chatTranscript struct {
+	Endpoint string               `json:"endpoint"`
+	Messages []client.ChatMessage `json:"messages"`
+	Usage    client.Usage         `json:"usage"`
+}
+
+func init() {
+	chatCmd := &cobra.Command{
+		Use:   "chat [name]",
+		Short: "Interactive chat with an endpoint exposing /v1/chat/completions (type /help for commands)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := c.GetEndpoint(namespace, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			var chat client.ChatCompleter
+			if chatFallback != "" {
+				chat = c.NewFallbackChatClient(namespace, args[0], c.NewRouterClient(client.InferenceProvider(chatFallback)))
+			} else if chat, err = c.NewChatClient(*endpoint); err != nil {
+				return err
+			}
+
+			transcript := &chatTranscript{Endpoint: args[0]}
+			if chatLoad != "" {
+				if transcript, err = loadTranscript(chatLoad); err != nil {
+					return err
+				}
+			} else if chatSystem != "" {
+				transcript.Messages = append(transcript.Messages, client.ChatMessage{Role: client.ChatRoleSystem, Content: chatSystem})
+			}
+
+			request := client.ChatCompletionRequest{Model: chatModel}
+			if cmd.Flags().Changed("max-tokens") {
+				request.MaxTokens = &chatMaxTokens
+			}
+			if cmd.Flags().Changed("temperature") {
+				request.Temperature = &chatTemperature
+			}
+			if chatToolsFile != "" {
+				if err := readJSONFile(chatToolsFile, &request.Tools); err != nil {
+					return err
+				}
+			}
+			if chatSchemaFile != "" {
+				schema, err := os.ReadFile(chatSchemaFile)
+				if err != nil {
+					return err
+				}
+				request.ResponseFormat = &client.ResponseFormat{
+					Type:       client.ResponseFormatJSONSchema,
+					JSONSchema: &client.JSONSchemaFormat{Name: "response", Schema: schema},
+				}
+			}
+
+			runChatREPL(chat, request, transcript, os.Stdin)
+
+			if chatSave != "" {
+				if err := saveTranscript(chatSave, transcript); err != nil {
+					return err
+				}
+				fmt.Printf("Transcript saved to %s.\n", chatSave)
+			}
+
+			return nil
+		},
+	}
+
+	chatCmd.Flags().StringVar(&chatSystem, "system", "", "System prompt")
+	chatCmd.Flags().StringVar(&chatModel, "model", client.DefaultChatModel, "Model name sent in requests")
+	chatCmd.Flags().BoolVar(&chatStream, "stream", true, "Stream the assistant answers")
+	chatCmd.Flags().IntVar(&chatMaxTokens, "max-tokens", 0, "Maximum number of tokens per answer")
+	chatCmd.Flags().Float64Var(&chatTemperature, "temperature", 0, "Sampling temperature")
+	chatCmd.Flags().StringVar(&chatToolsFile, "tools", "", "JSON file holding the tools (functions) the model can call")
+	chatCmd.Flags().StringVar(&chatSchemaFile, "json-schema", "", "JSON schema file the answers must follow")
+	chatCmd.Flags().StringVar(&chatLoad, "load", "", "Transcript file to resume the conversation from")
+	chatCmd.Flags().StringVar(&chatSave, "save", "", "Transcript file written when the session ends")
+	chatCmd.Flags().StringVar(&chatFallback, "fallback", "", "Provider answering through the serverless router while the endpoint is not running (auto picks the first live one)")
+
+	endpointCmd.AddCommand(chatCmd)
+}
+
+func runChatREPL(chat client.ChatCompleter, request client.ChatCompletionRequest, transcript *chatTranscript, input io.Reader) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		pending := pendingToolCalls(transcript.Messages)
+		switch {
+		case line == "/tool" || strings.HasPrefix(line, "/tool "):
+			message, err := toolResult(line, pending)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			transcript.Messages = append(transcript.Messages, message)
+			// The model answers once every call of its last message has a result
+			if len(pending) > 1 {
+				continue
+			}
+		case strings.HasPrefix(line, "/"):
+			if quit := runChatCommand(line, transcript); quit {
+				return
+			}
+			continue
+		case len(pending) > 0:
+			fmt.Printf("answer the tool calls first with /tool <id> <result> (pending: %s)\n", toolCallIDs(pending))
+			continue
+		default:
+			transcript.Messages = append(transcript.Messages, client.ChatMessage{Role: client.ChatRoleUser, Content: line})
+		}
+		request.Messages = transcript.Messages
+
+		message, usage, err := chatTurn(chat, request)
+		if err != nil {
+			fmt.Println(err)
+			// Drop the unanswered message so the user can retry
+			transcript.Messages = transcript.Messages[:len(transcript.Messages)-1]
+			continue
+		}
+
+		for _, call := range message.ToolCalls {
+			fmt.Printf("[tool call %s] %s(%s)\n", call.ID, call.Function.Name, call.Function.Arguments)
+		}
+		if len(message.ToolCalls) > 0 {
+			fmt.Println("Answer each call with /tool <id> <result>.")
+		}
+
+		transcript.Messages = append(transcript.Messages, message)
+		transcript.Usage.Add(usage)
+	}
+}
+
+// chatTurn - send the conversation and print the answer, streamed or not
+func chatTurn(chat client.ChatCompleter, request client.ChatCompletionRequest) (client.ChatMessage, *client.Usage, error) {
+	if !chatStream {
+		response, err := chat.ChatCompletion(request)
+		if err != nil {
+			return client.ChatMessage{}, nil, err
+		}
+		if len(response.Choices) == 0 {
+			return client.ChatMessage{}, nil, fmt.Errorf("empty chat completion response")
+		}
+
+		fmt.Println(response.Choices[0].Message.Content)
+
+		return response.Choices[0].Message, response.Usage, nil
+	}
+
+	stream, err := chat.ChatCompletionStream(request)
+	if err != nil {
+		return client.ChatMessage{}, nil, err
+	}
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println()
+			return client.ChatMessage{}, nil, err
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				fmt.Print(choice.Delta.Content)
+			}
+		}
+	}
+	fmt.Println()
+
+	return stream.Message(), stream.Usage(), nil
+}
+
+// pendingToolCalls - the tool calls of the last assistant message which have no result yet
+func pendingToolCalls(messages []client.ChatMessage) []client.ToolCall {
+	answered := map[string]bool{}
+	for i := len(messages) - 1; i >= 0; i-- {
+		switch messages[i].Role {
+		case client.ChatRoleTool:
+			answered[messages[i].ToolCallID] = true
+		case client.ChatRoleAssistant:
+			var pending []client.ToolCall
+			for _, call := range messages[i].ToolCalls {
+				if !answered[call.ID] {
+					pending = append(pending, call)
+				}
+			}
+			return pending
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// toolResult - tool message of a "/tool <id> <result>" line answering one of the pending calls
+func toolResult(line string, pending []client.ToolCall) (client.ChatMessage, error) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return client.ChatMessage{}, errors.New("usage: /tool <id> <result>")
+	}
+
+	for _, call := range pending {
+		if call.ID == fields[1] {
+			return client.ChatMessage{Role: client.ChatRoleTool, ToolCallID: call.ID, Name: call.Function.Name, Content: strings.TrimSpace(fields[2])}, nil
+		}
+	}
+
+	if len(pending) == 0 {
+		return client.ChatMessage{}, errors.New("no tool call is waiting for a result")
+	}
+
+	return client.ChatMessage{}, fmt.Errorf("unknown tool call %s (pending: %s)", fields[1], toolCallIDs(pending))
+}
+
+func toolCallIDs(calls []client.ToolCall) string {
+	ids := make([]string, len(calls))
+	for i, call := range calls {
+		ids[i] = call.ID
+	}
+
+	return strings.Join(ids, ", ")
+}
+
+// runChatCommand - handle a /command, returns true when the session must end
+func runChatCommand(line string, transcript *chatTranscript) bool {
+	fields := strings.Fields(line)
+
+	switch fields[0] {
+	case "/exit", "/quit":
+		return true
+	case "/reset":
+		var kept []client.ChatMessage
+		for _, message := range transcript.Messages {
+			if message.Role == client.ChatRoleSystem {
+				kept = append(kept, message)
+			}
+		}
+		transcript.Messages = kept
+		fmt.Println("Conversation reset.")
+	case "/usage":
+		printJSON(transcript.Usage)
+	case "/history":
+		for _, message := range transcript.Messages {
+			fmt.Printf("[%s] %s\n", message.Role, message.Content)
+		}
+	case "/save":
+		if len(fields) < 2 {
+			fmt.Println("usage: /save <file>")
+			break
+		}
+		if err := saveTranscript(fields[1], transcript); err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Printf("Transcript saved to %s.\n", fields[1])
+	case "/load":
+		if len(fields) < 2 {
+			fmt.Println("usage: /load <file>")
+			break
+		}
+		loaded, err := loadTranscript(fields[1])
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		*transcript = *loaded
+		fmt.Printf("Loaded %d messages from %s.\n", len(transcript.Messages), fields[1])
+	default:
+		fmt.Println("commands: /tool <id> <result>, /save <file>, /load <file>, /history, /usage, /reset, /exit")
+	}
+
+	return false
+}
+
+func saveTranscript(path string, transcript *chatTranscript) error {
+	data, err := json.MarshalIndent(transcript, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func loadTranscript(path string) (*chatTranscript, error) {
+	var transcript chatTranscript
+	if err := readJSONFile(path, &transcript); err != nil {
+		return nil, err
+	}
+
+	return &transcript, nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid json in %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	costPricing    string
+	costRefresh    bool
+	costWindow     time.Duration
+	costNamespaces []string
+)
+
+// CostReport is the output of `endpoint cost`
+type CostReport struct {
+	Currency    string                        `json:"currency"`
+	From        time.Time                     `json:"from"`
+	To          time.Time                     `json:"to"`
+	Endpoints   []client.EndpointCost         `json:"endpoints"`
+	ByTag       map[string]client.CostSummary `json:"byTag"`
+	ByNamespace map[string]client.CostSummary `json:"byNamespace"`
+}
+
+func init() {
+	costCmd := &cobra.Command{
+		Use:   "cost [name]",
+		Short: "Estimate the hourly and monthly cost of endpoints",
+		Long: `Estimate the hourly and monthly cost of an endpoint, or of all the endpoints of the namespaces,
+aggregated by tag and by namespace.
+
+Costs are the price of the endpoint instance times its average running replicas over --window, read
+from the running-replicas metric, or from the ready replicas without metric data. Prices come from
+the pricing catalog file, fetched from the provider catalog api when it does not exist or with --refresh.
+Catalog entries with the "*" region apply to every region of their vendor.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			catalog, err := loadPricingCatalog(c)
+			if err != nil {
+				return err
+			}
+
+			to := time.Now()
+			from := to.Add(-costWindow)
+			report := CostReport{Currency: catalog.Currency, From: from, To: to}
+
+			namespaces := append([]string{namespace}, costNamespaces...)
+			for _, ns := range namespaces {
+				var endpoints []client.EndpointWithStatus
+				if len(args) == 1 {
+					endpoint, err := c.GetEndpoint(ns, args[0])
+					if err != nil {
+						return err
+					}
+					endpoints = append(endpoints, *endpoint)
+				} else if endpoints, err = c.ListEndpoints(ns, nil); err != nil {
+					return err
+				}
+
+				for _, endpoint := range endpoints {
+					cost := c.EstimateEndpointCost(ns, endpoint, catalog, from, to)
+					if !cost.Priced {
+						fmt.Fprintf(os.Stderr, "No price for %s (%s), counted as free\n", cost.Endpoint, cost.Instance)
+					}
+					report.Endpoints = append(report.Endpoints, cost)
+				}
+			}
+
+			report.ByTag, report.ByNamespace = client.AggregateCosts(report.Endpoints)
+			printJSON(report)
+
+			return nil
+		},
+	}
+
+	costCmd.Flags().StringVar(&costPricing, "pricing", "", "Pricing catalog file (default $HF_HOME/endpoint-pricing.json)")
+	costCmd.Flags().BoolVar(&costRefresh, "refresh", false, "Refresh the pricing catalog from the provider catalog api")
+	costCmd.Flags().DurationVar(&costWindow, "window", 24*time.Hour, "Window over which running replicas are averaged")
+	costCmd.Flags().StringSliceVar(&costNamespaces, "namespaces", nil, "Further namespaces to estimate along the --namespace one")
+
+	endpointCmd.AddCommand(costCmd)
+}
+
+// loadPricingCatalog - catalog of the --pricing file, fetched and saved when missing or refreshed
+func loadPricingCatalog(c *client.Client) (*client.PricingCatalog, error) {
+	path := costPricing
+	if path == "" {
+		var err error
+		if path, err = client.PricingPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !costRefresh {
+		catalog, err := client.LoadPricingCatalog(path)
+		if !errors.Is(err, os.ErrNotExist) {
+			return catalog, err
+		}
+	}
+
+	catalog, err := c.FetchPricingCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch the pricing catalog: %w", err)
+	}
+	if err := catalog.Save(path); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %d prices to %s\n", len(catalog.Prices), path)
+
+	return catalog, nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+const embedChunkSize = 1024
+
+var (
+	embedInputFile   string
+	embedOutFile     string
+	embedNormalize   bool
+	embedTruncate    bool
+	embedConcurrency int
+	embedBatchSize   int
+)
+
+type embeddingLine struct {
+	Index     int       `json:"index"`
+	Input     string    `json:"input"`
+	Embedding []float64 `json:"embedding"`
+}
+
+func init() {
+	embedCmd := &cobra.Command{
+		Use:   "embed [name]",
+		Short: "Embed every line of a file with a tei or llamacpp embeddings endpoint, writing jsonl",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := c.GetEndpoint(namespace, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			embedder, err := c.NewEmbeddingClient(*endpoint)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("concurrency") {
+				embedder.SetConcurrency(embedConcurrency)
+			}
+			if cmd.Flags().Changed("batch-size") {
+				embedder.MaxBatchSize = embedBatchSize
+			}
+
+			input := os.Stdin
+			if embedInputFile != "-" {
+				input, err = os.Open(embedInputFile)
+				if err != nil {
+					return err
+				}
+				defer input.Close()
+			}
+
+			output := os.Stdout
+			if embedOutFile != "" && embedOutFile != "-" {
+				output, err = os.Create(embedOutFile)
+				if err != nil {
+					return err
+				}
+				defer output.Close()
+			}
+
+			var normalize, truncate *bool
+			if cmd.Flags().Changed("normalize") {
+				normalize = &embedNormalize
+			}
+			if cmd.Flags().Changed("truncate") {
+				truncate = &embedTruncate
+			}
+
+			count, err := embedLines(embedder, input, output, normalize, truncate)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Embedded %d lines.\n", count)
+
+			return nil
+		},
+	}
+
+	embedCmd.Flags().StringVar(&embedInputFile, "input-file", "", "File with one input per line, - for stdin (required)")
+	embedCmd.Flags().StringVar(&embedOutFile, "out", "", "Output jsonl file (defaults to stdout)")
+	embedCmd.Flags().BoolVar(&embedNormalize, "normalize", true, "Normalize embeddings (tei only)")
+	embedCmd.Flags().BoolVar(&embedTruncate, "truncate", false, "Truncate inputs exceeding the model max length (tei only)")
+	embedCmd.Flags().IntVar(&embedConcurrency, "concurrency", client.DefaultEmbeddingConcurrency, "Number of batches sent in parallel (capped by the image max concurrent requests)")
+	embedCmd.Flags().IntVar(&embedBatchSize, "batch-size", client.DefaultMaxBatchSize, "Maximum number of inputs per request")
+
+	embedCmd.MarkFlagRequired("input-file")
+
+	endpointCmd.AddCommand(embedCmd)
+}
+
+// embedLines - embed the non empty lines of input by chunks, writing one json line per input
+func embedLines(embedder *client.EmbeddingClient, input io.Reader, output io.Writer, normalize, truncate *bool) (int, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(output)
+
+	index := 0
+	lines := make([]string, 0, embedChunkSize)
+
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+
+		vectors, err := embedder.EmbedBatched(lines, normalize, truncate)
+		if err != nil {
+			return fmt.Errorf("lines %d-%d: %w", index, index+len(lines)-1, err)
+		}
+
+		for i, line := range lines {
+			if err := encoder.Encode(embeddingLine{Index: index + i, Input: line, Embedding: vectors[i]}); err != nil {
+				return err
+			}
+		}
+
+		index += len(lines)
+		lines = lines[:0]
+
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+		if len(lines) == embedChunkSize {
+			if err := flush(); err != nil {
+				return index, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return index, err
+	}
+
+	if err := flush(); err != nil {
+		return index, err
+	}
+
+	return index, nil
+}
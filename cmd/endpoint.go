@@ -11,8 +11,6 @@ import (
 )
 
 var (
-	host                  string
-	token                 string
 	namespace             string
 	inferenceName         string
 	inferenceRepository   string
@@ -32,23 +30,27 @@ var (
 	inferenceInstanceType string
 	inferenceMinReplica   int
 	inferenceMaxReplica   int
+	inferenceRevision     string
+	inferencePinCommit    bool
+	skipValidation        bool
+	skipPreflight         bool
 	logsReplicaID         string
 	startTime             string
 	stopTime              string
 	metricStep            string
 )
 
-func init() {
-	endpointCmd := &cobra.Command{
-		Use:   "endpoint",
-		Short: "Manage endpoints",
-	}
+var endpointCmd = &cobra.Command{
+	Use:   "endpoint",
+	Short: "Manage endpoints",
+}
 
+func init() {
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List endpoints",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -69,7 +71,7 @@ func init() {
 		Use:   "create",
 		Short: "Create an endpoint",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -110,6 +112,18 @@ func init() {
 				},
 			}
 
+			endpoint.Model.Revision, err = modelRevision(c, endpoint.Model.Repository, inferenceRevision, inferencePinCommit)
+			if err != nil {
+				return err
+			}
+
+			if err := validateCompute(c, endpoint.Provider, endpoint.Compute); err != nil {
+				return err
+			}
+			if err := preflightModel(c, endpoint.Model); err != nil {
+				return err
+			}
+
 			createdEndpoint, err := c.CreateEndpoint(namespace, endpoint)
 			if err != nil {
 				fmt.Println(err)
@@ -127,7 +141,7 @@ func init() {
 		Short: "Get an endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -149,7 +163,7 @@ func init() {
 		Short: "Update an existing endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -194,6 +208,29 @@ func init() {
 				endpointUpdate.Compute = compute
 			}
 
+			// Validate the instance resulting from the changed compute flags
+			if compute.Accelerator != nil || compute.InstanceType != nil || compute.InstanceSize != nil {
+				current, err := c.GetEndpoint(namespace, args[0])
+				if err != nil {
+					return err
+				}
+
+				merged := current.Compute
+				if compute.Accelerator != nil {
+					merged.Accelerator = *compute.Accelerator
+				}
+				if compute.InstanceType != nil {
+					merged.InstanceType = *compute.InstanceType
+				}
+				if compute.InstanceSize != nil {
+					merged.InstanceSize = *compute.InstanceSize
+				}
+
+				if err := validateCompute(c, current.Provider, merged); err != nil {
+					return err
+				}
+			}
+
 			// Update Model
 			model := &client.EndpointModelUpdate{}
 			anyModelField := false
@@ -202,6 +239,23 @@ func init() {
 				model.Repository = &inferenceRepository
 				anyModelField = true
 			}
+			if cmd.Flags().Changed("revision") || inferencePinCommit {
+				repository := inferenceRepository
+				if !cmd.Flags().Changed("repository") {
+					current, err := c.GetEndpoint(namespace, args[0])
+					if err != nil {
+						return err
+					}
+					repository = current.Model.Repository
+				}
+
+				revision, err := modelRevision(c, repository, inferenceRevision, inferencePinCommit)
+				if err != nil {
+					return err
+				}
+				model.Revision = revision
+				anyModelField = true
+			}
 			if cmd.Flags().Changed("framework") {
 				framework := client.EndpointFramework(inferenceFramework)
 				model.Framework = &framework
@@ -232,6 +286,29 @@ func init() {
 				endpointUpdate.Model = model
 			}
 
+			// Check the model resulting from the changed model flags
+			if model.Repository != nil || model.Revision != nil || model.Image != nil {
+				current, err := c.GetEndpoint(namespace, args[0])
+				if err != nil {
+					return err
+				}
+
+				merged := current.Model
+				if model.Repository != nil {
+					merged.Repository = *model.Repository
+				}
+				if model.Revision != nil {
+					merged.Revision = model.Revision
+				}
+				if model.Image != nil {
+					merged.Image = *model.Image
+				}
+
+				if err := preflightModel(c, merged); err != nil {
+					return err
+				}
+			}
+
 			// Nothing was updated
 			if endpointUpdate.Compute == nil && endpointUpdate.Model == nil && endpointUpdate.Type == nil {
 				return fmt.Errorf("no update flags were provided, nothing to update")
@@ -254,7 +331,7 @@ func init() {
 		Short: "Delete an endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -276,7 +353,7 @@ func init() {
 		Short: "Get logs from an endpoint (optionally filtered by replica)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -303,7 +380,7 @@ func init() {
 		Short: "Stream live logs from an endpoint (optionally filtered by replica)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -353,7 +430,7 @@ func init() {
 				return fmt.Errorf("invalid --stop time format : %w", err)
 			}
 
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -392,7 +469,7 @@ func init() {
 				return fmt.Errorf("invalid --stop time format : %w", err)
 			}
 
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -427,7 +504,7 @@ func init() {
 		Short: "Pause an endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -449,7 +526,7 @@ func init() {
 		Short: "Get endpoint replica statuses",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -471,7 +548,7 @@ func init() {
 		Short: "Resume an endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -493,7 +570,7 @@ func init() {
 		Short: "Scale an endpoint to zero",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -515,7 +592,7 @@ func init() {
 		Short: "Stream SSE info from an endpoint",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.NewClient(&host, &token)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -543,6 +620,8 @@ func init() {
 
 	createCmd.Flags().StringVar(&inferenceName, "name", "", "Endpoint name (required)")
 	createCmd.Flags().StringVar(&inferenceRepository, "repository", "", "Model repository (required)")
+	createCmd.Flags().StringVar(&inferenceRevision, "revision", "", "Model revision, branch, tag or commit (default main)")
+	createCmd.Flags().BoolVar(&inferencePinCommit, "pin-revision", false, "Deploy the commit the revision points to, unaffected by later pushes")
 	createCmd.Flags().StringVar(&inferenceFramework, "framework", "", "Model framework (pytorch, custom, llamacpp)")
 	createCmd.Flags().StringVar(&inferenceImage, "image", "huggingface", "Model image (huggingface, huggingfaceNeuron, tgi, tgiNeuron, tei, llamacpp, custom)")
 	createCmd.Flags().StringVar(&inferenceImageUrl, "url", "", "Model image url (required for tgi, tgiNeuron, tei, llamacpp, custom) using format https://host/image:tag")
@@ -559,6 +638,8 @@ func init() {
 	createCmd.Flags().StringVar(&inferenceInstanceType, "instance-type", "default", "Instance type")
 	createCmd.Flags().IntVar(&inferenceMinReplica, "min-replica", 0, "Endpoint minimum replica")
 	createCmd.Flags().IntVar(&inferenceMaxReplica, "max-replica", 0, "Endpoint maximum replica")
+	createCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip the validation of the vendor, region and instance against the catalog")
+	createCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Skip the check of the repository, revision and model path on the hub")
 
 	createCmd.MarkFlagRequired("name")
 	createCmd.MarkFlagRequired("repository")
@@ -569,6 +650,8 @@ func init() {
 	createCmd.MarkFlagRequired("image")
 
 	updateCmd.Flags().StringVar(&inferenceRepository, "repository", "", "Model repository")
+	updateCmd.Flags().StringVar(&inferenceRevision, "revision", "", "Model revision, branch, tag or commit")
+	updateCmd.Flags().BoolVar(&inferencePinCommit, "pin-revision", false, "Deploy the commit the revision (default main) points to, unaffected by later pushes")
 	updateCmd.Flags().StringVar(&inferenceFramework, "framework", "", "Model framework (pytorch, custom, llamacpp)")
 	updateCmd.Flags().StringVar(&inferenceImage, "image", "huggingface", "Model image (huggingface, huggingfaceNeuron, tgi, tgiNeuron, tei, llamacpp, custom)")
 	updateCmd.Flags().StringVar(&inferenceImageUrl, "url", "", "Model image url (for tgi, tgiNeuron, tei, llamacpp, custom) format https://host/image:tag")
@@ -585,6 +668,8 @@ func init() {
 	updateCmd.Flags().StringVar(&inferenceInstanceType, "instance-type", "default", "Instance type")
 	updateCmd.Flags().IntVar(&inferenceMinReplica, "min-replica", 0, "Endpoint minimum replica")
 	updateCmd.Flags().IntVar(&inferenceMaxReplica, "max-replica", 0, "Endpoint maximum replica")
+	updateCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip the validation of the instance against the catalog")
+	updateCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Skip the check of the repository, revision and model path on the hub")
 
 	logsCmd.Flags().StringVar(&logsReplicaID, "replica", "", "Replica ID to filter logs (optional)")
 
@@ -603,11 +688,8 @@ func init() {
 	getMetricCmd.MarkFlagRequired("start")
 	getMetricCmd.MarkFlagRequired("stop")
 
-	endpointCmd.PersistentFlags().StringVar(&token, "token", "", "Authorization Bearer token (required)")
 	endpointCmd.PersistentFlags().StringVar(&namespace, "namespace", "", "Namespace (organization or user) (required)")
-	endpointCmd.PersistentFlags().StringVar(&host, "host", "", "API host URL (optional)")
 
-	endpointCmd.MarkPersistentFlagRequired("token")
 	endpointCmd.MarkPersistentFlagRequired("namespace")
 
 	endpointCmd.AddCommand(listCmd)
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/exporter"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterListen          string
+	exporterNamespaces      []string
+	exporterMetrics         []string
+	exporterInterval        time.Duration
+	exporterMetricsInterval time.Duration
+	exporterWindow          time.Duration
+	exporterConcurrency     int
+)
+
+func init() {
+	exporterCmd := &cobra.Command{
+		Use:   "exporter",
+		Short: "Serve endpoint states and metrics in the Prometheus exposition format",
+		Long: `Serve endpoint states and metrics on /metrics in the Prometheus exposition format.
+
+Endpoints are listed every --interval and their metrics fetched at most every --metrics-interval,
+scrapes are served from this cache so that the api rate limits do not depend on the scrape frequency.
+Namespaces default to the user and organizations reachable by the token.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, metric := range exporterMetrics {
+				if !utils.IsMetricValid(metric) {
+					return fmt.Errorf("invalid metric: %s", metric)
+				}
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			namespaces := exporterNamespaces
+			if len(namespaces) == 0 {
+				whoami, err := c.WhoAmI()
+				if err != nil {
+					return err
+				}
+				for _, ns := range whoami.Namespaces() {
+					namespaces = append(namespaces, ns.Name)
+				}
+			}
+
+			e := exporter.New(c, namespaces, exporterMetrics)
+			e.Interval = exporterInterval
+			e.MetricsInterval = exporterMetricsInterval
+			e.Window = exporterWindow
+			e.Concurrency = exporterConcurrency
+			e.ErrorLog = log.New(os.Stderr, "exporter: ", log.LstdFlags)
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			go e.Run(ctx)
+
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", e)
+			server := &http.Server{Addr: exporterListen, Handler: mux}
+
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer shutdownCancel()
+				server.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(os.Stderr, "Serving metrics of %v on %s/metrics\n", namespaces, exporterListen)
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9400", "Address the metrics are served on")
+	exporterCmd.Flags().StringSliceVar(&exporterNamespaces, "namespace", nil, "Namespaces to export (repeatable, defaults to all the namespaces of the token)")
+	exporterCmd.Flags().StringSliceVar(&exporterMetrics, "metrics", utils.Metrics, "Endpoint metrics to export")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", exporter.DefaultInterval, "Interval between two listings of the endpoints")
+	exporterCmd.Flags().DurationVar(&exporterMetricsInterval, "metrics-interval", exporter.DefaultMetricsInterval, "Minimum interval between two fetches of an endpoint metric")
+	exporterCmd.Flags().DurationVar(&exporterWindow, "window", exporter.DefaultWindow, "Lookback of metric queries")
+	exporterCmd.Flags().IntVar(&exporterConcurrency, "concurrency", exporter.DefaultConcurrency, "Number of metric queries run in parallel")
+
+	rootCmd.AddCommand(exporterCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generatePrompt       string
+	generateStream       bool
+	generateMaxNewTokens int
+	generateTemperature  float64
+	generateTopP         float64
+	generateStop         []string
+	generateGrammarType  string
+	generateGrammar      string
+	generateDetails      bool
+)
+
+func init() {
+	generateCmd := &cobra.Command{
+		Use:   "generate [name]",
+		Short: "Generate text with an endpoint deployed with a tgi image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := c.GetEndpoint(namespace, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			tgi, err := c.NewTGIClient(*endpoint)
+			if err != nil {
+				return err
+			}
+
+			parameters, err := buildTGIParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			request := client.TGIGenerateRequest{
+				Inputs:     generatePrompt,
+				Parameters: parameters,
+			}
+
+			if !generateStream {
+				response, err := tgi.Generate(request)
+				if err != nil {
+					fmt.Println(err)
+					return nil
+				}
+
+				if generateDetails {
+					printJSON(response)
+				} else {
+					fmt.Println(response.GeneratedText)
+				}
+
+				return nil
+			}
+
+			stream, err := tgi.GenerateStream(request)
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+			defer stream.Close()
+
+			var details *client.TGIDetails
+			for {
+				response, err := stream.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					fmt.Println()
+					return err
+				}
+
+				if !response.Token.Special {
+					fmt.Print(response.Token.Text)
+				}
+				if response.Details != nil {
+					details = response.Details
+				}
+			}
+			fmt.Println()
+
+			if generateDetails && details != nil {
+				printJSON(details)
+			}
+
+			return nil
+		},
+	}
+
+	generateCmd.Flags().StringVar(&generatePrompt, "prompt", "", "Prompt to complete (required)")
+	generateCmd.Flags().BoolVar(&generateStream, "stream", false, "Print tokens as they are generated")
+	generateCmd.Flags().IntVar(&generateMaxNewTokens, "max-new-tokens", 0, "Maximum number of generated tokens")
+	generateCmd.Flags().Float64Var(&generateTemperature, "temperature", 0, "Sampling temperature")
+	generateCmd.Flags().Float64Var(&generateTopP, "top-p", 0, "Nucleus sampling probability")
+	generateCmd.Flags().StringSliceVar(&generateStop, "stop", nil, "Stop sequences (repeatable)")
+	generateCmd.Flags().StringVar(&generateGrammarType, "grammar-type", "json", "Grammar type (json, regex)")
+	generateCmd.Flags().StringVar(&generateGrammar, "grammar", "", "Grammar constraining the output: json schema, @file containing it, or regex")
+	generateCmd.Flags().BoolVar(&generateDetails, "details", false, "Print generation details (finish reason, tokens)")
+
+	generateCmd.MarkFlagRequired("prompt")
+
+	endpointCmd.AddCommand(generateCmd)
+}
+
+// buildTGIParameters - tgi parameters from the generate flags, only the flags explicitly set are sent
+func buildTGIParameters(cmd *cobra.Command) (*client.TGIParameters, error) {
+	parameters := &client.TGIParameters{}
+
+	if cmd.Flags().Changed("max-new-tokens") {
+		parameters.MaxNewTokens = &generateMaxNewTokens
+	}
+	if cmd.Flags().Changed("temperature") {
+		parameters.Temperature = &generateTemperature
+	}
+	if cmd.Flags().Changed("top-p") {
+		parameters.TopP = &generateTopP
+	}
+	if len(generateStop) > 0 {
+		parameters.Stop = generateStop
+	}
+	if generateDetails {
+		parameters.Details = &generateDetails
+	}
+
+	if generateGrammar != "" {
+		switch client.TGIGrammarType(generateGrammarType) {
+		case client.TGIGrammarRegex:
+			parameters.Grammar = client.NewTGIRegexGrammar(generateGrammar)
+		case client.TGIGrammarJSON:
+			schema := []byte(generateGrammar)
+			if generateGrammar[0] == '@' {
+				content, err := os.ReadFile(generateGrammar[1:])
+				if err != nil {
+					return nil, err
+				}
+				schema = content
+			}
+			if !json.Valid(schema) {
+				return nil, fmt.Errorf("--grammar is not a valid json schema")
+			}
+			parameters.Grammar = &client.TGIGrammar{Type: client.TGIGrammarJSON, Value: schema}
+		default:
+			return nil, fmt.Errorf("unsupported grammar type: %s", generateGrammarType)
+		}
+	}
+
+	return parameters, nil
+}
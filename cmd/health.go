@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthTimeout  time.Duration
+	healthWait     time.Duration
+	healthInterval time.Duration
+)
+
+// Exit codes of `endpoint health`, 1 is left to command errors
+var healthExitCodes = map[client.HealthStatus]int{
+	client.HealthHealthy:   0,
+	client.HealthDegraded:  2,
+	client.HealthNotReady:  3,
+	client.HealthUnhealthy: 4,
+}
+
+func init() {
+	healthCmd := &cobra.Command{
+		Use:   "health [name]",
+		Short: "Check the health of an endpoint",
+		Long: `Check the health of an endpoint from its state, its ready replicas and a probe of the health route of its image.
+
+Exit codes: 0 healthy, 1 command error, 2 degraded, 3 not ready, 4 unhealthy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			deadline := time.Now().Add(healthWait)
+			for {
+				endpoint, err := c.GetEndpoint(namespace, args[0])
+				if err != nil {
+					return err
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+				report, err := c.HealthCheckContext(ctx, *endpoint)
+				cancel()
+				if err != nil {
+					return err
+				}
+
+				if report.Health == client.HealthHealthy || !time.Now().Add(healthInterval).Before(deadline) {
+					printJSON(report)
+					return exitCode(cmd, healthExitCodes[report.Health])
+				}
+
+				fmt.Fprintf(os.Stderr, "Endpoint %s is %s (%s), retrying in %s...\n", report.Name, report.Health, report.State, healthInterval)
+				time.Sleep(healthInterval)
+			}
+		},
+	}
+
+	healthCmd.Flags().DurationVar(&healthTimeout, "timeout", client.DefaultHealthTimeout, "Timeout of the health route probe")
+	healthCmd.Flags().DurationVar(&healthWait, "wait", 0, "Keep checking until the endpoint is healthy or this duration elapses")
+	healthCmd.Flags().DurationVar(&healthInterval, "interval", 10*time.Second, "Interval between checks with --wait")
+
+	endpointCmd.AddCommand(healthCmd)
+}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/hub"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hubRevision    string
+	hubPath        string
+	hubLimit       int
+	hubCacheDir    string
+	hubChunks      int
+	hubForce       bool
+	hubMessage     string
+	hubDescription string
+	hubPrivate     bool
+	hubCreate      bool
+	hubExclude     []string
+	hubDelete      []string
+	hubWorkers     int
+)
+
+var hubCmd = &cobra.Command{
+	Use:   "hub",
+	Short: "Inspect, download and upload the model repositories of the hub",
+}
+
+func init() {
+	infoCmd := &cobra.Command{
+		Use:   "info [repository]",
+		Short: "Get a model repository at a revision",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			info, err := h.ModelInfo(args[0], hubRevision)
+			if err != nil {
+				return err
+			}
+
+			printJSON(info)
+
+			return nil
+		},
+	}
+
+	filesCmd := &cobra.Command{
+		Use:   "files [repository]",
+		Short: "List the files of a model repository at a revision",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			files, err := h.ListRepoFiles(args[0], hubRevision, hubPath)
+			if err != nil {
+				return err
+			}
+
+			printJSON(files)
+
+			return nil
+		},
+	}
+
+	refsCmd := &cobra.Command{
+		Use:   "refs [repository]",
+		Short: "List the branches and tags of a model repository",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			refs, err := h.ListRefs(args[0])
+			if err != nil {
+				return err
+			}
+
+			printJSON(refs)
+
+			return nil
+		},
+	}
+
+	commitsCmd := &cobra.Command{
+		Use:   "commits [repository]",
+		Short: "List the commits of a model repository revision, most recent first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			commits, err := h.ListCommits(args[0], hubRevision, hubLimit)
+			if err != nil {
+				return err
+			}
+
+			printJSON(commits)
+
+			return nil
+		},
+	}
+
+	resolveCmd := &cobra.Command{
+		Use:   "resolve [repository]",
+		Short: "Print the commit sha a branch, tag or commit of a model repository points to",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			sha, err := h.ResolveRevision(args[0], hubRevision)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(sha)
+
+			return nil
+		},
+	}
+
+	downloadCmd := &cobra.Command{
+		Use:   "download [repository] [files...]",
+		Short: "Download files of a model repository into the hub cache, all of them when none is given",
+		Long: `Download files of a model repository into the hub cache shared with huggingface_hub and print their paths.
+
+Files already in the cache are not downloaded again. Large files are downloaded as --chunks parallel
+ranges, an interrupted download resumes where it stopped when the command is run again, and each file
+is checked against the sha256 or git blob id the hub reports for it.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := interruptible("rerun the same command to resume")
+			defer stop()
+
+			repository, files, revision := args[0], args[1:], hubRevision
+			if len(files) == 0 {
+				// Pin the listing and the downloads to the same commit
+				revision, err = h.ResolveRevision(repository, hubRevision)
+				if err != nil {
+					return err
+				}
+
+				repoFiles, err := h.ListRepoFiles(repository, revision, "")
+				if err != nil {
+					return err
+				}
+				for _, file := range repoFiles {
+					files = append(files, file.Path)
+				}
+			}
+
+			progress := printProgress()
+			for _, file := range files {
+				snapshot, err := h.Download(ctx, repository, file, hub.DownloadOptions{
+					Revision: revision,
+					CacheDir: hubCacheDir,
+					Chunks:   hubChunks,
+					Force:    hubForce,
+					Progress: func(p hub.DownloadProgress) {
+						progress(p.Path, p.Downloaded, p.Total)
+					},
+				})
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(snapshot)
+			}
+
+			return nil
+		},
+	}
+
+	uploadCmd := &cobra.Command{
+		Use:   "upload [repository] [local path] [path in repository]",
+		Short: "Upload a local file or folder to a model repository in a single commit",
+		Long: `Upload a local file or folder (default the current directory) to a model repository in a single commit
+and print the commit, whose id can be deployed with endpoint create --revision.
+
+The file is uploaded to its name and the folder to the root of the repository unless a path in the repository
+is given. The hub decides which files are stored with lfs, those are uploaded to the lfs storage first, in
+parts for large files, and files already in the storage are not sent again.`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := newHubClient()
+			if err != nil {
+				return err
+			}
+
+			repository, localPath, pathInRepo := args[0], ".", ""
+			if len(args) > 1 {
+				localPath = args[1]
+			}
+			if len(args) > 2 {
+				pathInRepo = args[2]
+			}
+
+			info, err := os.Stat(localPath)
+			if err != nil {
+				return err
+			}
+
+			var operations []hub.CommitOperation
+			if info.IsDir() {
+				operations, err = hub.FolderOperations(localPath, pathInRepo, hubExclude)
+				if err != nil {
+					return err
+				}
+			} else {
+				if pathInRepo == "" {
+					pathInRepo = filepath.Base(localPath)
+				}
+				operations = []hub.CommitOperation{{Path: pathInRepo, LocalPath: localPath}}
+			}
+			for _, path := range hubDelete {
+				operations = append(operations, hub.CommitOperation{Path: path, Delete: true})
+			}
+
+			if hubCreate {
+				if _, err := h.CreateRepo(repository, hub.CreateRepoOptions{Private: hubPrivate, ExistOK: true}); err != nil {
+					return err
+				}
+			}
+
+			message := hubMessage
+			if message == "" {
+				message = fmt.Sprintf("Upload %s", filepath.Base(filepath.Clean(localPath)))
+			}
+
+			ctx, stop := interruptible("files already in the lfs storage are not sent again")
+			defer stop()
+
+			progress := printProgress()
+			commit, err := h.CreateCommit(ctx, repository, operations, hub.CommitOptions{
+				Revision:    hubRevision,
+				Message:     message,
+				Description: hubDescription,
+				Workers:     hubWorkers,
+				Progress: func(p hub.UploadProgress) {
+					progress(p.Path, p.Uploaded, p.Total)
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			printJSON(commit)
+
+			return nil
+		},
+	}
+
+	infoCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch, tag or commit (default main)")
+	filesCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch, tag or commit (default main)")
+	filesCmd.Flags().StringVar(&hubPath, "path", "", "Directory to list (default the repository root)")
+	commitsCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch, tag or commit (default main)")
+	commitsCmd.Flags().IntVar(&hubLimit, "limit", 20, "Maximum number of commits, 0 for all")
+	resolveCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch, tag or commit (default main)")
+	downloadCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch, tag or commit (default main)")
+	downloadCmd.Flags().StringVar(&hubCacheDir, "cache-dir", "", "Hub cache directory (default $HF_HUB_CACHE, $HF_HOME/hub or ~/.cache/huggingface/hub)")
+	downloadCmd.Flags().IntVar(&hubChunks, "chunks", hub.DefaultDownloadChunks, "Ranges of a large file downloaded in parallel")
+	uploadCmd.Flags().StringVar(&hubRevision, "revision", "", "Branch the commit is pushed to (default main)")
+	uploadCmd.Flags().StringVar(&hubMessage, "message", "", "Commit summary (default Upload <local path>)")
+	uploadCmd.Flags().StringVar(&hubDescription, "description", "", "Commit description")
+	uploadCmd.Flags().BoolVar(&hubCreate, "create", true, "Create the repository if it does not exist")
+	uploadCmd.Flags().BoolVar(&hubPrivate, "private", false, "Create the repository as private")
+	uploadCmd.Flags().StringSliceVar(&hubExclude, "exclude", nil, "Patterns of the folder files not to upload, e.g. *.log,checkpoint-*/*")
+	uploadCmd.Flags().StringSliceVar(&hubDelete, "delete", nil, "Paths of the repository deleted in the same commit")
+	uploadCmd.Flags().IntVar(&hubWorkers, "workers", hub.DefaultUploadWorkers, "Lfs files uploaded in parallel")
+	downloadCmd.Flags().BoolVar(&hubForce, "force", false, "Download the files again even if they are in the cache")
+
+	hubCmd.AddCommand(infoCmd)
+	hubCmd.AddCommand(filesCmd)
+	hubCmd.AddCommand(refsCmd)
+	hubCmd.AddCommand(commitsCmd)
+	hubCmd.AddCommand(resolveCmd)
+	hubCmd.AddCommand(downloadCmd)
+	hubCmd.AddCommand(uploadCmd)
+
+	rootCmd.AddCommand(hubCmd)
+}
+
+func newHubClient() (*hub.Client, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return hub.New(c), nil
+}
+
+// preflightModel - PreflightError of the model on the hub, an unreachable hub being reported on stderr
+func preflightModel(c *client.Client, model client.EndpointModel) error {
+	if skipPreflight {
+		return nil
+	}
+
+	err := hub.New(c).Preflight(context.Background(), model)
+	var preflight *hub.PreflightError
+	if errors.As(err, &preflight) {
+		return fmt.Errorf("%w (or --skip-preflight)", err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not check the model on the hub: %v\n", err)
+	}
+
+	return nil
+}
+
+// modelRevision - revision to deploy repository at, the commit sha revision points to
+// when --pin-revision is set, nil when neither --revision nor --pin-revision is set
+func modelRevision(c *client.Client, repository, revision string, pin bool) (*string, error) {
+	if !pin {
+		if revision == "" {
+			return nil, nil
+		}
+		return &revision, nil
+	}
+
+	sha, err := hub.New(c).ResolveRevision(repository, revision)
+	if err != nil {
+		return nil, fmt.Errorf("could not pin the revision: %w", err)
+	}
+
+	return &sha, nil
+}
+
+// printProgress - progress callback of a transfer printing a line on stderr, refreshed at most every 200ms
+func printProgress() func(path string, done, total int64) {
+	var printedAt time.Time
+	return func(path string, done, total int64) {
+		complete := total > 0 && done >= total
+		if !complete && time.Since(printedAt) < 200*time.Millisecond {
+			return
+		}
+		printedAt = time.Now()
+
+		line := fmt.Sprintf("%s %s", path, formatBytes(done))
+		if total > 0 {
+			line += fmt.Sprintf(" / %s (%d%%)", formatBytes(total), done*100/total)
+		}
+		fmt.Fprintf(os.Stderr, "\r%-80s", line)
+		if complete {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value, exponent := float64(n)/unit, 0
+	for value >= unit && exponent < 3 {
+		value /= unit
+		exponent++
+	}
+
+	return fmt.Sprintf("%.1f %cB", value, "kMGT"[exponent])
+}
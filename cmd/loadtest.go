@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadtestRoute         string
+	loadtestPayload       string
+	loadtestPayloadFile   string
+	loadtestInputsFile    string
+	loadtestDuration      time.Duration
+	loadtestRate          float64
+	loadtestConcurrency   int
+	loadtestMaxRequests   int
+	loadtestMetrics       []string
+	loadtestMetricsMargin time.Duration
+	loadtestMetricsWait   time.Duration
+)
+
+func init() {
+	loadtestCmd := &cobra.Command{
+		Use:   "loadtest [name]",
+		Short: "Load test an endpoint and correlate the run with its metrics",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, metric := range loadtestMetrics {
+				if !utils.IsMetricValid(metric) {
+					return fmt.Errorf("invalid metric: %s", metric)
+				}
+			}
+
+			templateText := loadtestPayload
+			if loadtestPayloadFile != "" {
+				content, err := os.ReadFile(loadtestPayloadFile)
+				if err != nil {
+					return err
+				}
+				templateText = string(content)
+			}
+			if templateText == "" {
+				return errors.New("one of --payload or --payload-file must be specified")
+			}
+
+			var lines []string
+			if loadtestInputsFile != "" {
+				content, err := os.ReadFile(loadtestInputsFile)
+				if err != nil {
+					return err
+				}
+				for _, line := range strings.Split(string(content), "\n") {
+					if strings.TrimSpace(line) != "" {
+						lines = append(lines, line)
+					}
+				}
+			}
+
+			payload, err := client.NewPayloadTemplate(templateText, lines)
+			if err != nil {
+				return fmt.Errorf("invalid payload template: %w", err)
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := c.GetEndpoint(namespace, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+
+			inference, err := c.NewInferenceClient(*endpoint)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			fmt.Fprintf(os.Stderr, "Load testing %s for %s...\n", *endpoint.Status.URL, loadtestDuration)
+
+			report, err := inference.RunLoadTest(ctx, client.LoadTestOptions{
+				Route:       loadtestRoute,
+				Payload:     payload,
+				Duration:    loadtestDuration,
+				Rate:        loadtestRate,
+				Concurrency: loadtestConcurrency,
+				MaxRequests: loadtestMaxRequests,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(loadtestMetrics) > 0 {
+				if loadtestMetricsWait > 0 {
+					fmt.Fprintf(os.Stderr, "Waiting %s for metrics to be collected...\n", loadtestMetricsWait)
+					time.Sleep(loadtestMetricsWait)
+				}
+				if err := c.CorrelateMetrics(report, namespace, args[0], loadtestMetrics, loadtestMetricsMargin); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}
+
+			printJSON(report)
+
+			return nil
+		},
+	}
+
+	loadtestCmd.Flags().StringVar(&loadtestRoute, "route", "", "Endpoint route receiving the requests (e.g. /generate)")
+	loadtestCmd.Flags().StringVar(&loadtestPayload, "payload", "", "Payload template, e.g. '{\"inputs\": {{quote .Line}}}'")
+	loadtestCmd.Flags().StringVar(&loadtestPayloadFile, "payload-file", "", "File holding the payload template")
+	loadtestCmd.Flags().StringVar(&loadtestInputsFile, "inputs-file", "", "File whose lines are cycled through as .Line in the template")
+	loadtestCmd.Flags().DurationVar(&loadtestDuration, "duration", client.DefaultLoadTestDuration, "Duration of the run")
+	loadtestCmd.Flags().Float64Var(&loadtestRate, "rate", 0, "Requests per second (open loop), 0 to send back to back with --concurrency workers")
+	loadtestCmd.Flags().IntVar(&loadtestConcurrency, "concurrency", client.DefaultLoadTestConcurrency, "Number of workers, or maximum requests in flight with --rate")
+	loadtestCmd.Flags().IntVar(&loadtestMaxRequests, "max-requests", 0, "Stop after this many requests (0 for no limit)")
+	loadtestCmd.Flags().StringSliceVar(&loadtestMetrics, "metrics", []string{"pending-requests", "running-replicas"}, "Endpoint metrics summarized over the run window")
+	loadtestCmd.Flags().DurationVar(&loadtestMetricsMargin, "metrics-margin", time.Minute, "Margin added around the run window when fetching metrics")
+	loadtestCmd.Flags().DurationVar(&loadtestMetricsWait, "metrics-wait", 0, "Delay before fetching metrics, to let them be collected")
+
+	endpointCmd.AddCommand(loadtestCmd)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/exporter"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportMetrics []string
+	exportStart   string
+	exportStop    string
+	exportStep    time.Duration
+	exportChunk   time.Duration
+	exportFormat  string
+	exportOut     string
+)
+
+func init() {
+	metricExportCmd := &cobra.Command{
+		Use:   "metric-export [name]",
+		Short: "Export endpoint metrics over a long range to a csv, jsonl, json or openmetrics file",
+		Long: `Export endpoint metrics over a long range to a single file.
+
+Ranges longer than the api accepts are split in chunks. The csv, jsonl and json (columnar) formats
+align all the series on a shared time axis truncated to --step, missing points being left empty.
+The openmetrics format holds the raw points of each series with their timestamps.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, metric := range exportMetrics {
+				if !utils.IsMetricValid(metric) {
+					return fmt.Errorf("invalid metric: %s", metric)
+				}
+			}
+
+			switch exportFormat {
+			case "csv", "jsonl", "json", "openmetrics":
+			default:
+				return fmt.Errorf("invalid format %s, must be one of csv, jsonl, json, openmetrics", exportFormat)
+			}
+
+			start, err := utils.ParseTime(exportStart)
+			if err != nil {
+				return fmt.Errorf("invalid --start time format : %w", err)
+			}
+
+			stop, err := utils.ParseTime(exportStop)
+			if err != nil {
+				return fmt.Errorf("invalid --stop time format : %w", err)
+			}
+
+			if !start.Before(stop) {
+				return fmt.Errorf("--start must be before --stop")
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			series := map[string][]client.MetricSeries{}
+			for _, metric := range exportMetrics {
+				fmt.Fprintf(os.Stderr, "Fetching %s...\n", metric)
+				series[metric], err = c.GetEndpointMetricRange(namespace, args[0], metric, start, stop, exportStep, exportChunk)
+				if err != nil {
+					return err
+				}
+			}
+
+			out := os.Stdout
+			if exportOut != "" {
+				out, err = os.Create(exportOut)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+			}
+
+			if exportFormat == "openmetrics" {
+				return writeOpenMetricsExport(out, namespace, args[0], exportMetrics, series)
+			}
+
+			var columns []client.MetricColumn
+			for _, metric := range exportMetrics {
+				columns = append(columns, client.MetricColumns(metric, series[metric])...)
+			}
+			table := client.AlignMetricSeries(columns, exportStep)
+
+			switch exportFormat {
+			case "csv":
+				err = writeCSVExport(out, table)
+			case "jsonl":
+				err = writeJSONLExport(out, table)
+			case "json":
+				err = writeColumnarExport(out, table)
+			}
+			if err != nil {
+				return err
+			}
+
+			if exportOut != "" {
+				fmt.Fprintf(os.Stderr, "Exported %d rows of %d series to %s\n", len(table.Times), len(table.Columns), exportOut)
+			}
+
+			return nil
+		},
+	}
+
+	metricExportCmd.Flags().StringSliceVar(&exportMetrics, "metrics", nil, "Metrics to export, e.g. cpu-usage,p95-latency (required)")
+	metricExportCmd.Flags().StringVar(&exportStart, "start", "-24h", "Export start, a time or a duration relative to now ( '-168h' )")
+	metricExportCmd.Flags().StringVar(&exportStop, "stop", "now", "Export stop, a time or a duration relative to now")
+	metricExportCmd.Flags().DurationVar(&exportStep, "step", time.Minute, "Resolution of the exported series")
+	metricExportCmd.Flags().DurationVar(&exportChunk, "chunk", 0, "Range of each api query (defaults to 1000 steps)")
+	metricExportCmd.Flags().StringVar(&exportFormat, "format", "csv", "Output format: csv, jsonl, json (columnar) or openmetrics")
+	metricExportCmd.Flags().StringVar(&exportOut, "out", "", "Output file (defaults to stdout)")
+
+	metricExportCmd.MarkFlagRequired("metrics")
+
+	endpointCmd.AddCommand(metricExportCmd)
+}
+
+// writeCSVExport - one row per timestamp, one column per series
+func writeCSVExport(w io.Writer, table *client.MetricTable) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(append([]string{"time"}, table.Columns...)); err != nil {
+		return err
+	}
+
+	for i, t := range table.Times {
+		record := []string{t.Format(time.RFC3339)}
+		for _, value := range table.Values[i] {
+			cell := ""
+			if value != nil {
+				cell = strconv.FormatFloat(*value, 'g', -1, 64)
+			}
+			record = append(record, cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// writeJSONLExport - one {"time": ..., "<series>": value} object per timestamp,
+// keys written in column order
+func writeJSONLExport(w io.Writer, table *client.MetricTable) error {
+	bw := bufio.NewWriter(w)
+
+	names := make([][]byte, len(table.Columns))
+	for i, column := range table.Columns {
+		names[i], _ = json.Marshal(column)
+	}
+
+	for i, t := range table.Times {
+		fmt.Fprintf(bw, `{"time":%q`, t.Format(time.RFC3339))
+		for j, value := range table.Values[i] {
+			bw.WriteByte(',')
+			bw.Write(names[j])
+			bw.WriteByte(':')
+			if value == nil {
+				bw.WriteString("null")
+			} else {
+				bw.WriteString(strconv.FormatFloat(*value, 'g', -1, 64))
+			}
+		}
+		bw.WriteString("}\n")
+	}
+
+	return bw.Flush()
+}
+
+type columnarExport struct {
+	Time   []time.Time      `json:"time"`
+	Series []columnarSeries `json:"series"`
+}
+
+type columnarSeries struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+// writeColumnarExport - a single json document with the time axis and one values array per series
+func writeColumnarExport(w io.Writer, table *client.MetricTable) error {
+	export := columnarExport{Time: table.Times, Series: make([]columnarSeries, len(table.Columns))}
+	for j, column := range table.Columns {
+		export.Series[j] = columnarSeries{Name: column, Values: make([]*float64, len(table.Times))}
+		for i := range table.Times {
+			export.Series[j].Values[i] = table.Values[i][j]
+		}
+	}
+
+	return json.NewEncoder(w).Encode(export)
+}
+
+// writeOpenMetricsExport - one gauge family per metric, raw points with their timestamps
+func writeOpenMetricsExport(w io.Writer, namespace, name string, metrics []string, series map[string][]client.MetricSeries) error {
+	labels := []exporter.Label{{Name: "namespace", Value: namespace}, {Name: "endpoint", Value: name}}
+
+	var families []exporter.Family
+	for _, metric := range metrics {
+		family := exporter.Family{
+			Name: exporter.MetricName(metric),
+			Help: "Endpoint metric " + metric + ".",
+			Type: exporter.TypeGauge,
+		}
+		for _, s := range series[metric] {
+			seriesLabels := exporter.SeriesLabels(labels, s.Labels)
+			for _, point := range s.Points {
+				family.Samples = append(family.Samples, exporter.Sample{Labels: seriesLabels, Value: point.Value, Timestamp: point.Time})
+			}
+		}
+		families = append(families, family)
+	}
+
+	return exporter.WriteOpenMetrics(w, families)
+}
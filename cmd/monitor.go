@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/monitor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorRules     string
+	monitorNamespace string
+	monitorInterval  time.Duration
+	monitorOnce      bool
+)
+
+func init() {
+	monitorCmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Evaluate alert rules against the endpoints of a namespace",
+		Long: `Evaluate the alert rules of a json file periodically against the endpoints of a namespace.
+
+Rules compare a metric or a status field with a value, e.g. "server-error-rate > 0.05" for 5m,
+"state == failed" or "readyReplica < targetReplica" for 10m. Alerts go through the pending, firing
+and resolved states and are delivered to stdout, a log file or a webhook with templated payloads.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := monitor.LoadConfig(monitorRules)
+			if err != nil {
+				return err
+			}
+			if monitorNamespace != "" {
+				config.Namespace = monitorNamespace
+			}
+			if monitorInterval > 0 {
+				config.Interval = monitor.Duration(monitorInterval)
+			}
+			if err := config.Validate(); err != nil {
+				return err
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			m, err := monitor.New(c, config)
+			if err != nil {
+				return err
+			}
+
+			if monitorOnce {
+				if _, err := m.Evaluate(context.Background()); err != nil {
+					return err
+				}
+				printJSON(m.Alerts())
+				return nil
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			fmt.Fprintf(os.Stderr, "Monitoring %d rules on namespace %s...\n", len(config.Rules), config.Namespace)
+			m.Run(ctx, func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			})
+
+			return nil
+		},
+	}
+
+	monitorCmd.Flags().StringVar(&monitorRules, "rules", "", "Json file of the alert rules and notifiers (required)")
+	monitorCmd.Flags().StringVar(&monitorNamespace, "namespace", "", "Namespace to monitor, overrides the rules file one")
+	monitorCmd.Flags().DurationVar(&monitorInterval, "interval", 0, "Evaluation interval, overrides the rules file one (default 1m)")
+	monitorCmd.Flags().BoolVar(&monitorOnce, "once", false, "Evaluate the rules once and print the pending and firing alerts")
+
+	monitorCmd.MarkFlagRequired("rules")
+
+	rootCmd.AddCommand(monitorCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/monitor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reaperPolicies  string
+	reaperNamespace string
+	reaperInterval  time.Duration
+	reaperDryRun    bool
+	reaperOnce      bool
+)
+
+func init() {
+	reaperCmd := &cobra.Command{
+		Use:   "reaper",
+		Short: "Pause the endpoints of a namespace idle for longer than their policy",
+		Long: `Pause the endpoints of a namespace which received no request for longer than their idle policy.
+
+Activity is read from the request-count and pending-requests metrics over the lookback window.
+Policies apply per tag, the first policy matching an endpoint wins, and allowlisted endpoints are
+never paused. Unlike scale to zero, pausing works for every endpoint type and hardware.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := monitor.LoadReaperConfig(reaperPolicies)
+			if err != nil {
+				return err
+			}
+			if reaperNamespace != "" {
+				config.Namespace = reaperNamespace
+			}
+			if reaperInterval > 0 {
+				config.Interval = monitor.Duration(reaperInterval)
+			}
+			if err := config.Validate(); err != nil {
+				return err
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			reaper := monitor.NewReaper(c, config)
+			reaper.DryRun = reaperDryRun
+
+			if reaperOnce {
+				reports, err := reaper.Reap(context.Background())
+				if err != nil {
+					return err
+				}
+				printJSON(reports)
+				return nil
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			fmt.Fprintf(os.Stderr, "Reaping idle endpoints of namespace %s...\n", config.Namespace)
+			reaper.Run(ctx, func(report monitor.IdleReport) {
+				printJSON(report)
+			}, func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			})
+
+			return nil
+		},
+	}
+
+	reaperCmd.Flags().StringVar(&reaperPolicies, "policies", "", "Json file of the idle policies and allowlist (required)")
+	reaperCmd.Flags().StringVar(&reaperNamespace, "namespace", "", "Namespace to reap, overrides the policy file one")
+	reaperCmd.Flags().DurationVar(&reaperInterval, "interval", 0, "Inspection interval, overrides the policy file one (default 10m)")
+	reaperCmd.Flags().BoolVar(&reaperDryRun, "dry-run", false, "Report the idle endpoints without pausing them")
+	reaperCmd.Flags().BoolVar(&reaperOnce, "once", false, "Inspect the endpoints once and print the report of all of them")
+
+	reaperCmd.MarkFlagRequired("policies")
+
+	rootCmd.AddCommand(reaperCmd)
+}
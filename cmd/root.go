@@ -1,21 +1,104 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
+	"github.com/sebps/huggingface-client/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	host  string
+	token string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "huggingface-cli",
 	Short: "CLI to manage Hugging Face endpoints",
 	Long:  `A Cobra CLI to manage Hugging Face hosted inference endpoints.`,
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "Authorization Bearer token (defaults to $HF_TOKEN, then to the token stored by `auth login`)")
+	rootCmd.PersistentFlags().StringVar(&host, "host", "", "API host URL (optional)")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exit *exitCodeError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// exitCodeError ends the program with its exit code once the command has returned,
+// for commands whose outcome is reported through the exit status
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// exitCode - error exiting with code, the command printing its own output
+func exitCode(cmd *cobra.Command, code int) error {
+	if code == 0 {
+		return nil
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	return &exitCodeError{code: code}
+}
+
+// newClient - build a client from the --token flag, falling back to $HF_TOKEN
+// and then to the token stored by `auth login`
+func newClient() (*client.Client, error) {
+	if token != "" {
+		return client.NewClient(&host, &token)
+	}
+
+	if os.Getenv(client.TokenEnvVar) != "" {
+		return client.NewClientWithTokenProvider(&host, client.NewEnvTokenProvider(client.TokenEnvVar))
+	}
+
+	path, err := client.TokenPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, errors.New("no token provided: use --token, set $HF_TOKEN or run `auth login`")
+	}
+
+	return client.NewClientWithTokenProvider(&host, client.NewFileTokenProvider(path))
+}
+
+// interruptible - context canceled on ctrl-c, printing hint when not empty
+func interruptible(hint string) (context.Context, context.CancelFunc) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+
+	if hint != "" {
+		go func() {
+			<-ctx.Done()
+			// The parent is only canceled when the command stops on its own
+			if parent.Err() == nil {
+				fmt.Fprintf(os.Stderr, "\nInterrupted, %s.\n", hint)
+			}
+		}()
+	}
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
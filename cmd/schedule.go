@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sebps/huggingface-client/schedule"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleConfig    string
+	scheduleNamespace string
+	scheduleState     string
+	scheduleDryRun    bool
+	scheduleOnce      bool
+	scheduleTick      time.Duration
+	scheduleFor       time.Duration
+	scheduleResolve   bool
+)
+
+func init() {
+	scheduleCmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Pause, resume and scale endpoints on cron schedules",
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the rules of a schedule file when they are due",
+		Long: `Run the rules of a json schedule file when they are due.
+
+Rules select endpoints by name or tag and pause, resume, scale them to zero or update their
+min / max replicas at the times of a cron expression, e.g. "0 20 * * mon-fri". Cron expressions
+are evaluated in the schedule time zone and rules do not run on holidays unless they opt in.
+The time of the last run of each rule is saved to the state file, occurrences missed while
+the scheduler was stopped are caught up on the next start.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := newScheduler()
+			if err != nil {
+				return err
+			}
+			s.DryRun = scheduleDryRun
+
+			if scheduleOnce {
+				results, err := s.RunDue(context.Background())
+				printJSON(results)
+				return err
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			fmt.Fprintf(os.Stderr, "Scheduling %d rules on namespace %s...\n", len(s.Config.Rules), s.Config.Namespace)
+			s.Run(ctx, scheduleTick, func(result schedule.Result) {
+				printJSON(result)
+			}, func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			})
+
+			return nil
+		},
+	}
+
+	previewCmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Print the upcoming actions of a schedule file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := newScheduler()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			occurrences := s.Upcoming(now, now.Add(scheduleFor))
+			if scheduleResolve && len(occurrences) > 0 {
+				if err := s.Resolve(occurrences); err != nil {
+					return err
+				}
+			}
+
+			printJSON(occurrences)
+
+			return nil
+		},
+	}
+
+	for _, c := range []*cobra.Command{runCmd, previewCmd} {
+		c.Flags().StringVar(&scheduleConfig, "config", "", "Json file of the schedule rules (required)")
+		c.Flags().StringVar(&scheduleNamespace, "namespace", "", "Namespace of the endpoints, overrides the schedule file one")
+		c.MarkFlagRequired("config")
+	}
+
+	runCmd.Flags().StringVar(&scheduleState, "state", "", "State file of the last runs (default <config>.state.json)")
+	runCmd.Flags().BoolVar(&scheduleDryRun, "dry-run", false, "Print the due actions without running them nor saving the state")
+	runCmd.Flags().BoolVar(&scheduleOnce, "once", false, "Run the due rules once and exit")
+	runCmd.Flags().DurationVar(&scheduleTick, "tick", schedule.DefaultTick, "Interval between two checks of the due rules")
+
+	previewCmd.Flags().DurationVar(&scheduleFor, "for", 7*24*time.Hour, "Period of the preview")
+	previewCmd.Flags().BoolVar(&scheduleResolve, "resolve", true, "Resolve the endpoints selected by each rule")
+
+	scheduleCmd.AddCommand(runCmd, previewCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+// newScheduler - scheduler of the schedule file and state flags
+func newScheduler() (*schedule.Scheduler, error) {
+	config, err := schedule.LoadConfig(scheduleConfig)
+	if err != nil {
+		return nil, err
+	}
+	if scheduleNamespace != "" {
+		config.Namespace = scheduleNamespace
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	statePath := scheduleState
+	if statePath == "" {
+		statePath = scheduleConfig + ".state.json"
+	}
+
+	return schedule.New(c, config, statePath)
+}
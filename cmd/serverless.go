@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serverlessProvider string
+	serverlessPrompt   string
+	serverlessNegative string
+	serverlessWidth    int
+	serverlessHeight   int
+	serverlessSteps    int
+	serverlessSeed     int64
+	serverlessOut      string
+	serverlessInput    []string
+)
+
+var serverlessCmd = &cobra.Command{
+	Use:   "serverless",
+	Short: "Call models through the serverless inference providers router",
+}
+
+// newRouterClient - router client of --provider
+func newRouterClient() (*client.RouterClient, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewRouterClient(client.InferenceProvider(serverlessProvider)), nil
+}
+
+func init() {
+	providersCmd := &cobra.Command{
+		Use:   "providers [model]",
+		Short: "List the providers serving a model with their model id, status and task",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			router, err := newRouterClient()
+			if err != nil {
+				return err
+			}
+
+			mappings, err := router.GetProviderMappings(args[0])
+			if err != nil {
+				return err
+			}
+
+			printJSON(mappings)
+
+			return nil
+		},
+	}
+
+	chatCmd := &cobra.Command{
+		Use:   "chat [model]",
+		Short: "Interactive chat with a model served by a provider (type /help for commands)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			router, err := newRouterClient()
+			if err != nil {
+				return err
+			}
+
+			mapping, err := router.SelectProvider(args[0], client.RouterTaskConversational)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Chatting with %s through %s.\n", mapping.ProviderID, mapping.Provider)
+
+			transcript := &chatTranscript{Endpoint: args[0]}
+			if chatSystem != "" {
+				transcript.Messages = append(transcript.Messages, client.ChatMessage{Role: client.ChatRoleSystem, Content: chatSystem})
+			}
+
+			request := client.ChatCompletionRequest{Model: args[0]}
+			if cmd.Flags().Changed("max-tokens") {
+				request.MaxTokens = &chatMaxTokens
+			}
+			if cmd.Flags().Changed("temperature") {
+				request.Temperature = &chatTemperature
+			}
+
+			runChatREPL(router, request, transcript, os.Stdin)
+
+			if chatSave != "" {
+				if err := saveTranscript(chatSave, transcript); err != nil {
+					return err
+				}
+				fmt.Printf("Transcript saved to %s.\n", chatSave)
+			}
+
+			return nil
+		},
+	}
+
+	embedCmd := &cobra.Command{
+		Use:   "embed [model]",
+		Short: "Embed texts with a model served by a provider, writing one jsonl line per input",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			router, err := newRouterClient()
+			if err != nil {
+				return err
+			}
+
+			inputs := serverlessInput
+			if len(inputs) == 0 {
+				scanner := bufio.NewScanner(os.Stdin)
+				scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+				for scanner.Scan() {
+					inputs = append(inputs, scanner.Text())
+				}
+				if err := scanner.Err(); err != nil {
+					return err
+				}
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			vectors, err := router.WithContext(ctx).FeatureExtraction(args[0], client.FeatureExtractionRequest{Inputs: inputs})
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			for i, vector := range vectors {
+				if err := encoder.Encode(embeddingLine{Index: i, Input: inputs[i], Embedding: vector}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	imageCmd := &cobra.Command{
+		Use:   "image [model]",
+		Short: "Generate an image from --prompt with a model served by a provider",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			router, err := newRouterClient()
+			if err != nil {
+				return err
+			}
+
+			parameters := &client.TextToImageParameters{NegativePrompt: serverlessNegative}
+			if cmd.Flags().Changed("width") {
+				parameters.Width = &serverlessWidth
+			}
+			if cmd.Flags().Changed("height") {
+				parameters.Height = &serverlessHeight
+			}
+			if cmd.Flags().Changed("steps") {
+				parameters.NumInferenceSteps = &serverlessSteps
+			}
+			if cmd.Flags().Changed("seed") {
+				parameters.Seed = &serverlessSeed
+			}
+
+			ctx, cancel := interruptible("")
+			defer cancel()
+
+			image, err := router.WithContext(ctx).TextToImage(args[0], client.TextToImageRequest{Inputs: serverlessPrompt, Parameters: parameters})
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(serverlessOut, image, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Image written to %s (%s).\n", serverlessOut, formatBytes(int64(len(image))))
+
+			return nil
+		},
+	}
+
+	serverlessCmd.PersistentFlags().StringVar(&serverlessProvider, "provider", string(client.InferenceProviderAuto), "Provider serving the model, auto picks the first live one")
+
+	chatCmd.Flags().StringVar(&chatSystem, "system", "", "System prompt")
+	chatCmd.Flags().BoolVar(&chatStream, "stream", true, "Stream the assistant answers")
+	chatCmd.Flags().IntVar(&chatMaxTokens, "max-tokens", 0, "Maximum number of tokens per answer")
+	chatCmd.Flags().Float64Var(&chatTemperature, "temperature", 0, "Sampling temperature")
+	chatCmd.Flags().StringVar(&chatSave, "save", "", "Transcript file written when the session ends")
+
+	embedCmd.Flags().StringArrayVar(&serverlessInput, "input", nil, "Text to embed, repeatable (default: one text per line of stdin)")
+
+	imageCmd.Flags().StringVar(&serverlessPrompt, "prompt", "", "Prompt of the image")
+	imageCmd.Flags().StringVar(&serverlessNegative, "negative-prompt", "", "What the image must not show")
+	imageCmd.Flags().IntVar(&serverlessWidth, "width", 0, "Width of the image in pixels")
+	imageCmd.Flags().IntVar(&serverlessHeight, "height", 0, "Height of the image in pixels")
+	imageCmd.Flags().IntVar(&serverlessSteps, "steps", 0, "Number of denoising steps")
+	imageCmd.Flags().Int64Var(&serverlessSeed, "seed", 0, "Seed of the generation")
+	imageCmd.Flags().StringVar(&serverlessOut, "out", "image.png", "File the image is written to")
+	imageCmd.MarkFlagRequired("prompt")
+
+	serverlessCmd.AddCommand(providersCmd, chatCmd, embedCmd, imageCmd)
+	rootCmd.AddCommand(serverlessCmd)
+}
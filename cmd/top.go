@@ -0,0 +1,690 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+	"github.com/sebps/huggingface-client/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval    time.Duration
+	topConcurrency int
+)
+
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyEnter = "enter"
+	keyEsc   = "esc"
+)
+
+const (
+	viewTable   = "table"
+	viewDetails = "details"
+	viewLogs    = "logs"
+)
+
+// Metrics of the dashboard, fetched over the last minutes for running endpoints
+var topMetrics = []string{"pending-requests", "p95-latency", "server-error-rate"}
+
+func init() {
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "Live dashboard of the endpoints of a namespace",
+		Long: `Live dashboard of the endpoints of a namespace with their state, replicas, instance,
+pending requests, p95 latency and server error rate, refreshed every --interval.
+
+Keys: up/down (or k/j) select, s / 1-7 sort, r reverse, p pause, u resume, z scale to zero,
+l logs, enter details, space refresh, q quit.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !utils.IsTerminal(os.Stdin) || !utils.IsTerminal(os.Stdout) {
+				return fmt.Errorf("top needs an interactive terminal")
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			restore, err := utils.MakeRaw()
+			if err != nil {
+				return err
+			}
+			defer restore()
+
+			// Hide the cursor while drawing, clear the screen on exit
+			fmt.Print("\033[?25l")
+			defer fmt.Print("\033[H\033[2J\033[?25h")
+
+			return newTopDashboard(c, namespace).run(os.Stdin)
+		},
+	}
+
+	topCmd.Flags().DurationVar(&topInterval, "interval", 15*time.Second, "Refresh interval")
+	topCmd.Flags().IntVar(&topConcurrency, "concurrency", 4, "Number of metric queries run in parallel")
+
+	endpointCmd.AddCommand(topCmd)
+}
+
+type topRow struct {
+	endpoint client.EndpointWithStatus
+	// Last values of the dashboard metrics, NaN when unknown
+	pending   float64
+	p95       float64
+	errorRate float64
+}
+
+type topColumn struct {
+	title string
+	width int
+	value func(row topRow) string
+	less  func(a, b topRow) bool
+}
+
+var topColumns = []topColumn{
+	{"NAME", 32,
+		func(r topRow) string { return r.endpoint.Name },
+		func(a, b topRow) bool { return a.endpoint.Name < b.endpoint.Name }},
+	{"STATE", 14,
+		func(r topRow) string { return string(r.endpoint.Status.State) },
+		func(a, b topRow) bool { return a.endpoint.Status.State < b.endpoint.Status.State }},
+	{"READY", 7,
+		func(r topRow) string {
+			return fmt.Sprintf("%d/%d", r.endpoint.Status.ReadyReplica, r.endpoint.Status.TargetReplica)
+		},
+		func(a, b topRow) bool { return a.endpoint.Status.ReadyReplica < b.endpoint.Status.ReadyReplica }},
+	{"INSTANCE", 28,
+		func(r topRow) string { return topInstance(r.endpoint) },
+		func(a, b topRow) bool { return topInstance(a.endpoint) < topInstance(b.endpoint) }},
+	{"PENDING", 9,
+		func(r topRow) string { return formatTopValue(r.pending) },
+		func(a, b topRow) bool { return lessTopValue(a.pending, b.pending) }},
+	{"P95 LAT", 9,
+		func(r topRow) string { return formatTopValue(r.p95) },
+		func(a, b topRow) bool { return lessTopValue(a.p95, b.p95) }},
+	{"ERR RATE", 9,
+		func(r topRow) string { return formatTopValue(r.errorRate) },
+		func(a, b topRow) bool { return lessTopValue(a.errorRate, b.errorRate) }},
+}
+
+func topInstance(endpoint client.EndpointWithStatus) string {
+	return fmt.Sprintf("%s/%s %s", endpoint.Provider.Vendor, endpoint.Provider.Region, endpoint.Compute.InstanceType)
+}
+
+func formatTopValue(value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+	return fmt.Sprintf("%.4g", value)
+}
+
+// lessTopValue - unknown values sort last
+func lessTopValue(a, b float64) bool {
+	if math.IsNaN(a) {
+		return false
+	}
+	if math.IsNaN(b) {
+		return true
+	}
+	return a < b
+}
+
+// topAction is an endpoint action waiting for confirmation
+type topAction struct {
+	verb string
+	name string
+	run  func(namespace, name string) error
+}
+
+type topRefresh struct {
+	rows []topRow
+	err  error
+}
+
+// Lines kept by the logs view
+const topMaxLogLines = 5000
+
+type topDashboard struct {
+	client    *client.Client
+	namespace string
+
+	rows       []topRow
+	selected   string
+	sortColumn int
+	descending bool
+
+	view      string
+	viewTitle string
+	viewLines []string
+	offset    int
+
+	// Log stream of the logs view, and its generation to ignore the lines of closed streams
+	logs      io.Closer
+	logsGen   int
+	following bool
+
+	// Changes of the background calls, applied by the main loop
+	updates chan func()
+
+	confirm      *topAction
+	message      string
+	refreshed    time.Time
+	refreshing   bool
+	needsRefresh bool
+	err          error
+}
+
+func newTopDashboard(c *client.Client, namespace string) *topDashboard {
+	return &topDashboard{client: c, namespace: namespace, view: viewTable, needsRefresh: true, updates: make(chan func(), 64)}
+}
+
+// run - main loop, redrawing after each key, refresh and tick until q or ctrl-c
+func (d *topDashboard) run(input io.Reader) error {
+	keys := readKeys(input)
+	results := make(chan topRefresh, 1)
+
+	ctx, cancel := interruptible("")
+	defer cancel()
+
+	ticker := time.NewTicker(topInterval)
+	defer ticker.Stop()
+	defer d.closeLogs()
+
+	for {
+		if d.needsRefresh && !d.refreshing {
+			d.needsRefresh = false
+			d.refreshing = true
+			go func() {
+				rows, err := fetchTopRows(d.client, d.namespace)
+				results <- topRefresh{rows: rows, err: err}
+			}()
+		}
+
+		d.draw(os.Stdout)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil
+			}
+		case result := <-results:
+			d.refreshing = false
+			d.err = result.err
+			if result.err == nil {
+				d.rows = result.rows
+				d.refreshed = time.Now()
+				d.sortRows()
+			}
+		case update := <-d.updates:
+			update()
+		case <-ticker.C:
+			d.needsRefresh = true
+		}
+	}
+}
+
+// readKeys - keys typed on the terminal, arrows being reported as up / down
+func readKeys(input io.Reader) <-chan string {
+	keys := make(chan string)
+
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 16)
+		for {
+			n, err := input.Read(buf)
+			if err != nil {
+				return
+			}
+
+			data := string(buf[:n])
+			switch {
+			case strings.HasPrefix(data, "\033[A"), strings.HasPrefix(data, "\033OA"):
+				keys <- keyUp
+			case strings.HasPrefix(data, "\033[B"), strings.HasPrefix(data, "\033OB"):
+				keys <- keyDown
+			case strings.HasPrefix(data, "\033["), strings.HasPrefix(data, "\033O"):
+				// Other escape sequences are ignored
+			case data == "\033":
+				keys <- keyEsc
+			default:
+				for _, r := range data {
+					switch r {
+					case '\r', '\n':
+						keys <- keyEnter
+					default:
+						keys <- string(r)
+					}
+				}
+			}
+		}
+	}()
+
+	return keys
+}
+
+// handleKey - apply a key, true to quit
+func (d *topDashboard) handleKey(key string) bool {
+	if d.confirm != nil {
+		action := d.confirm
+		d.confirm = nil
+		if key != "y" && key != "Y" {
+			d.message = "Cancelled."
+			return false
+		}
+		d.runAction(action)
+		return false
+	}
+
+	if d.view != viewTable {
+		switch key {
+		case "q", keyEsc, "h":
+			d.closeLogs()
+			d.view = viewTable
+		case keyUp, "k":
+			d.offset--
+		case keyDown, "j":
+			d.offset++
+		case "l":
+			if d.view == viewLogs {
+				d.openLogs()
+			}
+		}
+		return false
+	}
+
+	d.message = ""
+	row, hasRow := d.selectedRow()
+
+	switch key {
+	case "q":
+		return true
+	case keyUp, "k":
+		d.moveSelection(-1)
+	case keyDown, "j":
+		d.moveSelection(1)
+	case "s":
+		d.sortColumn = (d.sortColumn + 1) % len(topColumns)
+		d.sortRows()
+	case "r":
+		d.descending = !d.descending
+		d.sortRows()
+	case "1", "2", "3", "4", "5", "6", "7":
+		d.sortColumn = int(key[0]-'1') % len(topColumns)
+		d.sortRows()
+	case " ":
+		d.needsRefresh = true
+	case "p":
+		if hasRow {
+			d.confirm = &topAction{verb: "Pause", name: row.endpoint.Name, run: d.client.PauseEndpoint}
+		}
+	case "z":
+		if hasRow {
+			d.confirm = &topAction{verb: "Scale to zero", name: row.endpoint.Name, run: d.client.ScaleEndpointToZero}
+		}
+	case "u":
+		if hasRow {
+			d.runAction(&topAction{verb: "Resume", name: row.endpoint.Name, run: d.client.ResumeEndpoint})
+		}
+	case "l":
+		if hasRow {
+			d.openLogs()
+		}
+	case keyEnter, "d":
+		if hasRow {
+			details, _ := json.MarshalIndent(row.endpoint, "", "  ")
+			d.openView(viewDetails, row.endpoint.Name, strings.Split(string(details), "\n"), 0)
+		}
+	}
+
+	return false
+}
+
+// runAction - run an action in the background, the dashboard staying responsive
+func (d *topDashboard) runAction(action *topAction) {
+	d.message = fmt.Sprintf("%s %s...", action.verb, action.name)
+
+	go func() {
+		err := action.run(d.namespace, action.name)
+		d.updates <- func() {
+			if err != nil {
+				d.message = fmt.Sprintf("%s %s failed: %v", action.verb, action.name, err)
+				return
+			}
+			d.message = fmt.Sprintf("%s %s requested.", action.verb, action.name)
+			d.needsRefresh = true
+		}
+	}()
+}
+
+// openLogs - follow the logs of the selected endpoint
+func (d *topDashboard) openLogs() {
+	row, ok := d.selectedRow()
+	if !ok {
+		return
+	}
+
+	d.closeLogs()
+	d.logsGen++
+	gen, name := d.logsGen, row.endpoint.Name
+	d.openView(viewLogs, name+" logs (connecting...)", nil, 0)
+	d.following = true
+
+	go func() {
+		stream, err := d.client.StreamEndpointLogs(d.namespace, name, nil)
+		d.updates <- func() {
+			if err != nil {
+				if gen == d.logsGen {
+					d.viewTitle = fmt.Sprintf("%s logs (could not stream: %v, l to retry)", name, err)
+				}
+				return
+			}
+			if gen != d.logsGen || d.view != viewLogs {
+				stream.Close()
+				return
+			}
+			d.logs = stream
+			d.viewTitle = name + " logs (following)"
+		}
+		if err != nil {
+			return
+		}
+
+		events := client.NewSSEReader(stream)
+		for {
+			event, err := events.Next()
+			if err != nil {
+				d.updates <- func() {
+					if gen == d.logsGen && d.logs != nil {
+						d.logs = nil
+						d.viewTitle = name + " logs (stream ended, l to reconnect)"
+					}
+				}
+				return
+			}
+			if event.Data == "" {
+				continue
+			}
+
+			lines := strings.Split(strings.TrimRight(event.Data, "\n"), "\n")
+			d.updates <- func() {
+				if gen == d.logsGen {
+					d.appendLogs(lines)
+				}
+			}
+		}
+	}()
+}
+
+// appendLogs - add streamed lines, scrolling along while the view shows the last line
+func (d *topDashboard) appendLogs(lines []string) {
+	d.viewLines = append(d.viewLines, lines...)
+	if extra := len(d.viewLines) - topMaxLogLines; extra > 0 {
+		d.viewLines = d.viewLines[extra:]
+		d.offset -= extra
+	}
+	if d.following {
+		d.offset = len(d.viewLines)
+	}
+}
+
+// closeLogs - stop following the logs
+func (d *topDashboard) closeLogs() {
+	d.logsGen++
+	if d.logs != nil {
+		d.logs.Close()
+		d.logs = nil
+	}
+}
+
+func (d *topDashboard) openView(view, title string, lines []string, offset int) {
+	d.view = view
+	d.viewTitle = title
+	d.viewLines = lines
+	d.offset = offset
+}
+
+func (d *topDashboard) sortRows() {
+	column := topColumns[d.sortColumn]
+	sort.SliceStable(d.rows, func(i, j int) bool {
+		if d.descending {
+			return column.less(d.rows[j], d.rows[i])
+		}
+		return column.less(d.rows[i], d.rows[j])
+	})
+}
+
+func (d *topDashboard) selectedIndex() int {
+	for i, row := range d.rows {
+		if row.endpoint.Name == d.selected {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func (d *topDashboard) selectedRow() (topRow, bool) {
+	if len(d.rows) == 0 {
+		return topRow{}, false
+	}
+
+	return d.rows[d.selectedIndex()], true
+}
+
+func (d *topDashboard) moveSelection(delta int) {
+	if len(d.rows) == 0 {
+		return
+	}
+
+	index := d.selectedIndex() + delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(d.rows) {
+		index = len(d.rows) - 1
+	}
+	d.selected = d.rows[index].endpoint.Name
+}
+
+// draw - redraw the whole screen
+func (d *topDashboard) draw(w io.Writer) {
+	height, width := utils.TerminalSize()
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+
+	if d.view != viewTable {
+		d.drawView(&b, height, width)
+		io.WriteString(w, b.String())
+		return
+	}
+
+	status := "refreshing..."
+	if !d.refreshed.IsZero() {
+		status = "refreshed " + d.refreshed.Format("15:04:05")
+	}
+	arrow := "asc"
+	if d.descending {
+		arrow = "desc"
+	}
+	b.WriteString(fitLine(fmt.Sprintf("%s  %d endpoints  %s  sort: %s %s", d.namespace, len(d.rows), status, topColumns[d.sortColumn].title, arrow), width))
+	b.WriteString("\n\n")
+
+	var header strings.Builder
+	for _, column := range topColumns {
+		header.WriteString(fitCell(column.title, column.width))
+	}
+	b.WriteString("\033[1m" + fitLine(header.String(), width) + "\033[0m\n")
+
+	// Keep the selection visible
+	visible := height - 6
+	if visible < 1 {
+		visible = 1
+	}
+	selected := d.selectedIndex()
+	first := 0
+	if selected >= visible {
+		first = selected - visible + 1
+	}
+
+	for i := first; i < len(d.rows) && i < first+visible; i++ {
+		var line strings.Builder
+		for _, column := range topColumns {
+			line.WriteString(fitCell(column.value(d.rows[i]), column.width))
+		}
+		text := fitLine(line.String(), width)
+		if i == selected {
+			text = "\033[7m" + text + "\033[0m"
+		}
+		b.WriteString(text + "\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case d.confirm != nil:
+		b.WriteString(fitLine(fmt.Sprintf("%s %s? [y/N]", d.confirm.verb, d.confirm.name), width))
+	case d.message != "":
+		b.WriteString(fitLine(d.message, width))
+	case d.err != nil:
+		b.WriteString(fitLine("Refresh failed: "+d.err.Error(), width))
+	}
+	b.WriteString("\n")
+	b.WriteString(fitLine("↑/↓ select  s/1-7 sort  r reverse  p pause  u resume  z scale to zero  l logs  enter details  space refresh  q quit", width))
+
+	io.WriteString(w, b.String())
+}
+
+// drawView - scrollable details and logs views
+func (d *topDashboard) drawView(b *strings.Builder, height, width int) {
+	visible := height - 3
+	if visible < 1 {
+		visible = 1
+	}
+
+	maxOffset := len(d.viewLines) - visible
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	if d.offset > maxOffset {
+		d.offset = maxOffset
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+	d.following = d.offset == maxOffset
+
+	b.WriteString("\033[1m" + fitLine(d.viewTitle, width) + "\033[0m\n")
+	for i := d.offset; i < len(d.viewLines) && i < d.offset+visible; i++ {
+		b.WriteString(fitLine(d.viewLines[i], width) + "\n")
+	}
+	b.WriteString(fitLine("↑/↓ scroll  esc back", width))
+}
+
+// fitCell - pad or truncate text to width, keeping a separating space
+func fitCell(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) >= width {
+		return string(runes[:width-1]) + " "
+	}
+
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+// fitLine - truncate text to the terminal width
+func fitLine(text string, width int) string {
+	runes := []rune(strings.TrimRight(text, " "))
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+
+	return string(runes)
+}
+
+// fetchTopRows - list the endpoints of the namespace with the last values of
+// the dashboard metrics of running endpoints
+func fetchTopRows(c *client.Client, namespace string) ([]topRow, error) {
+	endpoints, err := c.ListEndpoints(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]topRow, len(endpoints))
+	for i, endpoint := range endpoints {
+		rows[i] = topRow{endpoint: endpoint, pending: math.NaN(), p95: math.NaN(), errorRate: math.NaN()}
+	}
+
+	concurrency := topConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	now := time.Now()
+	step := "1m"
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i := range rows {
+		if rows[i].endpoint.Status.State != client.StateRunning {
+			continue
+		}
+
+		for _, metric := range topMetrics {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(i int, metric string) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				series, err := c.GetEndpointMetricSeries(namespace, rows[i].endpoint.Name, metric, now.Add(-5*time.Minute), now, &step)
+				if err != nil {
+					return
+				}
+
+				// Pending requests add up over replicas, latency and error rate keep the worst one
+				value := math.NaN()
+				for _, s := range series {
+					if len(s.Points) == 0 {
+						continue
+					}
+					last := s.Points[len(s.Points)-1].Value
+					switch {
+					case math.IsNaN(value):
+						value = last
+					case metric == "pending-requests":
+						value += last
+					default:
+						value = math.Max(value, last)
+					}
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch metric {
+				case "pending-requests":
+					rows[i].pending = value
+				case "p95-latency":
+					rows[i].p95 = value
+				case "server-error-rate":
+					rows[i].errorRate = value
+				}
+			}(i, metric)
+		}
+	}
+	wg.Wait()
+
+	return rows, nil
+}
@@ -0,0 +1,359 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const (
+	// Interval between two listings of the endpoints
+	DefaultInterval = 30 * time.Second
+	// Minimum age of a cached metric before it is fetched again, the metrics
+	// api aggregates samples per minute
+	DefaultMetricsInterval = time.Minute
+	// Lookback of metric queries, the last point of the window is exported
+	DefaultWindow = 5 * time.Minute
+	// Number of metric queries run in parallel
+	DefaultConcurrency = 4
+
+	namePrefix = "huggingface_endpoint_"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter periodically lists the endpoints of namespaces and fetches their
+// metrics, serving the cached result in the Prometheus exposition format.
+// Scrapes never hit the api, so its rate limits only depend on the intervals.
+type Exporter struct {
+	Client     *client.Client
+	Namespaces []string
+	Metrics    []string
+
+	Interval        time.Duration
+	MetricsInterval time.Duration
+	Window          time.Duration
+	Concurrency     int
+	// Logger of refresh errors, discarded when nil
+	ErrorLog *log.Logger
+
+	mu          sync.Mutex
+	endpoints   map[string][]client.EndpointWithStatus
+	metrics     map[metricKey]cachedMetric
+	requests    float64
+	errors      float64
+	lastRefresh time.Time
+	refreshTime time.Duration
+	page        []byte
+	now         func() time.Time
+}
+
+type metricKey struct {
+	namespace string
+	name      string
+	metric    string
+}
+
+type cachedMetric struct {
+	series    []client.MetricSeries
+	fetchedAt time.Time
+}
+
+// New - exporter of the endpoints of namespaces with default intervals
+func New(c *client.Client, namespaces, metrics []string) *Exporter {
+	return &Exporter{
+		Client:          c,
+		Namespaces:      namespaces,
+		Metrics:         metrics,
+		Interval:        DefaultInterval,
+		MetricsInterval: DefaultMetricsInterval,
+		Window:          DefaultWindow,
+		Concurrency:     DefaultConcurrency,
+		endpoints:       map[string][]client.EndpointWithStatus{},
+		metrics:         map[metricKey]cachedMetric{},
+		now:             time.Now,
+	}
+}
+
+// Run - refresh until the context is done, starting immediately
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh - list the endpoints and fetch the metrics older than MetricsInterval.
+// Failed calls keep the previous values and are counted in the exporter metrics.
+func (e *Exporter) Refresh(ctx context.Context) {
+	start := e.now()
+
+	for _, namespace := range e.Namespaces {
+		if ctx.Err() != nil {
+			return
+		}
+
+		endpoints, err := e.Client.ListEndpoints(namespace, nil)
+		e.record(err, "list endpoints of "+namespace)
+		if err != nil {
+			continue
+		}
+
+		e.mu.Lock()
+		e.endpoints[namespace] = endpoints
+		e.mu.Unlock()
+	}
+
+	jobs := e.staleMetrics(start)
+
+	concurrency := e.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, key := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(key metricKey) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			now := e.now()
+			step := "1m"
+			series, err := e.Client.GetEndpointMetricSeries(key.namespace, key.name, key.metric, now.Add(-e.Window), now, &step)
+			e.record(err, "fetch "+key.metric+" of "+key.namespace+"/"+key.name)
+			if err != nil {
+				return
+			}
+
+			e.mu.Lock()
+			e.metrics[key] = cachedMetric{series: series, fetchedAt: now}
+			e.mu.Unlock()
+		}(key)
+	}
+	wg.Wait()
+
+	e.mu.Lock()
+	e.lastRefresh = start
+	e.refreshTime = e.now().Sub(start)
+	e.page = e.render()
+	e.mu.Unlock()
+}
+
+// staleMetrics - metrics to fetch, dropping the cache of removed endpoints
+func (e *Exporter) staleMetrics(now time.Time) []metricKey {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	listed := map[metricKey]bool{}
+	var stale []metricKey
+	for namespace, endpoints := range e.endpoints {
+		for _, endpoint := range endpoints {
+			for _, metric := range e.Metrics {
+				key := metricKey{namespace: namespace, name: endpoint.Name, metric: metric}
+				listed[key] = true
+				if cached, ok := e.metrics[key]; !ok || now.Sub(cached.fetchedAt) >= e.MetricsInterval {
+					stale = append(stale, key)
+				}
+			}
+		}
+	}
+
+	for key := range e.metrics {
+		if !listed[key] {
+			delete(e.metrics, key)
+		}
+	}
+
+	return stale
+}
+
+func (e *Exporter) record(err error, action string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests++
+	if err != nil {
+		e.errors++
+		if e.ErrorLog != nil {
+			e.ErrorLog.Printf("could not %s: %v", action, err)
+		}
+	}
+}
+
+// ServeHTTP - serve the metrics of the last refresh
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	page := e.page
+	e.mu.Unlock()
+
+	if page == nil {
+		http.Error(w, "metrics not collected yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(page)
+}
+
+// render - exposition of the cached state, called with the lock held
+func (e *Exporter) render() []byte {
+	info := Family{Name: namePrefix + "info", Help: "Endpoint information, always 1.", Type: TypeGauge}
+	up := Family{Name: namePrefix + "up", Help: "Whether the endpoint is running.", Type: TypeGauge}
+	ready := Family{Name: namePrefix + "ready_replicas", Help: "Number of ready replicas.", Type: TypeGauge}
+	target := Family{Name: namePrefix + "target_replicas", Help: "Number of targeted replicas.", Type: TypeGauge}
+	minReplicas := Family{Name: namePrefix + "min_replicas", Help: "Minimum number of replicas of the scaling policy.", Type: TypeGauge}
+	maxReplicas := Family{Name: namePrefix + "max_replicas", Help: "Maximum number of replicas of the scaling policy.", Type: TypeGauge}
+	count := Family{Name: namePrefix + "count", Help: "Number of endpoints per namespace.", Type: TypeGauge}
+
+	metricFamilies := map[string]*Family{}
+	for _, metric := range e.Metrics {
+		metricFamilies[metric] = &Family{
+			Name: MetricName(metric),
+			Help: "Last value of the " + metric + " endpoint metric.",
+			Type: TypeGauge,
+		}
+	}
+
+	namespaces := make([]string, 0, len(e.endpoints))
+	for namespace := range e.endpoints {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		endpoints := make([]client.EndpointWithStatus, len(e.endpoints[namespace]))
+		copy(endpoints, e.endpoints[namespace])
+		sort.Slice(endpoints, func(i, j int) bool {
+			return endpoints[i].Name < endpoints[j].Name
+		})
+
+		count.Samples = append(count.Samples, Sample{Labels: []Label{{"namespace", namespace}}, Value: float64(len(endpoints))})
+
+		for _, endpoint := range endpoints {
+			labels := EndpointLabels(namespace, endpoint)
+
+			infoLabels := append(append([]Label{}, labels...),
+				Label{"repository", endpoint.Model.Repository},
+				Label{"task", string(endpoint.Model.Task)},
+				Label{"type", string(endpoint.Type)},
+			)
+			info.Samples = append(info.Samples, Sample{Labels: infoLabels, Value: 1})
+
+			running := 0.0
+			if endpoint.Status.State == client.StateRunning {
+				running = 1
+			}
+			up.Samples = append(up.Samples, Sample{Labels: labels, Value: running})
+			ready.Samples = append(ready.Samples, Sample{Labels: labels, Value: float64(endpoint.Status.ReadyReplica)})
+			target.Samples = append(target.Samples, Sample{Labels: labels, Value: float64(endpoint.Status.TargetReplica)})
+			minReplicas.Samples = append(minReplicas.Samples, Sample{Labels: labels, Value: float64(endpoint.Compute.Scaling.MinReplica)})
+			maxReplicas.Samples = append(maxReplicas.Samples, Sample{Labels: labels, Value: float64(endpoint.Compute.Scaling.MaxReplica)})
+
+			for _, metric := range e.Metrics {
+				cached, ok := e.metrics[metricKey{namespace: namespace, name: endpoint.Name, metric: metric}]
+				if !ok {
+					continue
+				}
+				for _, series := range cached.series {
+					if len(series.Points) == 0 {
+						continue
+					}
+					metricFamilies[metric].Samples = append(metricFamilies[metric].Samples, Sample{
+						Labels: SeriesLabels(labels, series.Labels),
+						Value:  series.Points[len(series.Points)-1].Value,
+					})
+				}
+			}
+		}
+	}
+
+	families := []Family{info, up, ready, target, minReplicas, maxReplicas, count}
+	for _, family := range metricFamilies {
+		families = append(families, *family)
+	}
+
+	families = append(families,
+		Family{Name: "huggingface_exporter_api_requests_total", Help: "Number of api calls made by the exporter.", Type: TypeCounter, Samples: []Sample{{Value: e.requests}}},
+		Family{Name: "huggingface_exporter_api_errors_total", Help: "Number of failed api calls made by the exporter.", Type: TypeCounter, Samples: []Sample{{Value: e.errors}}},
+		Family{Name: "huggingface_exporter_last_refresh_timestamp_seconds", Help: "Time of the last refresh.", Type: TypeGauge, Samples: []Sample{{Value: float64(e.lastRefresh.UnixNano()) / 1e9}}},
+		Family{Name: "huggingface_exporter_refresh_duration_seconds", Help: "Duration of the last refresh.", Type: TypeGauge, Samples: []Sample{{Value: e.refreshTime.Seconds()}}},
+	)
+
+	var buf bytes.Buffer
+	WriteText(&buf, families)
+
+	return buf.Bytes()
+}
+
+// MetricName - exported name of an endpoint metric, e.g. pending-requests
+// becomes huggingface_endpoint_metric_pending_requests
+func MetricName(metric string) string {
+	return namePrefix + "metric_" + SanitizeName(strings.ToLower(metric))
+}
+
+// EndpointLabels - labels identifying an endpoint and its deployment
+func EndpointLabels(namespace string, endpoint client.EndpointWithStatus) []Label {
+	return []Label{
+		{"namespace", namespace},
+		{"endpoint", endpoint.Name},
+		{"state", string(endpoint.Status.State)},
+		{"accelerator", string(endpoint.Compute.Accelerator)},
+		{"vendor", endpoint.Provider.Vendor},
+		{"region", endpoint.Provider.Region},
+		{"instance_type", endpoint.Compute.InstanceType},
+		{"instance_size", endpoint.Compute.InstanceSize},
+	}
+}
+
+// SeriesLabels - endpoint labels extended with the labels of a metric series
+// (e.g. replica), labels clashing with the endpoint ones are dropped
+func SeriesLabels(labels []Label, seriesLabels map[string]string) []Label {
+	if len(seriesLabels) == 0 {
+		return labels
+	}
+
+	taken := map[string]bool{}
+	for _, label := range labels {
+		taken[label.Name] = true
+	}
+
+	names := make([]string, 0, len(seriesLabels))
+	for name := range seriesLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	extended := append([]Label{}, labels...)
+	for _, name := range names {
+		sanitized := SanitizeName(name)
+		if sanitized == "" || strings.HasPrefix(sanitized, "__") || taken[sanitized] {
+			continue
+		}
+		taken[sanitized] = true
+		extended = append(extended, Label{sanitized, seriesLabels[name]})
+	}
+
+	return extended
+}
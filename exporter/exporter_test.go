@@ -0,0 +1,91 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestWriteText(t *testing.T) {
+	var buf strings.Builder
+	WriteText(&buf, []Family{
+		{Name: "b", Help: "second", Type: TypeGauge, Samples: []Sample{{Value: 1.5}}},
+		{Name: "a", Help: "first", Type: TypeCounter, Samples: []Sample{{Labels: []Label{{"name", "x\"y"}}, Value: 2}}},
+		{Name: "empty", Help: "skipped", Type: TypeGauge},
+	})
+
+	expected := "# HELP a first\n# TYPE a counter\na{name=\"x\\\"y\"} 2\n# HELP b second\n# TYPE b gauge\nb 1.5\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected exposition:\n%s", buf.String())
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var metricCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/metrics/") {
+			atomic.AddInt32(&metricCalls, 1)
+			io.WriteString(w, `{"data":{"result":[{"metric":{"replica":"r1"},"values":[[1700000000,"1"],[1700000060,"3"]]}]}}`)
+			return
+		}
+		io.WriteString(w, `{"items":[{"name":"llm","provider":{"vendor":"aws","region":"us-east-1"},"compute":{"accelerator":"gpu"},"status":{"state":"running","readyReplica":1,"targetReplica":1}}]}`)
+	}))
+	defer server.Close()
+
+	host, token := server.URL, "token"
+	c, _ := client.NewClient(&host, &token)
+
+	exporter := New(c, []string{"org"}, []string{"pending-requests"})
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before the first refresh, got %d", recorder.Code)
+	}
+
+	exporter.Refresh(context.Background())
+	exporter.Refresh(context.Background())
+	if atomic.LoadInt32(&metricCalls) != 1 {
+		t.Fatalf("metrics should be cached for MetricsInterval, got %d calls", metricCalls)
+	}
+
+	recorder = httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	labels := `namespace="org",endpoint="llm",state="running",accelerator="gpu",vendor="aws",region="us-east-1",instance_type="",instance_size=""`
+	for _, line := range []string{
+		"huggingface_endpoint_up{" + labels + "} 1",
+		"huggingface_endpoint_metric_pending_requests{" + labels + `,replica="r1"} 3`,
+		"huggingface_exporter_api_requests_total 3",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("missing %s in:\n%s", line, body)
+		}
+	}
+
+	exporter.now = func() time.Time { return time.Now().Add(DefaultMetricsInterval) }
+	exporter.Refresh(context.Background())
+	if atomic.LoadInt32(&metricCalls) != 2 {
+		t.Fatalf("stale metrics should be fetched again, got %d calls", metricCalls)
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var buf strings.Builder
+	WriteOpenMetrics(&buf, []Family{
+		{Name: "cpu", Help: "cpu usage", Type: TypeGauge, Samples: []Sample{{Value: 0.5, Timestamp: time.Unix(1700000000, 500000000)}}},
+	})
+
+	expected := "# TYPE cpu gauge\n# HELP cpu cpu usage\ncpu 0.5 1700000000.5\n# EOF\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected exposition:\n%s", buf.String())
+	}
+}
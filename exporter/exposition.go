@@ -0,0 +1,160 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	Labels []Label
+	Value  float64
+	// Optional sample time, omitted when zero
+	Timestamp time.Time
+}
+
+// Family is a metric with its help text, type and samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// WriteText - write metric families in the Prometheus text exposition format,
+// families sorted by name
+func WriteText(w io.Writer, families []Family) error {
+	sorted := make([]Family, len(families))
+	copy(sorted, families)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, family := range sorted {
+		if len(family.Samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			bw.WriteString(family.Name)
+			WriteLabels(bw, sample.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(FormatValue(sample.Value))
+			if !sample.Timestamp.IsZero() {
+				// Milliseconds in the Prometheus text format
+				fmt.Fprintf(bw, " %d", sample.Timestamp.UnixNano()/int64(time.Millisecond))
+			}
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+// WriteOpenMetrics - write metric families in the OpenMetrics text format, in the
+// given order since samples of a family must not be interleaved with other families
+func WriteOpenMetrics(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, family := range families {
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family.Name, family.Type)
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		for _, sample := range family.Samples {
+			name := family.Name
+			if family.Type == TypeCounter {
+				name += "_total"
+			}
+			bw.WriteString(name)
+			WriteLabels(bw, sample.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(FormatValue(sample.Value))
+			if !sample.Timestamp.IsZero() {
+				// Seconds in the OpenMetrics format
+				bw.WriteByte(' ')
+				bw.WriteString(strconv.FormatFloat(float64(sample.Timestamp.UnixNano())/1e9, 'f', -1, 64))
+			}
+			bw.WriteByte('\n')
+		}
+	}
+	bw.WriteString("# EOF\n")
+
+	return bw.Flush()
+}
+
+// WriteLabels - write a {name="value",...} label set, nothing when empty
+func WriteLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+
+	w.WriteByte('{')
+	for i, label := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(label.Name)
+		w.WriteString(`="`)
+		w.WriteString(escapeLabelValue(label.Value))
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+// FormatValue - sample value as expected by the exposition format
+func FormatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// SanitizeName - replace characters not allowed in metric and label names with underscores
+func SanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
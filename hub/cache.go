@@ -0,0 +1,140 @@
+package hub
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CacheDir - directory of the hub cache shared with huggingface_hub: $HF_HUB_CACHE,
+// $HF_HOME/hub, or huggingface/hub in $XDG_CACHE_HOME or ~/.cache
+func CacheDir() (string, error) {
+	if cacheDir := os.Getenv("HF_HUB_CACHE"); cacheDir != "" {
+		return cacheDir, nil
+	}
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "hub"), nil
+	}
+
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = filepath.Join(userHome, ".cache")
+	}
+
+	return filepath.Join(cacheDir, "huggingface", "hub"), nil
+}
+
+// RepoFolderName - cache folder of a repository, models--org--name for the model org/name
+func RepoFolderName(repoType RepoType, repository string) string {
+	return string(repoType) + "s--" + strings.ReplaceAll(repository, "/", "--")
+}
+
+// repoCache is the cache folder of a repository, laid out as in huggingface_hub:
+// blobs/<etag> holds the file contents, snapshots/<commit>/<path> links to the blobs
+// and refs/<revision> holds the commit a branch or tag pointed to when last resolved
+type repoCache struct {
+	dir string
+}
+
+func newRepoCache(cacheDir string, repoType RepoType, repository string) repoCache {
+	return repoCache{dir: filepath.Join(cacheDir, RepoFolderName(repoType, repository))}
+}
+
+func (r repoCache) blobPath(etag string) string {
+	return filepath.Join(r.dir, "blobs", etag)
+}
+
+func (r repoCache) snapshotPath(commit, path string) string {
+	return filepath.Join(r.dir, "snapshots", commit, filepath.FromSlash(path))
+}
+
+func (r repoCache) refPath(revision string) string {
+	return filepath.Join(r.dir, "refs", filepath.FromSlash(revision))
+}
+
+// readRef - commit revision pointed to when last resolved, revision itself for commits
+func (r repoCache) readRef(revision string) (string, bool) {
+	if IsCommitSHA(revision) {
+		return revision, true
+	}
+
+	content, err := os.ReadFile(r.refPath(revision))
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(content)), true
+}
+
+// writeRef - record the commit revision points to, nothing to record for commits
+func (r repoCache) writeRef(revision, commit string) error {
+	if revision == commit {
+		return nil
+	}
+
+	path := r.refPath(revision)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(commit), 0644)
+}
+
+// cached - snapshot path of a file if it is in the cache
+func (r repoCache) cached(commit, path string) (string, bool) {
+	snapshot := r.snapshotPath(commit, path)
+	if _, err := os.Stat(snapshot); err != nil {
+		return "", false
+	}
+
+	return snapshot, true
+}
+
+// link - point the snapshot path of a file to its blob, with a relative symlink as huggingface_hub,
+// falling back to a hard link then a copy where symlinks are not supported
+func (r repoCache) link(commit, path, etag string) (string, error) {
+	snapshot := r.snapshotPath(commit, path)
+	if err := os.MkdirAll(filepath.Dir(snapshot), 0755); err != nil {
+		return "", err
+	}
+	os.Remove(snapshot)
+
+	blob := r.blobPath(etag)
+	target, err := filepath.Rel(filepath.Dir(snapshot), blob)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Symlink(target, snapshot); err == nil {
+		return snapshot, nil
+	}
+	if err := os.Link(blob, snapshot); err == nil {
+		return snapshot, nil
+	}
+
+	return snapshot, copyFile(blob, snapshot)
+}
+
+func copyFile(source, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
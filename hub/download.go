@@ -0,0 +1,466 @@
+package hub
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const (
+	DefaultDownloadChunks       = 4
+	DefaultDownloadChunkSize    = 64 * 1024 * 1024
+	DefaultDownloadRetries      = 3
+	DefaultDownloadRetryBackoff = time.Second
+	maxMetadataRedirects        = 5
+)
+
+// ErrChecksumMismatch is returned when a downloaded file does not match its etag
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// DownloadOptions configures a file download
+type DownloadOptions struct {
+	// Branch, tag or commit, defaults to main
+	Revision string
+	// Hub cache directory, defaults to CacheDir()
+	CacheDir string
+	// Maximum number of ranges of a file downloaded in parallel
+	Chunks int
+	// Minimum size of a range, smaller files are downloaded with a single request
+	ChunkSize int64
+	// Number of retries of a failed range when the error is retryable, resuming where it stopped
+	Retries      int
+	RetryBackoff time.Duration
+	// Download the file again even if it is in the cache
+	Force bool
+	// Called as bytes are written, from one goroutine at a time
+	Progress func(DownloadProgress)
+}
+
+// DownloadProgress is the state of a file download, Downloaded includes the bytes resumed from a previous run
+type DownloadProgress struct {
+	Path       string
+	Downloaded int64
+	Total      int64
+}
+
+// FileMetadata is the resolution of a file of a repository at a revision
+type FileMetadata struct {
+	// Commit the revision resolved to
+	Commit string `json:"commit"`
+	// sha256 of the content for lfs files, git blob id otherwise
+	ETag string `json:"etag"`
+	Size int64  `json:"size"`
+	// Url the content is served from, the cdn for lfs files
+	Location string `json:"location"`
+}
+
+// resolveURL - url serving a file of a model repository at a revision
+func (h *Client) resolveURL(repository, revision, path string) string {
+	elements := strings.Split(path, "/")
+	for i := range elements {
+		elements[i] = url.PathEscape(elements[i])
+	}
+
+	return fmt.Sprintf("%s/%s/resolve/%s/%s", h.Client.HubBaseURL(), repository, escapeRevision(revision), strings.Join(elements, "/"))
+}
+
+// GetFileMetadata - commit, etag, size and location of a file of a model repository at a revision,
+// read from the headers of the hub without downloading the file
+func (h *Client) GetFileMetadata(ctx context.Context, repository, revision, path string) (*FileMetadata, error) {
+	c := h.transferClient(false)
+	endpoint := h.resolveURL(repository, revision, path)
+
+	for redirects := 0; ; redirects++ {
+		req, err := http.NewRequestWithContext(ctx, "HEAD", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept-Encoding", "identity")
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the metadata of %s: %w", path, err)
+		}
+		resp.Body.Close()
+
+		var location string
+		if header := resp.Header.Get("Location"); header != "" {
+			next, err := req.URL.Parse(header)
+			if err != nil {
+				return nil, err
+			}
+			location = next.String()
+		}
+
+		commit := resp.Header.Get("X-Repo-Commit")
+		// Renamed repositories are redirected before the file is resolved
+		if commit == "" && location != "" && redirects < maxMetadataRedirects {
+			endpoint = location
+			continue
+		}
+
+		meta := &FileMetadata{
+			Commit:   commit,
+			ETag:     normalizeETag(firstHeader(resp.Header, "X-Linked-Etag", "ETag")),
+			Location: endpoint,
+		}
+		if location != "" {
+			meta.Location = location
+		}
+		meta.Size, _ = strconv.ParseInt(firstHeader(resp.Header, "X-Linked-Size", "Content-Length"), 10, 64)
+
+		if meta.Commit == "" || meta.ETag == "" {
+			return nil, fmt.Errorf("could not get the metadata of %s: missing commit or etag headers", path)
+		}
+
+		return meta, nil
+	}
+}
+
+// Download - download a file of a model repository into the hub cache, returning its snapshot path.
+// The file is read from the cache when present, large files are downloaded as parallel ranges
+// resumed on later calls after a failure, and the content is checked against the etag of the file.
+// When the hub can not be reached, the snapshot of the last resolution of the revision is returned.
+func (h *Client) Download(ctx context.Context, repository, path string, options DownloadOptions) (string, error) {
+	if options.Revision == "" {
+		options.Revision = DefaultRevision
+	}
+	if options.CacheDir == "" {
+		cacheDir, err := CacheDir()
+		if err != nil {
+			return "", err
+		}
+		options.CacheDir = cacheDir
+	}
+
+	cache := newRepoCache(options.CacheDir, RepoModel, repository)
+
+	// Commits are immutable, a cached snapshot needs no resolution
+	if IsCommitSHA(options.Revision) && !options.Force {
+		if snapshot, ok := cache.cached(options.Revision, path); ok {
+			return snapshot, nil
+		}
+	}
+
+	meta, err := h.GetFileMetadata(ctx, repository, options.Revision, path)
+	if err != nil {
+		var httpErr *client.HTTPError
+		if !errors.As(err, &httpErr) && ctx.Err() == nil && !options.Force {
+			if commit, ok := cache.readRef(options.Revision); ok {
+				if snapshot, ok := cache.cached(commit, path); ok {
+					return snapshot, nil
+				}
+			}
+		}
+		return "", err
+	}
+
+	if IsCommitSHA(options.Revision) && meta.Commit != options.Revision {
+		return "", fmt.Errorf("could not download %s: revision %s resolved to commit %s", path, options.Revision, meta.Commit)
+	}
+	if err := cache.writeRef(options.Revision, meta.Commit); err != nil {
+		return "", err
+	}
+
+	if !options.Force {
+		if snapshot, ok := cache.cached(meta.Commit, path); ok {
+			return snapshot, nil
+		}
+	}
+
+	blob := cache.blobPath(meta.ETag)
+	if _, err := os.Stat(blob); err != nil || options.Force {
+		if err := h.downloadBlob(ctx, meta, blob, path, options); err != nil {
+			return "", err
+		}
+	}
+
+	return cache.link(meta.Commit, path, meta.ETag)
+}
+
+// byteRange is an inclusive range of bytes, with an unknown end when end is negative
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+func (r byteRange) length() int64 {
+	return r.end - r.start + 1
+}
+
+// splitRanges - ranges of at least chunkSize bytes covering size bytes, at most chunks of them
+func splitRanges(size int64, chunks int, chunkSize int64) []byteRange {
+	if size <= 0 {
+		return []byteRange{{start: 0, end: -1}}
+	}
+
+	count := int64(chunks)
+	if size/chunkSize < count {
+		count = size / chunkSize
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	ranges := make([]byteRange, count)
+	step := size / count
+	for i := range ranges {
+		ranges[i] = byteRange{start: int64(i) * step, end: int64(i+1)*step - 1}
+	}
+	ranges[count-1].end = size - 1
+
+	return ranges
+}
+
+// downloadBlob - download the content of a file to blob through a blob.incomplete file as
+// huggingface_hub, each range of a parallel download having its own part file
+func (h *Client) downloadBlob(ctx context.Context, meta *FileMetadata, blob, path string, options DownloadOptions) error {
+	if options.Chunks <= 0 {
+		options.Chunks = DefaultDownloadChunks
+	}
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultDownloadChunkSize
+	}
+	if options.Retries <= 0 {
+		options.Retries = DefaultDownloadRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultDownloadRetryBackoff
+	}
+
+	if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+		return err
+	}
+
+	incomplete := blob + ".incomplete"
+	ranges := splitRanges(meta.Size, options.Chunks, options.ChunkSize)
+	parts := []string{incomplete}
+	if len(ranges) > 1 {
+		parts = make([]string, len(ranges))
+		for i := range ranges {
+			parts[i] = fmt.Sprintf("%s.part-%d-of-%d", incomplete, i+1, len(ranges))
+		}
+	}
+	if options.Force {
+		for _, part := range parts {
+			os.Remove(part)
+		}
+	}
+
+	tracker := &progressTracker{path: path, total: meta.Size}
+	if options.Progress != nil {
+		tracker.report = func(path string, done, total int64) {
+			options.Progress(DownloadProgress{Path: path, Downloaded: done, Total: total})
+		}
+	}
+	for _, part := range parts {
+		if info, err := os.Stat(part); err == nil {
+			tracker.done += info.Size()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(ranges))
+	for i := range ranges {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.Retry(ctx, options.Retries, options.RetryBackoff, func() error {
+				return h.downloadRange(ctx, meta.Location, parts[i], ranges[i], tracker)
+			})
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The first range failing cancels the others, report its error rather than theirs
+	var failure error
+	for _, err := range errs {
+		if err != nil && (failure == nil || errors.Is(failure, context.Canceled)) {
+			failure = err
+		}
+	}
+	if failure != nil {
+		return fmt.Errorf("could not download %s: %w", path, failure)
+	}
+
+	if len(parts) > 1 {
+		if err := concatenate(incomplete, parts); err != nil {
+			return err
+		}
+	}
+
+	if err := verifyBlob(incomplete, meta); err != nil {
+		os.Remove(incomplete)
+		return fmt.Errorf("could not download %s: %w", path, err)
+	}
+
+	return os.Rename(incomplete, blob)
+}
+
+// downloadRange - append the bytes of r missing from the part file, resuming from its size
+func (h *Client) downloadRange(ctx context.Context, location, part string, r byteRange, tracker *progressTracker) error {
+	file, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	if r.end >= 0 && offset > r.length() {
+		// Longer than its range, the part can not be trusted
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+		tracker.add(-offset)
+		offset = 0
+	}
+	if r.end >= 0 && offset == r.length() {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept-Encoding", "identity")
+	if r.start+offset > 0 || r.end >= 0 {
+		end := ""
+		if r.end >= 0 {
+			end = strconv.FormatInt(r.end, 10)
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%s", r.start+offset, end))
+	}
+
+	resp, err := h.transfer(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent && r.start+offset > 0 {
+		if r.start > 0 {
+			return fmt.Errorf("server does not support range requests, got HTTP %d", resp.StatusCode)
+		}
+		// The whole file is sent again, start over
+		tracker.add(-offset)
+		offset = 0
+	}
+	if err := file.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(&progressWriter{writer: file, tracker: tracker}, &transferBody{reader: resp.Body})
+
+	return err
+}
+
+// concatenate - join the part files into path and remove them
+func concatenate(path string, parts []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		in, err := os.Open(part)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		os.Remove(part)
+	}
+
+	return nil
+}
+
+// verifyBlob - check the size of a downloaded file and its content against the etag, the sha256
+// of lfs files or the git blob id of regular files. Other etags are not checksums and are not checked.
+func verifyBlob(path string, meta *FileMetadata) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if meta.Size > 0 && info.Size() != meta.Size {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrChecksumMismatch, meta.Size, info.Size())
+	}
+
+	var digest hash.Hash
+	switch len(meta.ETag) {
+	case sha256.Size * 2:
+		digest = sha256.New()
+	case sha1.Size * 2:
+		digest = sha1.New()
+		fmt.Fprintf(digest, "blob %d\x00", info.Size())
+	default:
+		return nil
+	}
+
+	if _, err := io.Copy(digest, file); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(digest.Sum(nil)); sum != meta.ETag {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, meta.ETag, sum)
+	}
+
+	return nil
+}
+
+func normalizeETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
+
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
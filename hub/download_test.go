@@ -0,0 +1,197 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	sum := sha256.Sum256(content)
+	etag := hex.EncodeToString(sum[:])
+
+	var mu sync.Mutex
+	var ranges []string
+	serve := content
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/org/model/resolve/main/weights/model.gguf":
+			w.Header().Set("X-Repo-Commit", testSHA)
+			w.Header().Set("X-Linked-Etag", `"`+etag+`"`)
+			w.Header().Set("X-Linked-Size", "65536")
+			w.Header().Set("Location", "/cdn/blob")
+			w.WriteHeader(http.StatusFound)
+		case r.URL.Path == "/cdn/blob":
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+			http.ServeContent(w, r, "blob", time.Time{}, bytes.NewReader(serve))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	cacheDir := t.TempDir()
+	var last DownloadProgress
+	options := DownloadOptions{CacheDir: cacheDir, Chunks: 4, ChunkSize: 16384, Progress: func(p DownloadProgress) { last = p }}
+
+	snapshot, err := h.Download(context.Background(), "org/model", "weights/model.gguf", options)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	repoDir := filepath.Join(cacheDir, "models--org--model")
+	if snapshot != filepath.Join(repoDir, "snapshots", testSHA, "weights", "model.gguf") {
+		t.Fatalf("unexpected snapshot path %s", snapshot)
+	}
+	if downloaded, _ := os.ReadFile(snapshot); !bytes.Equal(downloaded, content) {
+		t.Fatal("expected the snapshot to hold the file content")
+	}
+	if target, err := os.Readlink(snapshot); err != nil || target != filepath.Join("..", "..", "..", "blobs", etag) {
+		t.Fatalf("expected a relative link to the blob, got %s (%v)", target, err)
+	}
+	if ref, _ := os.ReadFile(filepath.Join(repoDir, "refs", "main")); string(ref) != testSHA {
+		t.Fatalf("expected refs/main to hold the commit, got %s", ref)
+	}
+	if len(ranges) != 4 || last.Downloaded != 65536 || last.Total != 65536 {
+		t.Fatalf("expected 4 parallel ranges and a complete progress, got %v %+v", ranges, last)
+	}
+
+	// Cached files are not downloaded again, and are served when the hub can not be reached
+	ranges = nil
+	if _, err := h.Download(context.Background(), "org/model", "weights/model.gguf", options); err != nil || len(ranges) != 0 {
+		t.Fatalf("expected the cached file, got %v with ranges %v", err, ranges)
+	}
+	h.Client.HubHost = "http://127.0.0.1:1"
+	if offline, err := h.Download(context.Background(), "org/model", "weights/model.gguf", options); err != nil || offline != snapshot {
+		t.Fatalf("expected the cached file when offline, got %s (%v)", offline, err)
+	}
+}
+
+func TestDownloadResumeAndVerify(t *testing.T) {
+	content := []byte(strings.Repeat("resumable content ", 100))
+	sum := sha256.Sum256(content)
+	etag := hex.EncodeToString(sum[:])
+
+	var ranges []string
+	serve := content
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("X-Repo-Commit", testSHA)
+			w.Header().Set("ETag", `"`+etag+`"`)
+			w.Header().Set("Content-Length", "1800")
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "blob", time.Time{}, bytes.NewReader(serve))
+	})
+
+	cacheDir := t.TempDir()
+	blobs := filepath.Join(cacheDir, "models--org--model", "blobs")
+	os.MkdirAll(blobs, 0755)
+	os.WriteFile(filepath.Join(blobs, etag+".incomplete"), content[:1000], 0644)
+
+	options := DownloadOptions{CacheDir: cacheDir, Revision: testSHA}
+	snapshot, err := h.Download(context.Background(), "org/model", "model.bin", options)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if downloaded, _ := os.ReadFile(snapshot); !bytes.Equal(downloaded, content) || ranges[0] != "bytes=1000-1799" {
+		t.Fatalf("expected the download to resume from byte 1000, got ranges %v", ranges)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "models--org--model", "refs")); !os.IsNotExist(err) {
+		t.Fatal("expected no ref for a commit revision")
+	}
+
+	serve = bytes.ToUpper(content)
+	options.Force = true
+	if _, err := h.Download(context.Background(), "org/model", "model.bin", options); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(blobs, etag+".incomplete")); !os.IsNotExist(err) {
+		t.Fatal("expected the corrupted download to be removed")
+	}
+}
+
+func TestDownloadRetriesTruncatedRange(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 2048)
+	sum := sha256.Sum256(content)
+	etag := hex.EncodeToString(sum[:])
+
+	var mu sync.Mutex
+	var ranges []string
+	truncated := false
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("X-Repo-Commit", testSHA)
+			w.Header().Set("ETag", `"`+etag+`"`)
+			w.Header().Set("Content-Length", "32768")
+			return
+		}
+
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		truncate := !truncated && r.Header.Get("Range") == "bytes=0-16383"
+		truncated = truncated || truncate
+		mu.Unlock()
+
+		if truncate {
+			// The connection drops after half of the range
+			w.Header().Set("Content-Range", "bytes 0-16383/32768")
+			w.Header().Set("Content-Length", "16384")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[:8192])
+			return
+		}
+		http.ServeContent(w, r, "blob", time.Time{}, bytes.NewReader(content))
+	})
+
+	options := DownloadOptions{CacheDir: t.TempDir(), Revision: testSHA, Chunks: 2, ChunkSize: 16384, RetryBackoff: time.Millisecond}
+	snapshot, err := h.Download(context.Background(), "org/model", "model.bin", options)
+	if err != nil {
+		t.Fatalf("expected the truncated range to be retried, got %v", err)
+	}
+	if downloaded, _ := os.ReadFile(snapshot); !bytes.Equal(downloaded, content) {
+		t.Fatal("expected the snapshot to hold the file content")
+	}
+	resumed := false
+	for _, r := range ranges {
+		resumed = resumed || r == "bytes=8192-16383"
+	}
+	if len(ranges) != 3 || !resumed {
+		t.Fatalf("expected the truncated range to resume from its part file, got ranges %v", ranges)
+	}
+}
+
+func TestVerifyGitBlob(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	os.WriteFile(path, []byte("hello\n"), 0644)
+
+	// git hash-object of "hello\n"
+	if err := verifyBlob(path, &FileMetadata{ETag: "ce013625030ba8dba906f756967f9e9ca394464a", Size: 6}); err != nil {
+		t.Fatalf("expected the git blob id to match, got %v", err)
+	}
+	if err := verifyBlob(path, &FileMetadata{ETag: strings.Repeat("0", 40)}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestSplitRanges(t *testing.T) {
+	if r := splitRanges(100, 4, 64); len(r) != 1 || r[0].end != 99 {
+		t.Fatalf("expected a single range for a small file, got %v", r)
+	}
+	r := splitRanges(1000, 3, 100)
+	if len(r) != 3 || r[0] != (byteRange{0, 332}) || r[2] != (byteRange{666, 999}) {
+		t.Fatalf("unexpected ranges %v", r)
+	}
+}
@@ -0,0 +1,92 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+// DefaultRevision is the branch used when no revision is given, as in huggingface_hub
+const DefaultRevision string = "main"
+
+// RepoType is the kind of a hub repository
+type RepoType string
+
+const (
+	RepoModel   RepoType = "model"
+	RepoDataset RepoType = "dataset"
+	RepoSpace   RepoType = "space"
+)
+
+// commitSHA matches the full hexadecimal id of a git commit
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// linkNext matches the url of the next page in a Link header
+var linkNext = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Client calls the hub api with the host and credentials of an endpoints client
+type Client struct {
+	Client *client.Client
+}
+
+// New - hub client sharing the host, token and http client of c
+func New(c *client.Client) *Client {
+	return &Client{Client: c}
+}
+
+// IsCommitSHA - whether revision is a full commit id rather than a branch or a tag
+func IsCommitSHA(revision string) bool {
+	return commitSHA.MatchString(revision)
+}
+
+// apiURL - url of the api of a repository, followed by the path elements
+func (h *Client) apiURL(repoType RepoType, repository string, elements ...string) string {
+	parts := []string{h.Client.HubBaseURL(), "api", string(repoType) + "s", repository}
+	for _, element := range elements {
+		if element != "" {
+			parts = append(parts, element)
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// get - decode the json response of a GET request into v, returning the url of the next page if any
+func (h *Client) get(endpoint string, v interface{}) (string, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("could not decode %s: %w", endpoint, err)
+	}
+
+	next := ""
+	if match := linkNext.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		next = match[1]
+	}
+
+	return next, nil
+}
+
+// escapeRevision - revision as a single path element, so that refs/pr/1 stays one element
+func escapeRevision(revision string) string {
+	if revision == "" {
+		revision = DefaultRevision
+	}
+
+	return url.PathEscape(revision)
+}
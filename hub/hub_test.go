@@ -0,0 +1,137 @@
+package hub
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const testSHA = "0e9e39f249a16976918f6564b8830bc894c89659"
+
+func newTestHub(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	token := "token"
+	c, _ := client.NewClient(nil, &token)
+	c.HubHost = server.URL
+
+	return New(c)
+}
+
+func TestModelInfo(t *testing.T) {
+	var paths []string
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("expected the client token, got %q", r.Header.Get("Authorization"))
+		}
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"Repository not found"}`)
+			return
+		}
+		io.WriteString(w, `{"id":"meta-llama/Llama-3.1-8B-Instruct","sha":"`+testSHA+`","private":false,"gated":"manual",
+			"siblings":[{"rfilename":"config.json"},{"rfilename":"model.safetensors"}]}`)
+	})
+
+	info, err := h.ModelInfo("meta-llama/Llama-3.1-8B-Instruct", "refs/pr/1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if paths[0] != "/api/models/meta-llama/Llama-3.1-8B-Instruct/revision/refs%2Fpr%2F1" {
+		t.Fatalf("expected the revision as a single path element, got %s", paths[0])
+	}
+	if info.SHA != testSHA || info.Gated != GatedManual || len(info.Siblings) != 2 {
+		t.Fatalf("unexpected model info: %+v", info)
+	}
+
+	sha, err := h.ResolveRevision("meta-llama/Llama-3.1-8B-Instruct", "")
+	if err != nil || sha != testSHA {
+		t.Fatalf("expected %s, got %s (%v)", testSHA, sha, err)
+	}
+	if paths[1] != "/api/models/meta-llama/Llama-3.1-8B-Instruct/revision/main" {
+		t.Fatalf("expected the revision to default to main, got %s", paths[1])
+	}
+
+	if _, err := h.ResolveRevision("org/missing", "main"); !client.IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestGatedMode(t *testing.T) {
+	for body, expected := range map[string]GatedMode{`false`: "", `true`: GatedAuto, `"auto"`: GatedAuto, `"manual"`: GatedManual} {
+		var gated GatedMode
+		if err := gated.UnmarshalJSON([]byte(body)); err != nil || gated != expected {
+			t.Fatalf("expected %s to decode to %q, got %q (%v)", body, expected, gated, err)
+		}
+	}
+}
+
+func TestListRepoFiles(t *testing.T) {
+	var server string
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") != "true" {
+			t.Errorf("expected a recursive listing, got %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Link", `<`+server+r.URL.Path+`?recursive=true&cursor=abc>; rel="next"`)
+			io.WriteString(w, `[{"type":"directory","path":"onnx","oid":"d1","size":0},
+				{"type":"file","path":"config.json","oid":"b1","size":826}]`)
+			return
+		}
+		io.WriteString(w, `[{"type":"file","path":"onnx/model.onnx","oid":"b2","size":1340000000,
+			"lfs":{"oid":"`+strings.Repeat("a", 64)+`","size":1340000000,"pointerSize":135}}]`)
+	})
+	server = h.Client.HubHost
+
+	files, err := h.ListRepoFiles("BAAI/bge-small-en-v1.5", testSHA, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(files) != 2 || files[0].Path != "config.json" || files[1].LFS == nil || files[1].LFS.PointerSize != 135 {
+		t.Fatalf("expected the files of both pages without directories, got %+v", files)
+	}
+}
+
+func TestListRefsAndCommits(t *testing.T) {
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/refs"):
+			io.WriteString(w, `{"branches":[{"name":"main","ref":"refs/heads/main","targetCommit":"`+testSHA+`"}],
+				"tags":[{"name":"v1.0","ref":"refs/tags/v1.0","targetCommit":"`+testSHA+`"}],"converts":[]}`)
+		case strings.Contains(r.URL.Path, "/commits/v1.0"):
+			io.WriteString(w, `[{"id":"`+testSHA+`","title":"Update README.md","authors":[{"user":"osanseviero"}],"date":"2024-07-18T08:56:00.000Z"},
+				{"id":"`+strings.Repeat("1", 40)+`","title":"initial commit","date":"2024-07-01T00:00:00.000Z"}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	refs, err := h.ListRefs("gpt2")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(refs.Branches) != 1 || refs.Tags[0].Name != "v1.0" || refs.Tags[0].TargetCommit != testSHA {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+
+	commits, err := h.ListCommits("gpt2", "v1.0", 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(commits) != 1 || commits[0].ID != testSHA || commits[0].Authors[0].User != "osanseviero" || commits[0].Date.IsZero() {
+		t.Fatalf("expected the latest commit only, got %+v", commits)
+	}
+}
+
+func TestIsCommitSHA(t *testing.T) {
+	if !IsCommitSHA(testSHA) || IsCommitSHA("main") || IsCommitSHA(testSHA[:7]) || IsCommitSHA(strings.ToUpper(testSHA)) {
+		t.Fatal("expected only full lowercase commit ids to be commit shas")
+	}
+}
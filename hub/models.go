@@ -0,0 +1,202 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GatedMode is the access request mode of a gated repository, empty when the repository is not gated
+type GatedMode string
+
+const (
+	GatedAuto   GatedMode = "auto"
+	GatedManual GatedMode = "manual"
+)
+
+// UnmarshalJSON - the api sends false for repositories which are not gated
+func (g *GatedMode) UnmarshalJSON(data []byte) error {
+	var gated interface{}
+	if err := json.Unmarshal(data, &gated); err != nil {
+		return err
+	}
+
+	switch value := gated.(type) {
+	case string:
+		*g = GatedMode(value)
+	case bool:
+		*g = ""
+		if value {
+			*g = GatedAuto
+		}
+	default:
+		*g = ""
+	}
+
+	return nil
+}
+
+// ModelInfo is a model repository at a revision
+type ModelInfo struct {
+	ID     string `json:"id"`
+	Author string `json:"author,omitempty"`
+	// Commit the revision resolved to
+	SHA          string    `json:"sha"`
+	Private      bool      `json:"private"`
+	Gated        GatedMode `json:"gated,omitempty"`
+	Disabled     bool      `json:"disabled,omitempty"`
+	PipelineTag  string    `json:"pipeline_tag,omitempty"`
+	Library      string    `json:"library_name,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	Downloads    int64     `json:"downloads"`
+	Likes        int64     `json:"likes"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastModified time.Time `json:"lastModified"`
+	Siblings     []Sibling `json:"siblings,omitempty"`
+}
+
+// Sibling is a file of a repository as listed in its info
+type Sibling struct {
+	Filename string `json:"rfilename"`
+	Size     int64  `json:"size,omitempty"`
+	BlobID   string `json:"blobId,omitempty"`
+	LFS      *struct {
+		SHA256 string `json:"sha256"`
+		Size   int64  `json:"size"`
+	} `json:"lfs,omitempty"`
+}
+
+// RepoFile is an entry of the tree of a repository
+type RepoFile struct {
+	// file or directory
+	Type string `json:"type"`
+	Path string `json:"path"`
+	// Git object id, the blob id of files
+	OID  string   `json:"oid"`
+	Size int64    `json:"size"`
+	LFS  *LFSInfo `json:"lfs,omitempty"`
+}
+
+// LFSInfo is the large file storage pointer of a file
+type LFSInfo struct {
+	// sha256 of the file content
+	OID         string `json:"oid"`
+	Size        int64  `json:"size"`
+	PointerSize int64  `json:"pointerSize"`
+}
+
+// GitRef is a branch or a tag of a repository
+type GitRef struct {
+	Name         string `json:"name"`
+	Ref          string `json:"ref"`
+	TargetCommit string `json:"targetCommit"`
+}
+
+// GitRefs are the branches, tags and conversion branches of a repository
+type GitRefs struct {
+	Branches []GitRef `json:"branches"`
+	Tags     []GitRef `json:"tags"`
+	Converts []GitRef `json:"converts,omitempty"`
+}
+
+// Commit is a commit of the history of a revision
+type Commit struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+	Authors []struct {
+		User string `json:"user"`
+	} `json:"authors,omitempty"`
+	Date time.Time `json:"date"`
+}
+
+// ModelInfo - Get a model repository at a revision, revision defaults to main
+func (h *Client) ModelInfo(repository, revision string) (*ModelInfo, error) {
+	endpoint := h.apiURL(RepoModel, repository)
+	if revision != "" {
+		endpoint = h.apiURL(RepoModel, repository, "revision", escapeRevision(revision))
+	}
+
+	var info ModelInfo
+	if _, err := h.get(endpoint, &info); err != nil {
+		return nil, fmt.Errorf("could not get model %s: %w", repository, err)
+	}
+
+	return &info, nil
+}
+
+// ListRepoFiles - List the files of a model repository at a revision, recursively from path
+// (the root when empty), following the pages of the tree api
+func (h *Client) ListRepoFiles(repository, revision, path string) ([]RepoFile, error) {
+	endpoint := h.apiURL(RepoModel, repository, "tree", escapeRevision(revision), path) + "?" + url.Values{"recursive": {"true"}}.Encode()
+
+	var files []RepoFile
+	for endpoint != "" {
+		var page []RepoFile
+		next, err := h.get(endpoint, &page)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the files of model %s: %w", repository, err)
+		}
+
+		for _, file := range page {
+			if file.Type == "file" {
+				files = append(files, file)
+			}
+		}
+		endpoint = next
+	}
+
+	return files, nil
+}
+
+// ListRefs - List the branches and tags of a model repository
+func (h *Client) ListRefs(repository string) (*GitRefs, error) {
+	var refs GitRefs
+	if _, err := h.get(h.apiURL(RepoModel, repository, "refs"), &refs); err != nil {
+		return nil, fmt.Errorf("could not list the refs of model %s: %w", repository, err)
+	}
+
+	return &refs, nil
+}
+
+// ListCommits - List the commits of a model repository revision, most recent first,
+// limit bounds the number of commits when positive
+func (h *Client) ListCommits(repository, revision string, limit int) ([]Commit, error) {
+	endpoint := h.apiURL(RepoModel, repository, "commits", escapeRevision(revision))
+
+	var commits []Commit
+	for endpoint != "" {
+		var page []Commit
+		next, err := h.get(endpoint, &page)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the commits of model %s: %w", repository, err)
+		}
+
+		commits = append(commits, page...)
+		if limit > 0 && len(commits) >= limit {
+			return commits[:limit], nil
+		}
+		endpoint = next
+	}
+
+	return commits, nil
+}
+
+// ResolveRevision - commit sha a branch, tag or commit of a model repository points to,
+// revision defaults to main. Pinning an endpoint to it keeps later pushes from changing the model.
+func (h *Client) ResolveRevision(repository, revision string) (string, error) {
+	if revision == "" {
+		revision = DefaultRevision
+	}
+
+	info, err := h.ModelInfo(repository, revision)
+	if err != nil {
+		return "", err
+	}
+	if !IsCommitSHA(info.SHA) {
+		return "", fmt.Errorf("could not resolve revision %s of model %s: got commit %q", revision, repository, info.SHA)
+	}
+
+	return info.SHA, nil
+}
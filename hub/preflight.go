@@ -0,0 +1,131 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+// PreflightError lists the problems of the model of an endpoint which would make its deployment fail
+type PreflightError struct {
+	Repository string
+	Problems   []string
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("model %s: %s", e.Repository, strings.Join(e.Problems, "; "))
+}
+
+// Preflight - check on the hub that the repository of an endpoint model exists and is readable with
+// the client token, gated repositories included, and that its revision and llamacpp model path exist.
+// Problems are returned as a *PreflightError, other errors mean the checks could not complete.
+func (h *Client) Preflight(ctx context.Context, model client.EndpointModel) error {
+	preflight := &PreflightError{Repository: model.Repository}
+	problem := func(format string, args ...interface{}) error {
+		preflight.Problems = append(preflight.Problems, fmt.Sprintf(format, args...))
+		return preflight
+	}
+
+	if model.Repository == "" {
+		return problem("no repository")
+	}
+
+	info, err := h.ModelInfo(model.Repository, "")
+	if err != nil {
+		switch statusCode(err) {
+		case http.StatusUnauthorized, http.StatusNotFound:
+			return problem("the repository does not exist, or is private and the token can not read it")
+		case http.StatusForbidden:
+			return problem("the token can not read the repository")
+		}
+		return err
+	}
+	if info.Disabled {
+		problem("the repository is disabled")
+	}
+
+	revision := ""
+	if model.Revision != nil {
+		revision = *model.Revision
+	}
+	if revision != "" {
+		if _, err := h.ModelInfo(model.Repository, revision); err != nil {
+			switch statusCode(err) {
+			case http.StatusBadRequest, http.StatusNotFound:
+				return problem("revision %s does not exist", revision)
+			}
+			return err
+		}
+	}
+
+	var modelPath string
+	if model.Image.LlamaCpp != nil {
+		modelPath = strings.TrimPrefix(model.Image.LlamaCpp.ModelPath, "/")
+		if modelPath == "" {
+			problem("the llamacpp image has no model path")
+		}
+	}
+
+	// Gated repositories show their info to everyone, reading their files needs granted access
+	probe := modelPath
+	if probe == "" {
+		for _, sibling := range info.Siblings {
+			if probe == "" || sibling.Filename == "config.json" {
+				probe = sibling.Filename
+			}
+		}
+	}
+	if probe != "" {
+		if _, err := h.GetFileMetadata(ctx, model.Repository, revision, probe); err != nil {
+			switch statusCode(err) {
+			case http.StatusNotFound:
+				if probe == modelPath {
+					problem("model path %s does not exist%s", modelPath, suggestFiles(info.Siblings, ".gguf"))
+				}
+			case http.StatusUnauthorized, http.StatusForbidden:
+				if info.Gated != "" {
+					problem("the repository is gated and the token has not been granted access, request it at %s/%s", h.Client.HubBaseURL(), model.Repository)
+				} else {
+					problem("the token can not read the files of the repository")
+				}
+			default:
+				return err
+			}
+		}
+	}
+
+	if len(preflight.Problems) > 0 {
+		return preflight
+	}
+
+	return nil
+}
+
+// suggestFiles - the files of a repository with an extension, as an error suffix
+func suggestFiles(siblings []Sibling, extension string) string {
+	var files []string
+	for _, sibling := range siblings {
+		if strings.HasSuffix(sibling.Filename, extension) {
+			files = append(files, sibling.Filename)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Sprintf(", the repository has no %s file", extension)
+	}
+
+	return fmt.Sprintf(", %s files: %s", extension, strings.Join(files, ", "))
+}
+
+// statusCode - status code of an http error, 0 for other errors
+func statusCode(err error) int {
+	var httpErr *client.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	return 0
+}
@@ -0,0 +1,63 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestPreflight(t *testing.T) {
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/models/org/missing"):
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasSuffix(r.URL.Path, "/revision/v2"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/api/models/meta-llama/"):
+			io.WriteString(w, `{"id":"meta-llama/Llama-3.1-8B-Instruct","sha":"`+testSHA+`","gated":"manual","siblings":[{"rfilename":"config.json"}]}`)
+		case strings.HasPrefix(r.URL.Path, "/api/models/"):
+			io.WriteString(w, `{"id":"org/gguf","sha":"`+testSHA+`","gated":false,"siblings":[{"rfilename":"README.md"},{"rfilename":"model-Q4_K_M.gguf"},{"rfilename":"model-Q8_0.gguf"}]}`)
+		case strings.HasPrefix(r.URL.Path, "/meta-llama/"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/org/gguf/resolve/main/model-Q4_K_M.gguf":
+			w.Header().Set("X-Repo-Commit", testSHA)
+			w.Header().Set("ETag", `"`+strings.Repeat("a", 64)+`"`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	llamacpp := func(path string) client.EndpointModel {
+		return client.EndpointModel{Repository: "org/gguf", Image: client.EndpointModelImage{LlamaCpp: &client.LlamaCppImage{ModelPath: path}}}
+	}
+	v2 := "v2"
+
+	for name, test := range map[string]struct {
+		model    client.EndpointModel
+		expected string
+	}{
+		"valid":    {llamacpp("model-Q4_K_M.gguf"), ""},
+		"missing":  {client.EndpointModel{Repository: "org/missing"}, "does not exist"},
+		"revision": {client.EndpointModel{Repository: "org/gguf", Revision: &v2}, "revision v2 does not exist"},
+		"path":     {llamacpp("model-Q4.gguf"), "model path model-Q4.gguf does not exist, .gguf files: model-Q4_K_M.gguf, model-Q8_0.gguf"},
+		"gated":    {client.EndpointModel{Repository: "meta-llama/Llama-3.1-8B-Instruct"}, "gated and the token has not been granted access"},
+	} {
+		err := h.Preflight(context.Background(), test.model)
+		if test.expected == "" {
+			if err != nil {
+				t.Fatalf("%s: expected no problem, got %v", name, err)
+			}
+			continue
+		}
+
+		var preflight *PreflightError
+		if !errors.As(err, &preflight) || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("%s: expected a problem containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package hub
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+// transferClient - copy of the endpoints client without timeout, for transfers
+// lasting longer than api calls, following redirects when redirects is set
+func (h *Client) transferClient(redirects bool) *client.Client {
+	transfer := &http.Client{}
+	if h.Client.Client != nil {
+		transfer.Transport = h.Client.Client.Transport
+	}
+	if !redirects {
+		transfer.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	c := *h.Client
+	c.Client = transfer
+
+	return &c
+}
+
+// transfer - send a transfer request, authenticated only on the hub host so that
+// the credentials are not sent to the cdn and storage serving lfs files
+func (h *Client) transfer(req *http.Request) (*http.Response, error) {
+	c := h.transferClient(true)
+
+	hub, err := url.Parse(h.Client.HubBaseURL())
+	if err != nil {
+		return nil, err
+	}
+	// Requests carrying their own credentials, as lfs actions, are sent as is
+	if req.URL.Host == hub.Host && req.Header.Get("Authorization") == "" {
+		return c.Do(req)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &client.HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return resp, nil
+}
+
+// progressTracker sums the bytes transferred by the ranges or parts of a file
+type progressTracker struct {
+	mu     sync.Mutex
+	path   string
+	done   int64
+	total  int64
+	report func(path string, done, total int64)
+}
+
+func (t *progressTracker) add(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done += n
+	if t.report != nil {
+		t.report(t.path, t.done, t.total)
+	}
+}
+
+type progressWriter struct {
+	writer  io.Writer
+	tracker *progressTracker
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.tracker.add(int64(n))
+
+	return n, err
+}
+
+// transferBody maps the read errors of a response body, a connection dropped mid-body
+// ending with io.ErrUnexpectedEOF among them, to retryable errors
+type transferBody struct {
+	reader io.Reader
+}
+
+func (b *transferBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = &interruptedError{err: err}
+	}
+
+	return n, err
+}
+
+// interruptedError is a transfer interrupted while reading the body, a net.Error so
+// that client.IsRetryable retries it
+type interruptedError struct {
+	err error
+}
+
+func (e *interruptedError) Error() string   { return "transfer interrupted: " + e.err.Error() }
+func (e *interruptedError) Unwrap() error   { return e.err }
+func (e *interruptedError) Timeout() bool   { return false }
+func (e *interruptedError) Temporary() bool { return true }
+
+// progressReader reports the bytes read from a request body
+type progressReader struct {
+	reader  io.Reader
+	tracker *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.tracker.add(int64(n))
+
+	return n, err
+}
@@ -0,0 +1,679 @@
+package hub
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const (
+	DefaultUploadWorkers      = 4
+	DefaultUploadRetries      = 3
+	DefaultUploadRetryBackoff = time.Second
+	// Files per preupload and lfs batch request, as huggingface_hub
+	uploadBatchSize = 256
+	// Bytes of a file sent to the hub to decide whether it is stored with lfs
+	uploadSampleSize = 512
+	lfsContentType   = "application/vnd.git-lfs+json"
+)
+
+// CreateRepoOptions configures the creation of a model repository
+type CreateRepoOptions struct {
+	Private bool
+	// Succeed when the repository already exists
+	ExistOK bool
+}
+
+// CommitOperation adds, replaces or deletes a file of a repository
+type CommitOperation struct {
+	// Path of the file in the repository
+	Path string
+	// Local file uploaded to Path
+	LocalPath string
+	// Content uploaded to Path when LocalPath is empty
+	Content []byte
+	// Delete Path instead of uploading to it
+	Delete bool
+}
+
+// CommitOptions configures a commit
+type CommitOptions struct {
+	// Branch the commit is pushed to, defaults to main
+	Revision string
+	// Commit summary
+	Message     string
+	Description string
+	// Fail when the branch moved past this commit
+	ParentCommit string
+	// Number of lfs files uploaded in parallel
+	Workers int
+	// Number of retries of a failed lfs transfer when the error is retryable
+	Retries      int
+	RetryBackoff time.Duration
+	// Called as the bytes of lfs files are sent, from one goroutine at a time per file
+	Progress func(UploadProgress)
+}
+
+// UploadProgress is the state of the upload of a lfs file
+type UploadProgress struct {
+	Path     string
+	Uploaded int64
+	Total    int64
+}
+
+// CommitInfo is a commit created on the hub
+type CommitInfo struct {
+	URL            string `json:"commitUrl"`
+	OID            string `json:"commitOid"`
+	PullRequestURL string `json:"pullRequestUrl,omitempty"`
+}
+
+// CreateRepo - Create a model repository, returning its url
+func (h *Client) CreateRepo(repository string, options CreateRepoOptions) (string, error) {
+	payload := map[string]interface{}{"name": repository, "private": options.Private}
+	if slash := strings.Index(repository, "/"); slash >= 0 {
+		payload["organization"], payload["name"] = repository[:slash], repository[slash+1:]
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		URL string `json:"url"`
+	}
+	err = h.post(context.Background(), h.Client.HubBaseURL()+"/api/repos/create", "application/json", bytes.NewReader(body), &result)
+	if err != nil {
+		var httpErr *client.HTTPError
+		if options.ExistOK && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusConflict {
+			return h.Client.HubBaseURL() + "/" + repository, nil
+		}
+		return "", fmt.Errorf("could not create model %s: %w", repository, err)
+	}
+
+	return result.URL, nil
+}
+
+// UploadFile - Upload a local file to a path of a model repository in a single commit
+func (h *Client) UploadFile(ctx context.Context, repository, localPath, pathInRepo string, options CommitOptions) (*CommitInfo, error) {
+	if options.Message == "" {
+		options.Message = "Upload " + pathInRepo
+	}
+
+	return h.CreateCommit(ctx, repository, []CommitOperation{{Path: pathInRepo, LocalPath: localPath}}, options)
+}
+
+// FolderOperations - operations uploading the files of a local folder under pathInRepo,
+// skipping .git directories and the files matching an exclude pattern (path.Match on the relative path)
+func FolderOperations(folder, pathInRepo string, exclude []string) ([]CommitOperation, error) {
+	var operations []CommitOperation
+	err := filepath.Walk(folder, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(folder, localPath)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		if info.IsDir() {
+			if info.Name() == ".git" || relative == ".cache/huggingface" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, pattern := range exclude {
+			if matched, _ := path.Match(pattern, relative); matched {
+				return nil
+			}
+		}
+
+		operations = append(operations, CommitOperation{Path: path.Join(pathInRepo, relative), LocalPath: localPath})
+		return nil
+	})
+
+	return operations, err
+}
+
+// commitFile is an uploaded file of a commit
+type commitFile struct {
+	operation CommitOperation
+	size      int64
+	sample    []byte
+	// lfs or regular, decided by the hub
+	mode string
+	// sha256 of the content of lfs files
+	oid    string
+	ignore bool
+}
+
+// CreateCommit - Create a commit adding, replacing and deleting files of a model repository.
+// The hub decides which files are stored with lfs, they are uploaded to the lfs storage first,
+// in parts for large files, then the commit references them along with the content of the regular files.
+func (h *Client) CreateCommit(ctx context.Context, repository string, operations []CommitOperation, options CommitOptions) (*CommitInfo, error) {
+	if options.Revision == "" {
+		options.Revision = DefaultRevision
+	}
+	if options.Message == "" {
+		return nil, fmt.Errorf("a commit message is required")
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("a commit needs at least one operation")
+	}
+	if options.Workers <= 0 {
+		options.Workers = DefaultUploadWorkers
+	}
+	if options.Retries <= 0 {
+		options.Retries = DefaultUploadRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultUploadRetryBackoff
+	}
+
+	var files []*commitFile
+	for _, operation := range operations {
+		if operation.Delete {
+			continue
+		}
+
+		file, err := sampleFile(operation)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	for start := 0; start < len(files); start += uploadBatchSize {
+		end := start + uploadBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+		if err := h.preupload(ctx, repository, options.Revision, files[start:end]); err != nil {
+			return nil, fmt.Errorf("could not commit to model %s: %w", repository, err)
+		}
+	}
+
+	var lfsFiles []*commitFile
+	for _, file := range files {
+		if file.mode == "lfs" && !file.ignore {
+			if file.oid == "" {
+				oid, err := hashFile(file.operation)
+				if err != nil {
+					return nil, err
+				}
+				file.oid = oid
+			}
+			lfsFiles = append(lfsFiles, file)
+		}
+	}
+
+	for start := 0; start < len(lfsFiles); start += uploadBatchSize {
+		end := start + uploadBatchSize
+		if end > len(lfsFiles) {
+			end = len(lfsFiles)
+		}
+		if err := h.uploadLFS(ctx, repository, options, lfsFiles[start:end]); err != nil {
+			return nil, fmt.Errorf("could not commit to model %s: %w", repository, err)
+		}
+	}
+
+	payload, err := commitPayload(operations, files, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var commit CommitInfo
+	endpoint := h.apiURL(RepoModel, repository, "commit", escapeRevision(options.Revision))
+	if err := h.post(ctx, endpoint, "application/x-ndjson", bytes.NewReader(payload), &commit); err != nil {
+		return nil, fmt.Errorf("could not commit to model %s: %w", repository, err)
+	}
+
+	return &commit, nil
+}
+
+// sampleFile - size and first bytes of the file of an operation, for the preupload
+func sampleFile(operation CommitOperation) (*commitFile, error) {
+	source, err := openOperation(operation)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	size, err := source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := make([]byte, uploadSampleSize)
+	n, err := source.ReadAt(sample, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &commitFile{operation: operation, size: size, sample: sample[:n]}, nil
+}
+
+// hashFile - sha256 of the content of an operation, the oid of lfs objects
+func hashFile(operation CommitOperation) (string, error) {
+	source, err := openOperation(operation)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, source); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// operationSource is the content of an operation, a local file or bytes
+type operationSource interface {
+	io.ReadSeeker
+	io.ReaderAt
+	io.Closer
+}
+
+type bytesSource struct {
+	*bytes.Reader
+}
+
+func (bytesSource) Close() error {
+	return nil
+}
+
+func openOperation(operation CommitOperation) (operationSource, error) {
+	if operation.LocalPath == "" {
+		return bytesSource{bytes.NewReader(operation.Content)}, nil
+	}
+
+	return os.Open(operation.LocalPath)
+}
+
+// preupload - ask the hub which files are stored with lfs and which are ignored
+func (h *Client) preupload(ctx context.Context, repository, revision string, files []*commitFile) error {
+	type preuploadFile struct {
+		Path   string `json:"path"`
+		Sample string `json:"sample"`
+		Size   int64  `json:"size"`
+	}
+	request := struct {
+		Files []preuploadFile `json:"files"`
+	}{}
+	for _, file := range files {
+		request.Files = append(request.Files, preuploadFile{
+			Path:   file.operation.Path,
+			Sample: base64.StdEncoding.EncodeToString(file.sample),
+			Size:   file.size,
+		})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Files []struct {
+			Path         string `json:"path"`
+			UploadMode   string `json:"uploadMode"`
+			ShouldIgnore bool   `json:"shouldIgnore"`
+		} `json:"files"`
+	}
+	endpoint := h.apiURL(RepoModel, repository, "preupload", escapeRevision(revision))
+	if err := h.post(ctx, endpoint, "application/json", bytes.NewReader(body), &result); err != nil {
+		return err
+	}
+
+	modes := map[string]int{}
+	for i, file := range result.Files {
+		modes[file.Path] = i
+	}
+	for _, file := range files {
+		i, ok := modes[file.operation.Path]
+		if !ok {
+			return fmt.Errorf("no upload mode for %s", file.operation.Path)
+		}
+		file.mode = result.Files[i].UploadMode
+		file.ignore = result.Files[i].ShouldIgnore
+	}
+
+	return nil
+}
+
+// lfsAction is a request of the lfs batch api
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+// uploadLFS - upload the lfs files missing from the lfs storage, through the lfs batch api,
+// with a single request or in parts as the storage asks
+func (h *Client) uploadLFS(ctx context.Context, repository string, options CommitOptions, files []*commitFile) error {
+	type lfsObject struct {
+		OID  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+	request := struct {
+		Operation string            `json:"operation"`
+		Transfers []string          `json:"transfers"`
+		Objects   []lfsObject       `json:"objects"`
+		HashAlgo  string            `json:"hash_algo"`
+		Ref       map[string]string `json:"ref"`
+	}{
+		Operation: "upload",
+		Transfers: []string{"basic", "multipart"},
+		HashAlgo:  "sha256",
+		Ref:       map[string]string{"name": options.Revision},
+	}
+	byOID := map[string]*commitFile{}
+	for _, file := range files {
+		if _, ok := byOID[file.oid]; !ok {
+			request.Objects = append(request.Objects, lfsObject{OID: file.oid, Size: file.size})
+		}
+		byOID[file.oid] = file
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Transfer string `json:"transfer"`
+		Objects  []struct {
+			OID     string `json:"oid"`
+			Actions *struct {
+				Upload *lfsAction `json:"upload"`
+				Verify *lfsAction `json:"verify"`
+			} `json:"actions"`
+			Error *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"objects"`
+	}
+	endpoint := fmt.Sprintf("%s/%s.git/info/lfs/objects/batch", h.Client.HubBaseURL(), repository)
+	if err := h.post(ctx, endpoint, lfsContentType, bytes.NewReader(body), &result); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan func() error)
+	errs := make(chan error, len(result.Objects))
+	var wg sync.WaitGroup
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := job(); err != nil {
+					errs <- err
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for _, object := range result.Objects {
+		object := object
+		file, ok := byOID[object.OID]
+		if !ok {
+			errs <- fmt.Errorf("the lfs batch answered with object %s which was not requested", object.OID)
+			break
+		}
+		if object.Error != nil {
+			errs <- fmt.Errorf("could not upload %s: lfs error %d: %s", file.operation.Path, object.Error.Code, object.Error.Message)
+			break
+		}
+		// Objects already in the storage have no actions
+		if object.Actions == nil || object.Actions.Upload == nil {
+			continue
+		}
+
+		job := func() error {
+			err := h.uploadObject(ctx, file, object.Actions.Upload, result.Transfer, options)
+			if err == nil && object.Actions.Verify != nil {
+				err = h.verifyObject(ctx, file, object.Actions.Verify)
+			}
+			if err != nil {
+				return fmt.Errorf("could not upload %s: %w", file.operation.Path, err)
+			}
+			return nil
+		}
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// uploadObject - send the content of a lfs file to the storage, in parts for the multipart transfer
+func (h *Client) uploadObject(ctx context.Context, file *commitFile, upload *lfsAction, transfer string, options CommitOptions) error {
+	source, err := openOperation(file.operation)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tracker := &progressTracker{path: file.operation.Path, total: file.size}
+	if options.Progress != nil {
+		tracker.report = func(path string, done, total int64) {
+			options.Progress(UploadProgress{Path: path, Uploaded: done, Total: total})
+		}
+	}
+
+	chunkSize, _ := strconv.ParseInt(upload.Header["chunk_size"], 10, 64)
+	if transfer != "multipart" || chunkSize <= 0 {
+		return client.Retry(ctx, options.Retries, options.RetryBackoff, func() error {
+			tracker.add(-tracker.done)
+			_, err := h.put(ctx, upload.Href, upload.Header, io.NewSectionReader(source, 0, file.size), file.size, tracker)
+			return err
+		})
+	}
+
+	// The header holds the chunk size and the url of each part, keyed by part number
+	var numbers []int
+	for key := range upload.Header {
+		if number, err := strconv.Atoi(key); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	type completedPart struct {
+		PartNumber int    `json:"partNumber"`
+		ETag       string `json:"etag"`
+	}
+	completion := struct {
+		OID   string          `json:"oid"`
+		Parts []completedPart `json:"parts"`
+	}{OID: file.oid}
+
+	for _, number := range numbers {
+		offset := int64(number-1) * chunkSize
+		length := chunkSize
+		if offset+length > file.size {
+			length = file.size - offset
+		}
+
+		var etag string
+		uploaded := tracker.done
+		err := client.Retry(ctx, options.Retries, options.RetryBackoff, func() error {
+			tracker.add(uploaded - tracker.done)
+			header, err := h.put(ctx, upload.Header[strconv.Itoa(number)], nil, io.NewSectionReader(source, offset, length), length, tracker)
+			etag = header.Get("ETag")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("part %d: %w", number, err)
+		}
+		completion.Parts = append(completion.Parts, completedPart{PartNumber: number, ETag: etag})
+	}
+
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+
+	return h.post(ctx, upload.Href, lfsContentType, bytes.NewReader(body), nil)
+}
+
+// verifyObject - ask the hub to check a lfs file reached the storage
+func (h *Client) verifyObject(ctx context.Context, file *commitFile, verify *lfsAction) error {
+	body, err := json.Marshal(map[string]interface{}{"oid": file.oid, "size": file.size})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", verify.Href, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range verify.Header {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", lfsContentType)
+
+	resp, err := h.transfer(req)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// put - send length bytes of body to a storage url, returning the response headers
+func (h *Client) put(ctx context.Context, href string, header map[string]string, body io.Reader, length int64, tracker *progressTracker) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", href, &progressReader{reader: body, tracker: tracker})
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = length
+	for key, value := range header {
+		if key == "chunk_size" {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	resp, err := h.transfer(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.Header, nil
+}
+
+// post - send body to the hub and decode the json response into v when not nil
+func (h *Client) post(ctx context.Context, endpoint, contentType string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if contentType == lfsContentType {
+		req.Header.Set("Accept", lfsContentType)
+	}
+
+	resp, err := h.transfer(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// commitPayload - ndjson commit request: a header line, then a line per regular file
+// with its content, lfs file with its oid and deleted file
+func commitPayload(operations []CommitOperation, files []*commitFile, options CommitOptions) ([]byte, error) {
+	var payload bytes.Buffer
+	writer := bufio.NewWriter(&payload)
+	encoder := json.NewEncoder(writer)
+
+	header := map[string]string{"summary": options.Message, "description": options.Description}
+	if options.ParentCommit != "" {
+		header["parentCommit"] = options.ParentCommit
+	}
+	if err := encoder.Encode(map[string]interface{}{"key": "header", "value": header}); err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.ignore {
+			continue
+		}
+
+		if file.mode == "lfs" {
+			value := map[string]interface{}{"path": file.operation.Path, "algo": "sha256", "oid": file.oid, "size": file.size}
+			if err := encoder.Encode(map[string]interface{}{"key": "lfsFile", "value": value}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		source, err := openOperation(file.operation)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(source)
+		source.Close()
+		if err != nil {
+			return nil, err
+		}
+		value := map[string]string{"path": file.operation.Path, "content": base64.StdEncoding.EncodeToString(content), "encoding": "base64"}
+		if err := encoder.Encode(map[string]interface{}{"key": "file", "value": value}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, operation := range operations {
+		if operation.Delete {
+			if err := encoder.Encode(map[string]interface{}{"key": "deletedFile", "value": map[string]string{"path": operation.Path}}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	return payload.Bytes(), nil
+}
@@ -0,0 +1,193 @@
+package hub
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCreateRepo(t *testing.T) {
+	var payload map[string]interface{}
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["name"] == "existing" {
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"error":"You already created this model repo"}`)
+			return
+		}
+		io.WriteString(w, `{"url":"https://huggingface.co/org/fine-tuned"}`)
+	})
+
+	url, err := h.CreateRepo("org/fine-tuned", CreateRepoOptions{Private: true})
+	if err != nil || url != "https://huggingface.co/org/fine-tuned" {
+		t.Fatalf("expected the repository url, got %s (%v)", url, err)
+	}
+	if payload["organization"] != "org" || payload["name"] != "fine-tuned" || payload["private"] != true {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+
+	if _, err := h.CreateRepo("org/existing", CreateRepoOptions{}); err == nil {
+		t.Fatal("expected an error for an existing repository")
+	}
+	if _, err := h.CreateRepo("org/existing", CreateRepoOptions{ExistOK: true}); err != nil {
+		t.Fatalf("expected an existing repository to be accepted, got %v", err)
+	}
+}
+
+func TestCreateCommit(t *testing.T) {
+	folder := t.TempDir()
+	weights := bytes.Repeat([]byte{1, 2, 3, 4, 5}, 2000)
+	os.MkdirAll(filepath.Join(folder, ".git"), 0755)
+	os.WriteFile(filepath.Join(folder, ".git", "HEAD"), []byte("ref"), 0644)
+	os.WriteFile(filepath.Join(folder, "config.json"), []byte(`{"model_type":"llama"}`), 0644)
+	os.WriteFile(filepath.Join(folder, "model.safetensors"), weights, 0644)
+	os.WriteFile(filepath.Join(folder, "adapter.bin"), []byte("small lfs file"), 0644)
+	os.WriteFile(filepath.Join(folder, "train.log"), []byte("loss"), 0644)
+
+	operations, err := FolderOperations(folder, "checkpoint", []string{"*.log"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(operations) != 3 {
+		t.Fatalf("expected the files without .git and logs, got %+v", operations)
+	}
+	operations = append(operations, CommitOperation{Path: "old.bin", Delete: true})
+
+	var mu sync.Mutex
+	var server string
+	var parts = map[string][]byte{}
+	var commit []map[string]interface{}
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+
+		switch {
+		case r.URL.Path == "/api/models/org/fine-tuned/preupload/main":
+			var request struct {
+				Files []struct {
+					Path string `json:"path"`
+					Size int64  `json:"size"`
+				} `json:"files"`
+			}
+			json.Unmarshal(body, &request)
+			var files []string
+			for _, file := range request.Files {
+				mode := "regular"
+				if !strings.HasSuffix(file.Path, ".json") {
+					mode = "lfs"
+				}
+				files = append(files, `{"path":"`+file.Path+`","uploadMode":"`+mode+`","shouldIgnore":false}`)
+			}
+			io.WriteString(w, `{"files":[`+strings.Join(files, ",")+`]}`)
+		case r.URL.Path == "/org/fine-tuned.git/info/lfs/objects/batch":
+			if r.Header.Get("Accept") != lfsContentType {
+				t.Errorf("expected the lfs content type, got %s", r.Header.Get("Accept"))
+			}
+			var request struct {
+				Objects []struct {
+					OID  string `json:"oid"`
+					Size int64  `json:"size"`
+				} `json:"objects"`
+			}
+			json.Unmarshal(body, &request)
+			var objects []string
+			for _, object := range request.Objects {
+				if object.Size == 10000 {
+					objects = append(objects, `{"oid":"`+object.OID+`","size":10000,"actions":{
+						"upload":{"href":"`+server+`/complete","header":{"chunk_size":"4000","1":"`+server+`/part/1","2":"`+server+`/part/2","3":"`+server+`/part/3"}},
+						"verify":{"href":"`+server+`/verify","header":{"Authorization":"Basic lfs"}}}}`)
+				} else {
+					// Already in the storage
+					objects = append(objects, `{"oid":"`+object.OID+`","size":`+"14"+`}`)
+				}
+			}
+			io.WriteString(w, `{"transfer":"multipart","objects":[`+strings.Join(objects, ",")+`]}`)
+		case strings.HasPrefix(r.URL.Path, "/part/"):
+			parts[r.URL.Path] = body
+			w.Header().Set("ETag", `"etag-`+strings.TrimPrefix(r.URL.Path, "/part/")+`"`)
+		case r.URL.Path == "/complete":
+			if !strings.Contains(string(body), `{"partNumber":3,"etag":"\"etag-3\""}`) {
+				t.Errorf("unexpected completion %s", body)
+			}
+		case r.URL.Path == "/verify":
+			if r.Header.Get("Authorization") != "Basic lfs" {
+				t.Errorf("expected the verify header, got %s", r.Header.Get("Authorization"))
+			}
+		case r.URL.Path == "/api/models/org/fine-tuned/commit/main":
+			if r.Header.Get("Content-Type") != "application/x-ndjson" {
+				t.Errorf("expected ndjson, got %s", r.Header.Get("Content-Type"))
+			}
+			scanner := bufio.NewScanner(bytes.NewReader(body))
+			for scanner.Scan() {
+				var line map[string]interface{}
+				json.Unmarshal(scanner.Bytes(), &line)
+				commit = append(commit, line)
+			}
+			io.WriteString(w, `{"commitUrl":"https://huggingface.co/org/fine-tuned/commit/`+testSHA+`","commitOid":"`+testSHA+`"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = h.Client.HubHost
+
+	var progress []UploadProgress
+	info, err := h.CreateCommit(context.Background(), "org/fine-tuned", operations, CommitOptions{
+		Message:  "Upload checkpoint",
+		Progress: func(p UploadProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.OID != testSHA {
+		t.Fatalf("unexpected commit %+v", info)
+	}
+
+	if !bytes.Equal(append(append(parts["/part/1"], parts["/part/2"]...), parts["/part/3"]...), weights) || len(parts["/part/3"]) != 2000 {
+		t.Fatal("expected the weights to be uploaded in 3 parts")
+	}
+	if last := progress[len(progress)-1]; last.Path != "checkpoint/model.safetensors" || last.Uploaded != 10000 {
+		t.Fatalf("unexpected progress %+v", last)
+	}
+
+	keys := make([]string, len(commit))
+	for i, line := range commit {
+		value, _ := line["value"].(map[string]interface{})
+		path, _ := value["path"].(string)
+		keys[i] = line["key"].(string) + ":" + path
+	}
+	if strings.Join(keys, " ") != "header: lfsFile:checkpoint/adapter.bin file:checkpoint/config.json lfsFile:checkpoint/model.safetensors deletedFile:old.bin" {
+		t.Fatalf("unexpected commit operations %v", keys)
+	}
+	if value := commit[0]["value"].(map[string]interface{}); value["summary"] != "Upload checkpoint" {
+		t.Fatalf("unexpected commit header %v", value)
+	}
+}
+
+func TestCreateCommitUnknownLFSObject(t *testing.T) {
+	var committed bool
+	h := newTestHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/preupload/main"):
+			io.WriteString(w, `{"files":[{"path":"model.bin","uploadMode":"lfs"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/info/lfs/objects/batch"):
+			io.WriteString(w, `{"objects":[{"oid":"`+strings.Repeat("0", 64)+`","size":5,"actions":{"upload":{"href":"/upload"}}}]}`)
+		default:
+			committed = true
+		}
+	})
+
+	_, err := h.CreateCommit(context.Background(), "org/model", []CommitOperation{{Path: "model.bin", Content: []byte("bytes")}}, CommitOptions{Message: "Upload model"})
+	if err == nil || !strings.Contains(err.Error(), "was not requested") || committed {
+		t.Fatalf("expected an error for an object which was not requested, got %v", err)
+	}
+}
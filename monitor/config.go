@@ -0,0 +1,214 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebps/huggingface-client/utils"
+)
+
+// Duration is a time.Duration read from json as "5m" or as a number of seconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration: %s", string(data))
+	}
+	*d = Duration(seconds * float64(time.Second))
+
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config is the content of a rules file
+type Config struct {
+	Namespace string `json:"namespace"`
+	// Endpoints to watch, all the endpoints of the namespace when empty
+	Endpoints []string `json:"endpoints,omitempty"`
+	// Interval between two evaluations, one minute when unset
+	Interval  Duration         `json:"interval,omitempty"`
+	Rules     []Rule           `json:"rules"`
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+}
+
+// Rule raises an alert for each endpoint its expression holds on for the For duration.
+// Expressions compare a metric (server-error-rate) or a status field (state, readyReplica,
+// targetReplica, minReplica, maxReplica) with a number, a word or another operand:
+// "server-error-rate > 0.05", "state == failed", "readyReplica < targetReplica".
+type Rule struct {
+	Name     string   `json:"name"`
+	Expr     string   `json:"expr"`
+	For      Duration `json:"for,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	// Endpoints the rule applies to, all the watched endpoints when empty
+	Endpoints []string `json:"endpoints,omitempty"`
+	// Aggregation of metrics made of several series (e.g. per replica): max, min, sum or avg
+	Aggregate string `json:"aggregate,omitempty"`
+
+	condition *condition
+}
+
+// NotifierConfig configures the delivery of alerts
+type NotifierConfig struct {
+	// stdout, file or webhook
+	Type string `json:"type"`
+	// Log file of the file notifier
+	Path string `json:"path,omitempty"`
+	// Target of the webhook notifier
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// text/template rendering the message or payload from the Alert
+	Template string `json:"template,omitempty"`
+	// Alert states delivered, firing and resolved when empty
+	States []AlertState `json:"states,omitempty"`
+}
+
+// Statuses fields usable in rule expressions
+var statusFields = map[string]bool{
+	"state":         true,
+	"readyReplica":  true,
+	"targetReplica": true,
+	"minReplica":    true,
+	"maxReplica":    true,
+}
+
+var operators = map[string]bool{">": true, ">=": true, "<": true, "<=": true, "==": true, "!=": true}
+
+type operand struct {
+	metric string
+	field  string
+	number *float64
+	text   string
+}
+
+type condition struct {
+	left     operand
+	operator string
+	right    operand
+}
+
+// LoadConfig - read a rules file, validated by Validate once the command line overrides are applied
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate - check the rules and parse their expressions
+func (c *Config) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("namespace is required")
+	}
+	if len(c.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+
+	names := map[string]bool{}
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicated rule name %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		cond, err := parseExpression(rule.Expr)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		rule.condition = cond
+
+		switch rule.Aggregate {
+		case "", "max", "min", "sum", "avg":
+		default:
+			return fmt.Errorf("rule %s: invalid aggregate %s", rule.Name, rule.Aggregate)
+		}
+	}
+
+	for _, notifier := range c.Notifiers {
+		switch notifier.Type {
+		case "stdout":
+		case "file":
+			if notifier.Path == "" {
+				return errors.New("file notifier requires a path")
+			}
+		case "webhook":
+			if notifier.URL == "" {
+				return errors.New("webhook notifier requires a url")
+			}
+		default:
+			return fmt.Errorf("unsupported notifier type %s", notifier.Type)
+		}
+	}
+
+	return nil
+}
+
+// parseExpression - parse "<operand> <operator> <operand>"
+func parseExpression(expr string) (*condition, error) {
+	tokens := strings.Fields(expr)
+	if len(tokens) != 3 {
+		return nil, fmt.Errorf("invalid expression %q, expected <operand> <operator> <operand>", expr)
+	}
+
+	if !operators[tokens[1]] {
+		return nil, fmt.Errorf("invalid operator %s", tokens[1])
+	}
+
+	left := parseOperand(tokens[0])
+	if left.metric == "" && left.field == "" {
+		return nil, fmt.Errorf("left side of %q must be a metric or a status field", expr)
+	}
+
+	cond := &condition{left: left, operator: tokens[1], right: parseOperand(tokens[2])}
+
+	numeric := cond.left.field != "state" && (cond.right.number != nil || cond.right.metric != "" || (cond.right.field != "" && cond.right.field != "state"))
+	if !numeric && cond.operator != "==" && cond.operator != "!=" {
+		return nil, fmt.Errorf("operator %s needs numeric operands in %q", cond.operator, expr)
+	}
+
+	return cond, nil
+}
+
+func parseOperand(token string) operand {
+	if number, err := strconv.ParseFloat(token, 64); err == nil {
+		return operand{number: &number}
+	}
+	if utils.IsMetricValid(token) {
+		return operand{metric: token}
+	}
+	if statusFields[token] {
+		return operand{field: token}
+	}
+
+	return operand{text: strings.Trim(token, `"'`)}
+}
@@ -0,0 +1,375 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const (
+	DefaultInterval = time.Minute
+	// Lookback of metric queries, the last point of the window is evaluated
+	DefaultWindow = 5 * time.Minute
+	// Interval between two inspections of the idle reaper
+	DefaultReaperInterval = 10 * time.Minute
+)
+
+type AlertState string
+
+const (
+	// The condition holds, for less than the rule For duration
+	AlertPending AlertState = "pending"
+	// The condition holds for at least the rule For duration
+	AlertFiring AlertState = "firing"
+	// The condition stopped holding after the alert fired
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert is the state of a rule for an endpoint
+type Alert struct {
+	Rule      string     `json:"rule"`
+	Expr      string     `json:"expr"`
+	Severity  string     `json:"severity,omitempty"`
+	Namespace string     `json:"namespace"`
+	Endpoint  string     `json:"endpoint"`
+	State     AlertState `json:"state"`
+	// Value of the left side of the expression at the last evaluation
+	Value      string     `json:"value"`
+	Since      time.Time  `json:"since"`
+	FiredAt    *time.Time `json:"firedAt,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	Summary    string     `json:"summary"`
+}
+
+type alertKey struct {
+	rule     string
+	endpoint string
+}
+
+// Monitor evaluates the rules of a config against the endpoints of its namespace
+// and delivers the alert transitions to the notifiers
+type Monitor struct {
+	Client    *client.Client
+	Config    *Config
+	Notifiers []Notifier
+	Window    time.Duration
+
+	alerts map[alertKey]*Alert
+	now    func() time.Time
+}
+
+// New - monitor of a validated config, with the notifiers it declares (stdout when none)
+func New(c *client.Client, config *Config) (*Monitor, error) {
+	configs := config.Notifiers
+	if len(configs) == 0 {
+		configs = []NotifierConfig{{Type: "stdout"}}
+	}
+
+	var notifiers []Notifier
+	for _, notifierConfig := range configs {
+		notifier, err := NewNotifier(notifierConfig)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	return &Monitor{
+		Client:    c,
+		Config:    config,
+		Notifiers: notifiers,
+		Window:    DefaultWindow,
+		alerts:    map[alertKey]*Alert{},
+		now:       time.Now,
+	}, nil
+}
+
+// Run - evaluate every config interval until the context is done, errors are
+// reported to onError and do not stop the monitor
+func (m *Monitor) Run(ctx context.Context, onError func(error)) {
+	interval := time.Duration(m.Config.Interval)
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Evaluate(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Alerts - pending and firing alerts, sorted by rule and endpoint
+func (m *Monitor) Alerts() []Alert {
+	var alerts []Alert
+	for _, alert := range m.alerts {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].Endpoint < alerts[j].Endpoint
+	})
+
+	return alerts
+}
+
+// Evaluate - evaluate all the rules once, notify and return the alert transitions
+func (m *Monitor) Evaluate(ctx context.Context) ([]Alert, error) {
+	endpoints, err := m.Client.ListEndpoints(m.Config.Namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	metrics := map[string][]client.MetricSeries{}
+	var errs []error
+
+	// metric - series of an endpoint metric, fetched once per evaluation
+	metric := func(endpoint, name string) []client.MetricSeries {
+		key := endpoint + "/" + name
+		if series, ok := metrics[key]; ok {
+			return series
+		}
+
+		step := "1m"
+		series, err := m.Client.GetEndpointMetricSeries(m.Config.Namespace, endpoint, name, now.Add(-m.Window), now, &step)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not fetch %s of %s: %w", name, endpoint, err))
+		}
+		metrics[key] = series
+
+		return series
+	}
+
+	var transitions []Alert
+	for _, rule := range m.Config.Rules {
+		for _, endpoint := range endpoints {
+			if ctx.Err() != nil {
+				return transitions, ctx.Err()
+			}
+			if !selected(m.Config.Endpoints, endpoint.Name) || !selected(rule.Endpoints, endpoint.Name) {
+				continue
+			}
+
+			holds, value, ok := rule.evaluate(endpoint, func(name string) []client.MetricSeries {
+				return metric(endpoint.Name, name)
+			})
+			if !ok {
+				// Without data the alert keeps its state
+				continue
+			}
+
+			if alert := m.transition(rule, endpoint.Name, holds, value, now); alert != nil {
+				transitions = append(transitions, *alert)
+			}
+		}
+	}
+
+	for _, alert := range transitions {
+		for _, notifier := range m.Notifiers {
+			if err := notifier.Notify(ctx, alert); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return transitions, fmt.Errorf("%d errors during evaluation, first: %w", len(errs), errs[0])
+	}
+
+	return transitions, nil
+}
+
+// transition - update the alert of a rule for an endpoint, the alert is returned
+// when its state changed
+func (m *Monitor) transition(rule Rule, endpoint string, holds bool, value string, now time.Time) *Alert {
+	key := alertKey{rule: rule.Name, endpoint: endpoint}
+	alert, exists := m.alerts[key]
+
+	if !holds {
+		if !exists {
+			return nil
+		}
+		delete(m.alerts, key)
+		if alert.State != AlertFiring {
+			return nil
+		}
+		alert.State = AlertResolved
+		alert.Value = value
+		alert.ResolvedAt = &now
+		alert.Summary = summary(rule, endpoint, value, alert.State)
+		return alert
+	}
+
+	if !exists {
+		alert = &Alert{
+			Rule:      rule.Name,
+			Expr:      rule.Expr,
+			Severity:  rule.Severity,
+			Namespace: m.Config.Namespace,
+			Endpoint:  endpoint,
+			Since:     now,
+		}
+		m.alerts[key] = alert
+	}
+	alert.Value = value
+
+	state := AlertPending
+	if now.Sub(alert.Since) >= time.Duration(rule.For) {
+		state = AlertFiring
+	}
+	if exists && state == alert.State {
+		return nil
+	}
+
+	alert.State = state
+	if state == AlertFiring {
+		alert.FiredAt = &now
+	}
+	alert.Summary = summary(rule, endpoint, value, state)
+	transition := *alert
+
+	return &transition
+}
+
+func summary(rule Rule, endpoint, value string, state AlertState) string {
+	return fmt.Sprintf("[%s] %s on %s: %s (value %s)", state, rule.Name, endpoint, rule.Expr, value)
+}
+
+func selected(names []string, name string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluate - whether the rule holds for the endpoint, with the value of the left
+// operand, false when a metric has no data
+func (r Rule) evaluate(endpoint client.EndpointWithStatus, metric func(string) []client.MetricSeries) (bool, string, bool) {
+	left, ok := r.resolve(r.condition.left, endpoint, metric)
+	if !ok {
+		return false, "", false
+	}
+	right, ok := r.resolve(r.condition.right, endpoint, metric)
+	if !ok {
+		return false, left.String(), false
+	}
+
+	return compare(left, r.condition.operator, right), left.String(), true
+}
+
+// value is a resolved operand, numeric unless text is set
+type value struct {
+	number float64
+	text   *string
+}
+
+func (v value) String() string {
+	if v.text != nil {
+		return *v.text
+	}
+	return strconv.FormatFloat(v.number, 'g', 6, 64)
+}
+
+func (r Rule) resolve(op operand, endpoint client.EndpointWithStatus, metric func(string) []client.MetricSeries) (value, bool) {
+	switch {
+	case op.number != nil:
+		return value{number: *op.number}, true
+	case op.metric != "":
+		number, ok := aggregate(metric(op.metric), r.Aggregate)
+		return value{number: number}, ok
+	case op.field == "state":
+		state := string(endpoint.Status.State)
+		return value{text: &state}, true
+	case op.field == "readyReplica":
+		return value{number: float64(endpoint.Status.ReadyReplica)}, true
+	case op.field == "targetReplica":
+		return value{number: float64(endpoint.Status.TargetReplica)}, true
+	case op.field == "minReplica":
+		return value{number: float64(endpoint.Compute.Scaling.MinReplica)}, true
+	case op.field == "maxReplica":
+		return value{number: float64(endpoint.Compute.Scaling.MaxReplica)}, true
+	}
+
+	text := op.text
+	return value{text: &text}, true
+}
+
+// aggregate - last values of the series combined, false without any point
+func aggregate(series []client.MetricSeries, how string) (float64, bool) {
+	var values []float64
+	for _, s := range series {
+		if len(s.Points) > 0 {
+			values = append(values, s.Points[len(s.Points)-1].Value)
+		}
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	result := values[0]
+	for _, v := range values[1:] {
+		switch how {
+		case "min":
+			result = math.Min(result, v)
+		case "sum", "avg":
+			result += v
+		default:
+			result = math.Max(result, v)
+		}
+	}
+	if how == "avg" {
+		result /= float64(len(values))
+	}
+
+	return result, true
+}
+
+func compare(left value, operator string, right value) bool {
+	if left.text != nil || right.text != nil {
+		equal := left.String() == right.String()
+		if operator == "!=" {
+			return !equal
+		}
+		return equal
+	}
+
+	switch operator {
+	case ">":
+		return left.number > right.number
+	case ">=":
+		return left.number >= right.number
+	case "<":
+		return left.number < right.number
+	case "<=":
+		return left.number <= right.number
+	case "==":
+		return left.number == right.number
+	case "!=":
+		return left.number != right.number
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestParseExpression(t *testing.T) {
+	valid := []string{"server-error-rate > 0.05", "state == failed", "readyReplica < targetReplica", "state != 'running'"}
+	for _, expr := range valid {
+		if _, err := parseExpression(expr); err != nil {
+			t.Fatalf("expected %q to parse, got %v", expr, err)
+		}
+	}
+
+	invalid := []string{"server-error-rate >", "unknown > 1", "state > failed", "cpu-usage =~ 1"}
+	for _, expr := range invalid {
+		if _, err := parseExpression(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recorder) Notify(ctx context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestMonitor(t *testing.T) {
+	errorRate := "0.1"
+	state := "running"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/metrics/") {
+			io.WriteString(w, `[[1700000000, `+errorRate+`]]`)
+			return
+		}
+		io.WriteString(w, `{"items":[{"name":"llm","status":{"state":"`+state+`","readyReplica":1,"targetReplica":1}}]}`)
+	}))
+	defer server.Close()
+
+	host, token := server.URL, "token"
+	c, _ := client.NewClient(&host, &token)
+
+	config := &Config{
+		Namespace: "org",
+		Rules: []Rule{
+			{Name: "errors", Expr: "server-error-rate > 0.05", For: Duration(5 * time.Minute)},
+			{Name: "failed", Expr: "state == failed"},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	m, _ := New(c, config)
+	notifications := &recorder{}
+	m.Notifiers = []Notifier{notifications}
+
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+
+	states := func() []AlertState {
+		transitions, err := m.Evaluate(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var result []AlertState
+		for _, alert := range transitions {
+			result = append(result, alert.State)
+		}
+		return result
+	}
+
+	if got := states(); len(got) != 1 || got[0] != AlertPending {
+		t.Fatalf("expected pending alert, got %v", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if got := states(); len(got) != 0 {
+		t.Fatalf("pending alert should not transition before its for duration, got %v", got)
+	}
+
+	now = now.Add(3 * time.Minute)
+	state = "failed"
+	if got := states(); len(got) != 2 || got[0] != AlertFiring || got[1] != AlertFiring {
+		t.Fatalf("expected two firing alerts, got %v", got)
+	}
+
+	errorRate = "0.01"
+	now = now.Add(time.Minute)
+	if got := states(); len(got) != 1 || got[0] != AlertResolved {
+		t.Fatalf("expected resolved alert, got %v", got)
+	}
+
+	if len(notifications.alerts) != 4 || len(m.Alerts()) != 1 {
+		t.Fatalf("unexpected notifications %d and active alerts %d", len(notifications.alerts), len(m.Alerts()))
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload []byte
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ = io.ReadAll(r.Body)
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(NotifierConfig{
+		Type:     "webhook",
+		URL:      server.URL,
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Template: `{"text": {{quote .Summary}}, "alert": {{json .Rule}}}`,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	notifier.Notify(context.Background(), Alert{Rule: "errors", State: AlertPending, Summary: "pending"})
+	if payload != nil {
+		t.Fatalf("pending alerts should be filtered out by default")
+	}
+
+	notifier.Notify(context.Background(), Alert{Rule: "errors", State: AlertFiring, Summary: "firing \"now\"\x7f"})
+
+	var decoded map[string]string
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded["text"] != "firing \"now\"\x7f" || decoded["alert"] != "errors" {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+	if authorization != "Bearer secret" {
+		t.Fatalf("unexpected authorization header: %s", authorization)
+	}
+
+	var buf bytes.Buffer
+	writer := &WriterNotifier{Writer: &buf}
+	writer.Notify(context.Background(), Alert{Summary: "line"})
+	if !strings.HasSuffix(buf.String(), " line\n") {
+		t.Fatalf("unexpected line: %q", buf.String())
+	}
+}
@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Notifier delivers alert transitions
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NewNotifier - notifier of a config, filtered on the config states
+func NewNotifier(config NotifierConfig) (Notifier, error) {
+	var tmpl *template.Template
+	if config.Template != "" {
+		var err error
+		tmpl, err = template.New(config.Type).Funcs(templateFuncs).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s notifier template: %w", config.Type, err)
+		}
+	}
+
+	var notifier Notifier
+	switch config.Type {
+	case "stdout":
+		notifier = &WriterNotifier{Writer: os.Stdout, Template: tmpl}
+	case "file":
+		notifier = &FileNotifier{Path: config.Path, Template: tmpl}
+	case "webhook":
+		notifier = &WebhookNotifier{
+			URL:      config.URL,
+			Headers:  config.Headers,
+			Template: tmpl,
+			Client:   &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported notifier type %s", config.Type)
+	}
+
+	states := config.States
+	if len(states) == 0 {
+		states = []AlertState{AlertFiring, AlertResolved}
+	}
+
+	return &filteredNotifier{notifier: notifier, states: states}, nil
+}
+
+var templateFuncs = template.FuncMap{
+	// JSON string, Go escapes as \x01 are not valid JSON
+	"quote": func(s string) string {
+		quoted, _ := json.Marshal(s)
+		return string(quoted)
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// render - alert rendered with the template, or the fallback when there is none
+func render(tmpl *template.Template, alert Alert, fallback func(Alert) ([]byte, error)) ([]byte, error) {
+	if tmpl == nil {
+		return fallback(alert)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func summaryLine(alert Alert) ([]byte, error) {
+	return []byte(alert.Summary), nil
+}
+
+type filteredNotifier struct {
+	notifier Notifier
+	states   []AlertState
+}
+
+func (f *filteredNotifier) Notify(ctx context.Context, alert Alert) error {
+	for _, state := range f.states {
+		if state == alert.State {
+			return f.notifier.Notify(ctx, alert)
+		}
+	}
+
+	return nil
+}
+
+// WriterNotifier writes one line per alert, the summary unless a template is set
+type WriterNotifier struct {
+	Writer   io.Writer
+	Template *template.Template
+}
+
+func (n *WriterNotifier) Notify(ctx context.Context, alert Alert) error {
+	line, err := render(n.Template, alert, summaryLine)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(n.Writer, "%s %s\n", time.Now().Format(time.RFC3339), bytes.TrimRight(line, "\n"))
+
+	return err
+}
+
+// FileNotifier appends one line per alert to a log file, the alert as json unless
+// a template is set
+type FileNotifier struct {
+	Path     string
+	Template *template.Template
+
+	mu sync.Mutex
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, alert Alert) error {
+	line, err := render(n.Template, alert, func(alert Alert) ([]byte, error) {
+		return json.Marshal(alert)
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(bytes.TrimRight(line, "\n"), '\n'))
+
+	return err
+}
+
+// WebhookNotifier posts each alert to an url, as json unless a template is set
+type WebhookNotifier struct {
+	URL      string
+	Headers  map[string]string
+	Template *template.Template
+	Client   *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	payload, err := render(n.Template, alert, func(alert Alert) ([]byte, error) {
+		return json.Marshal(alert)
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", n.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook %s: HTTP error %d: %s", n.URL, resp.StatusCode, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+// Metrics whose activity keeps an endpoint awake
+var activityMetrics = []string{"request-count", "pending-requests"}
+
+// ReaperConfig is the content of an idle policy file
+type ReaperConfig struct {
+	Namespace string `json:"namespace"`
+	// Lookback of the metric queries, the longest idle duration of the policies when unset
+	Lookback Duration `json:"lookback,omitempty"`
+	// Interval between two inspections, ten minutes when unset
+	Interval Duration `json:"interval,omitempty"`
+	// The first policy matching an endpoint applies, endpoints matching none are not paused
+	Policies []IdlePolicy `json:"policies"`
+	// Endpoints never paused, by name or glob pattern (dev-*)
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// IdlePolicy pauses the endpoints carrying any of its tags, or all endpoints when it has
+// none, once they received no request for IdleAfter. A zero IdleAfter never pauses.
+type IdlePolicy struct {
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags,omitempty"`
+	IdleAfter Duration `json:"idleAfter"`
+}
+
+// LoadReaperConfig - read an idle policy file, validated by Validate once the command line overrides are applied
+func LoadReaperConfig(path string) (*ReaperConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ReaperConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate - check the policies and the allowlist patterns
+func (c *ReaperConfig) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("namespace is required")
+	}
+	if len(c.Policies) == 0 {
+		return errors.New("at least one policy is required")
+	}
+
+	for i, policy := range c.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("policy %d has no name", i)
+		}
+		if policy.IdleAfter < 0 {
+			return fmt.Errorf("policy %s: negative idleAfter", policy.Name)
+		}
+	}
+
+	if longest := c.longestIdleAfter(); c.Lookback != 0 && c.Lookback < longest {
+		return fmt.Errorf("lookback %s is shorter than the longest idleAfter %s", time.Duration(c.Lookback), time.Duration(longest))
+	}
+
+	for _, pattern := range c.Allowlist {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowlist pattern %s: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// lookback - Lookback of the metric queries, the longest idleAfter when unset
+func (c *ReaperConfig) lookback() time.Duration {
+	if c.Lookback != 0 {
+		return time.Duration(c.Lookback)
+	}
+
+	return time.Duration(c.longestIdleAfter())
+}
+
+func (c *ReaperConfig) longestIdleAfter() Duration {
+	var longest Duration
+	for _, policy := range c.Policies {
+		if policy.IdleAfter > longest {
+			longest = policy.IdleAfter
+		}
+	}
+
+	return longest
+}
+
+// Policy - first policy matching the tags of an endpoint
+func (c *ReaperConfig) Policy(tags []string) (IdlePolicy, bool) {
+	for _, policy := range c.Policies {
+		if len(policy.Tags) == 0 {
+			return policy, true
+		}
+		for _, tag := range policy.Tags {
+			for _, endpointTag := range tags {
+				if tag == endpointTag {
+					return policy, true
+				}
+			}
+		}
+	}
+
+	return IdlePolicy{}, false
+}
+
+// Allowlisted - whether an endpoint is never paused
+func (c *ReaperConfig) Allowlisted(name string) bool {
+	for _, pattern := range c.Allowlist {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IdleReport is the activity of an endpoint over the lookback window
+type IdleReport struct {
+	Endpoint string               `json:"endpoint"`
+	State    client.EndpointState `json:"state"`
+	Instance string               `json:"instance,omitempty"`
+	Replicas int                  `json:"replicas"`
+	Policy   string               `json:"policy,omitempty"`
+	// Last time a request was received or pending, nil when there was none in the window
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+	IdleFor      Duration   `json:"idleFor"`
+	IdleAfter    Duration   `json:"idleAfter,omitempty"`
+	// Replica hours spent without activity, the savings of pausing the endpoint earlier
+	IdleReplicaHours float64 `json:"idleReplicaHours"`
+	// Idle for longer than its policy, paused unless allowlisted or in dry run
+	Candidate bool   `json:"candidate"`
+	Paused    bool   `json:"paused,omitempty"`
+	Skipped   string `json:"skipped,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Reaper pauses the endpoints of a namespace idle for longer than their policy
+type Reaper struct {
+	Client *client.Client
+	Config *ReaperConfig
+	// Report the candidates without pausing them
+	DryRun bool
+
+	now func() time.Time
+}
+
+// NewReaper - reaper of a validated config
+func NewReaper(c *client.Client, config *ReaperConfig) *Reaper {
+	return &Reaper{Client: c, Config: config, now: time.Now}
+}
+
+// Reap - inspect the endpoints of the namespace and pause the idle ones, the reports are
+// sorted as the endpoints list
+func (r *Reaper) Reap(ctx context.Context) ([]IdleReport, error) {
+	endpoints, err := r.Client.ListEndpoints(r.Config.Namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	var reports []IdleReport
+	for _, endpoint := range endpoints {
+		if ctx.Err() != nil {
+			return reports, ctx.Err()
+		}
+
+		report := r.inspect(endpoint, now)
+		if report.Candidate && report.Skipped == "" && !r.DryRun {
+			if err := r.Client.PauseEndpoint(r.Config.Namespace, endpoint.Name); err != nil {
+				report.Error = err.Error()
+			} else {
+				report.Paused = true
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// inspect - activity of an endpoint, candidates allowlisted are reported with a skip reason
+func (r *Reaper) inspect(endpoint client.EndpointWithStatus, now time.Time) IdleReport {
+	report := IdleReport{
+		Endpoint: endpoint.Name,
+		State:    endpoint.Status.State,
+		Replicas: endpoint.Status.ReadyReplica,
+	}
+	if endpoint.Compute.InstanceType != "" {
+		report.Instance = endpoint.Compute.InstanceType + " " + endpoint.Compute.InstanceSize
+	}
+
+	// Paused and scaled to zero endpoints cost nothing, others are changing state
+	if endpoint.Status.State != client.StateRunning {
+		report.Skipped = "not running"
+		return report
+	}
+
+	policy, ok := r.Config.Policy(endpoint.Tags)
+	if !ok {
+		report.Skipped = "no policy"
+		return report
+	}
+	report.Policy = policy.Name
+	report.IdleAfter = policy.IdleAfter
+
+	lookback := r.Config.lookback()
+	from := now.Add(-lookback)
+	step := lookback / client.MaxMetricChunkPoints
+	step = step.Truncate(time.Minute)
+	if step < time.Minute {
+		step = time.Minute
+	}
+
+	var data bool
+	for _, metric := range activityMetrics {
+		series, err := r.Client.GetEndpointMetricRange(r.Config.Namespace, endpoint.Name, metric, from, now, step, 0)
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		for _, s := range series {
+			for _, point := range s.Points {
+				data = true
+				if point.Value > 0 && (report.LastActivity == nil || point.Time.After(*report.LastActivity)) {
+					activity := point.Time
+					report.LastActivity = &activity
+				}
+			}
+		}
+	}
+
+	// Without any point the endpoint may be idle or the metrics unavailable, it is left running
+	if !data {
+		report.Skipped = "no metric data"
+		return report
+	}
+
+	// An endpoint idles at most since the start of the window or its last update
+	since := from
+	if report.LastActivity != nil && report.LastActivity.After(since) {
+		since = *report.LastActivity
+	}
+	if endpoint.Status.UpdatedAt.After(since) {
+		since = endpoint.Status.UpdatedAt
+	}
+	if since.After(now) {
+		since = now
+	}
+
+	idle := now.Sub(since)
+	report.IdleFor = Duration(idle.Truncate(time.Second))
+	report.IdleReplicaHours = idle.Hours() * float64(endpoint.Status.ReadyReplica)
+	report.Candidate = policy.IdleAfter > 0 && idle >= time.Duration(policy.IdleAfter)
+
+	if report.Candidate && r.Config.Allowlisted(endpoint.Name) {
+		report.Skipped = "allowlisted"
+	}
+
+	return report
+}
+
+// Run - reap every config interval until the context is done, the reports of the
+// candidates are passed to onReport and errors to onError
+func (r *Reaper) Run(ctx context.Context, onReport func(IdleReport), onError func(error)) {
+	interval := time.Duration(r.Config.Interval)
+	if interval <= 0 {
+		interval = DefaultReaperInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reports, err := r.Reap(ctx)
+		for _, report := range reports {
+			if report.Candidate || report.Error != "" {
+				onReport(report)
+			}
+		}
+		if err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
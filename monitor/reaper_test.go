@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestReaper(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	// Last request of each endpoint, none for idle ones
+	lastRequest := map[string]time.Time{
+		"busy":  now.Add(-10 * time.Minute),
+		"quiet": now.Add(-3 * time.Hour),
+	}
+
+	var mu sync.Mutex
+	var paused []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		parts := strings.Split(r.URL.Path, "/")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pause"):
+			paused = append(paused, parts[len(parts)-2])
+		case strings.Contains(r.URL.Path, "/metrics/"):
+			name := parts[len(parts)-3]
+			points := fmt.Sprintf("[%d, 0]", now.Add(-time.Minute).Unix())
+			if last, ok := lastRequest[name]; ok && strings.HasSuffix(r.URL.Path, "/request-count") {
+				points += fmt.Sprintf(", [%d, 4]", last.Unix())
+			}
+			if name != "nodata" {
+				io.WriteString(w, "["+points+"]")
+				return
+			}
+			io.WriteString(w, "[]")
+		default:
+			io.WriteString(w, `{"items":[
+				{"name":"busy","tags":["dev"],"status":{"state":"running","readyReplica":1}},
+				{"name":"quiet","tags":["dev"],"status":{"state":"running","readyReplica":2}},
+				{"name":"idle","tags":["dev"],"status":{"state":"running","readyReplica":1}},
+				{"name":"dev-pinned","tags":["dev"],"status":{"state":"running","readyReplica":1}},
+				{"name":"prod","tags":["prod"],"status":{"state":"running","readyReplica":1}},
+				{"name":"nodata","tags":["dev"],"status":{"state":"running","readyReplica":1}},
+				{"name":"asleep","tags":["dev"],"status":{"state":"paused"}}
+			]}`)
+		}
+	}))
+	defer server.Close()
+
+	host, token := server.URL, "token"
+	c, _ := client.NewClient(&host, &token)
+
+	config := &ReaperConfig{
+		Namespace: "org",
+		Policies: []IdlePolicy{
+			{Name: "prod", Tags: []string{"prod"}},
+			{Name: "dev", Tags: []string{"dev"}, IdleAfter: Duration(2 * time.Hour)},
+		},
+		Lookback:  Duration(6 * time.Hour),
+		Allowlist: []string{"dev-*"},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	reaper := NewReaper(c, config)
+	reaper.now = func() time.Time { return now }
+
+	reaper.DryRun = true
+	reports, err := reaper.Reap(context.Background())
+	if err != nil || len(reports) != 7 || len(paused) != 0 {
+		t.Fatalf("expected 7 reports and no pause in dry run, got %v %v %v", reports, paused, err)
+	}
+
+	expected := map[string]string{
+		"busy":       "candidate=false skipped= idle=10m0s",
+		"quiet":      "candidate=true skipped= idle=3h0m0s",
+		"idle":       "candidate=true skipped= idle=6h0m0s",
+		"dev-pinned": "candidate=true skipped=allowlisted idle=6h0m0s",
+		"prod":       "candidate=false skipped= idle=6h0m0s",
+		"nodata":     "candidate=false skipped=no metric data idle=0s",
+		"asleep":     "candidate=false skipped=not running idle=0s",
+	}
+	for _, report := range reports {
+		got := fmt.Sprintf("candidate=%t skipped=%s idle=%s", report.Candidate, report.Skipped, time.Duration(report.IdleFor))
+		if got != expected[report.Endpoint] {
+			t.Fatalf("expected %s for %s, got %s", expected[report.Endpoint], report.Endpoint, got)
+		}
+	}
+	if reports[1].IdleReplicaHours != 6 {
+		t.Fatalf("expected 6 idle replica hours for quiet, got %f", reports[1].IdleReplicaHours)
+	}
+
+	reaper.DryRun = false
+	if _, err := reaper.Reap(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(paused, ",") != "quiet,idle" {
+		t.Fatalf("expected quiet and idle to be paused, got %v", paused)
+	}
+}
+
+func TestReaperConfig(t *testing.T) {
+	config := &ReaperConfig{
+		Namespace: "org",
+		Policies:  []IdlePolicy{{Name: "all", IdleAfter: Duration(time.Hour)}},
+		Lookback:  Duration(time.Minute),
+	}
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected a lookback shorter than idleAfter to be rejected")
+	}
+
+	config.Lookback = 0
+	if err := config.Validate(); err != nil || config.Lookback != 0 || config.lookback() != time.Hour {
+		t.Fatalf("expected the lookback to default to the longest idleAfter without changing the config, got %v %v", config.lookback(), err)
+	}
+
+	if policy, ok := config.Policy(nil); !ok || policy.Name != "all" {
+		t.Fatal("expected a policy without tags to match any endpoint")
+	}
+}
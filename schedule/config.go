@@ -0,0 +1,150 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionPause       Action = "pause"
+	ActionResume      Action = "resume"
+	ActionScaleToZero Action = "scale-to-zero"
+	// Update the min and max replicas of the scaling policy
+	ActionScale Action = "scale"
+)
+
+// Config is the content of a schedule file
+type Config struct {
+	Namespace string `json:"namespace"`
+	// IANA time zone of the cron expressions and holidays, local time when empty
+	Timezone string `json:"timezone,omitempty"`
+	// Dates (2006-01-02) on which rules do not run unless they set RunOnHolidays
+	Holidays []string `json:"holidays,omitempty"`
+	Rules    []Rule   `json:"rules"`
+
+	location *time.Location
+	holidays map[string]bool
+}
+
+// Rule runs an action on endpoints, selected by name or by tag, at the times of a cron expression
+type Rule struct {
+	Name   string `json:"name"`
+	Cron   string `json:"cron"`
+	Action Action `json:"action"`
+	// Endpoints by name, and endpoints carrying any of the tags
+	Endpoints []string `json:"endpoints,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Scaling of the scale action
+	MinReplica    *int `json:"minReplica,omitempty"`
+	MaxReplica    *int `json:"maxReplica,omitempty"`
+	RunOnHolidays bool `json:"runOnHolidays,omitempty"`
+
+	cron *Cron
+}
+
+// LoadConfig - read a schedule file, validated by Validate once the command line overrides are applied
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid schedule file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate - check the rules, parse their cron expressions, the time zone and holidays
+func (c *Config) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("namespace is required")
+	}
+
+	c.location = time.Local
+	if c.Timezone != "" {
+		location, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %s: %w", c.Timezone, err)
+		}
+		c.location = location
+	}
+
+	c.holidays = map[string]bool{}
+	for _, holiday := range c.Holidays {
+		if _, err := time.Parse("2006-01-02", holiday); err != nil {
+			return fmt.Errorf("invalid holiday %s, expected YYYY-MM-DD", holiday)
+		}
+		c.holidays[holiday] = true
+	}
+
+	names := map[string]bool{}
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicated rule name %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		cron, err := ParseCron(rule.Cron)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		rule.cron = cron
+
+		switch rule.Action {
+		case ActionPause, ActionResume, ActionScaleToZero:
+		case ActionScale:
+			if rule.MinReplica == nil && rule.MaxReplica == nil {
+				return fmt.Errorf("rule %s: scale action requires minReplica or maxReplica", rule.Name)
+			}
+		default:
+			return fmt.Errorf("rule %s: unsupported action %s", rule.Name, rule.Action)
+		}
+
+		if len(rule.Endpoints) == 0 && len(rule.Tags) == 0 {
+			return fmt.Errorf("rule %s: endpoints or tags are required", rule.Name)
+		}
+	}
+
+	return nil
+}
+
+// Location - time zone of the schedule
+func (c *Config) Location() *time.Location {
+	return c.location
+}
+
+// IsHoliday - whether t falls on a holiday in the schedule time zone
+func (c *Config) IsHoliday(t time.Time) bool {
+	return c.holidays[t.In(c.location).Format("2006-01-02")]
+}
+
+// Matches - whether the rule selects the endpoint
+func (r Rule) Matches(name string, tags []string) bool {
+	for _, endpoint := range r.Endpoints {
+		if endpoint == name {
+			return true
+		}
+	}
+
+	for _, tag := range r.Tags {
+		for _, endpointTag := range tags {
+			if tag == endpointTag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
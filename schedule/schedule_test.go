@@ -0,0 +1,204 @@
+package schedule
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestCronNext(t *testing.T) {
+	cases := []struct {
+		expr     string
+		from     string
+		expected string
+	}{
+		{"0 20 * * mon-fri", "2024-03-01T20:00:00Z", "2024-03-04T20:00:00Z"},
+		{"*/15 * * * *", "2024-03-01T10:07:30Z", "2024-03-01T10:15:00Z"},
+		{"30 8 1 * *", "2024-03-01T09:00:00Z", "2024-04-01T08:30:00Z"},
+		// day-of-month and day-of-week are OR-ed when both are restricted
+		{"0 0 15 * sun", "2024-03-01T00:00:00Z", "2024-03-03T00:00:00Z"},
+		{"@yearly", "2024-03-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"0 0 29 feb *", "2024-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+	}
+
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("expected %q to parse, got %v", c.expr, err)
+		}
+		from, _ := time.Parse(time.RFC3339, c.from)
+		if next := cron.Next(from).Format(time.RFC3339); next != c.expected {
+			t.Fatalf("expected next of %q after %s to be %s, got %s", c.expr, c.from, c.expected, next)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * * foo", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	config := &Config{
+		Namespace: "org",
+		Timezone:  "Europe/Paris",
+		Holidays:  []string{"2024-03-05"},
+		Rules: []Rule{
+			{Name: "night", Cron: "0 20 * * mon-fri", Action: ActionPause, Tags: []string{"dev"}},
+			{Name: "morning", Cron: "0 8 * * mon-fri", Action: ActionResume, Tags: []string{"dev"}, RunOnHolidays: true},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	s := &Scheduler{Config: config}
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	occurrences := s.Upcoming(from, from.Add(48*time.Hour))
+
+	var got []string
+	for _, o := range occurrences {
+		got = append(got, o.Rule+"@"+o.Time.UTC().Format("01-02T15:04"))
+	}
+	// 8:00 and 20:00 in Paris are 7:00 and 19:00 UTC in march, the night rule skips the holiday
+	expected := "morning@03-04T07:00 night@03-04T19:00 morning@03-05T07:00"
+	if strings.Join(got, " ") != expected {
+		t.Fatalf("expected %s, got %s", expected, strings.Join(got, " "))
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == "GET" {
+			io.WriteString(w, `{"items":[
+				{"name":"dev-llm","tags":["dev"],"compute":{"scaling":{"minReplica":1,"maxReplica":2}},"status":{"state":"running"}},
+				{"name":"prod-llm","tags":["prod"],"compute":{"scaling":{"minReplica":1,"maxReplica":2}},"status":{"state":"running"}}
+			]}`)
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	host, token := server.URL, "token"
+	c, _ := client.NewClient(&host, &token)
+
+	two := 2
+	config := &Config{
+		Namespace: "org",
+		Timezone:  "UTC",
+		Rules: []Rule{
+			{Name: "night", Cron: "0 20 * * *", Action: ActionPause, Tags: []string{"dev"}},
+			{Name: "morning", Cron: "0 8 * * *", Action: ActionResume, Tags: []string{"dev"}},
+			{Name: "peak", Cron: "0 9 * * *", Action: ActionScale, Endpoints: []string{"prod-llm"}, MinReplica: &two},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	s, err := New(c, config, statePath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	now := time.Date(2024, 3, 4, 19, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	// The first run only records the state
+	results, err := s.RunDue(context.Background())
+	if err != nil || len(results) != 0 || len(calls) != 0 {
+		t.Fatalf("expected the first run to do nothing, got %v %v %v", results, calls, err)
+	}
+
+	// A dry run reports the actions without calling the api nor saving the state
+	now = time.Date(2024, 3, 4, 20, 0, 30, 0, time.UTC)
+	dry, _ := New(c, config, statePath)
+	dry.DryRun = true
+	dry.now = s.now
+	results, err = dry.RunDue(context.Background())
+	if err != nil || len(results) != 1 || !results[0].DryRun || results[0].Endpoint != "dev-llm" || len(calls) != 0 {
+		t.Fatalf("expected a dry pause of dev-llm, got %v %v %v", results, calls, err)
+	}
+
+	// Catching up a night pause, a morning resume and a peak scale after a downtime
+	now = time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	results, err = s.RunDue(context.Background())
+	if err != nil || len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v %v", results, err)
+	}
+	expected := "POST /v2/endpoint/org/dev-llm/pause POST /v2/endpoint/org/dev-llm/resume PUT /v2/endpoint/org/prod-llm"
+	if strings.Join(calls, " ") != expected {
+		t.Fatalf("expected calls %s, got %s", expected, strings.Join(calls, " "))
+	}
+
+	// The state is persisted, occurrences are not run twice
+	reloaded, err := New(c, config, statePath)
+	if err != nil || !reloaded.State.Rules["night"].LastRun.Equal(now) {
+		t.Fatalf("expected the state to be saved, got %v", err)
+	}
+	reloaded.now = s.now
+	if results, _ := reloaded.RunDue(context.Background()); len(results) != 0 {
+		t.Fatalf("expected no due occurrence, got %v", results)
+	}
+}
+
+func TestRunDueRetriesTransientErrors(t *testing.T) {
+	var pauses int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			io.WriteString(w, `{"items":[{"name":"dev-llm","tags":["dev"],"status":{"state":"running"}}]}`)
+			return
+		}
+		pauses++
+		if pauses == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	host, token := server.URL, "token"
+	c, _ := client.NewClient(&host, &token)
+	config := &Config{Namespace: "org", Timezone: "UTC", Rules: []Rule{{Name: "night", Cron: "0 20 * * *", Action: ActionPause, Tags: []string{"dev"}}}}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	s, _ := New(c, config, "")
+
+	now := time.Date(2024, 3, 4, 19, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.RunDue(context.Background())
+
+	now = time.Date(2024, 3, 4, 20, 0, 30, 0, time.UTC)
+	results, _ := s.RunDue(context.Background())
+	if len(results) != 1 || results[0].Error == "" || !results[0].Retry {
+		t.Fatalf("expected a retryable failure, got %+v", results)
+	}
+
+	// The failed pause is still due at the next tick
+	now = now.Add(DefaultTick)
+	results, _ = s.RunDue(context.Background())
+	if len(results) != 1 || results[0].Error != "" || pauses != 2 {
+		t.Fatalf("expected the pause to be retried, got %+v after %d calls", results, pauses)
+	}
+	if results, _ := s.RunDue(context.Background()); len(results) != 0 {
+		t.Fatalf("expected no due occurrence once the pause succeeded, got %+v", results)
+	}
+}
@@ -0,0 +1,374 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+const (
+	// Interval between two checks of the due rules
+	DefaultTick = 30 * time.Second
+	// Occurrences missed for longer, e.g. while the scheduler was stopped, are not caught up
+	DefaultMaxCatchUp = 24 * time.Hour
+)
+
+// Occurrence is a scheduled run of a rule
+type Occurrence struct {
+	Rule   string    `json:"rule"`
+	Action Action    `json:"action"`
+	Time   time.Time `json:"time"`
+	// Endpoints selected by the rule, when resolved against the api
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+// Result is the outcome of an occurrence on an endpoint
+type Result struct {
+	Rule      string    `json:"rule"`
+	Action    Action    `json:"action"`
+	Endpoint  string    `json:"endpoint"`
+	Scheduled time.Time `json:"scheduled"`
+	Executed  time.Time `json:"executed"`
+	DryRun    bool      `json:"dryRun,omitempty"`
+	// Reason the action was not needed, e.g. an already paused endpoint
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// The error is transient, the occurrence is run again at the next tick
+	Retry bool `json:"retry,omitempty"`
+}
+
+// State is persisted between runs so that missed occurrences are caught up
+// and occurrences are not run twice
+type State struct {
+	Rules map[string]*RuleState `json:"rules"`
+}
+
+type RuleState struct {
+	// Time up to which the occurrences of the rule were handled
+	LastRun     time.Time `json:"lastRun"`
+	LastResults []Result  `json:"lastResults,omitempty"`
+}
+
+// LoadState - read a state file, empty when the file does not exist
+func LoadState(path string) (*State, error) {
+	state := &State{Rules: map[string]*RuleState{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	if state.Rules == nil {
+		state.Rules = map[string]*RuleState{}
+	}
+
+	return state, nil
+}
+
+// Save - write the state file atomically
+func (s *State) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Scheduler runs the rules of a schedule when they are due
+type Scheduler struct {
+	Client *client.Client
+	Config *Config
+	State  *State
+	// State file, the state is kept in memory only when empty
+	StatePath string
+	// Report the actions without running them nor saving the state
+	DryRun     bool
+	MaxCatchUp time.Duration
+
+	now func() time.Time
+}
+
+// New - scheduler of a validated config, with the state of statePath
+func New(c *client.Client, config *Config, statePath string) (*Scheduler, error) {
+	state := &State{Rules: map[string]*RuleState{}}
+	if statePath != "" {
+		var err error
+		if state, err = LoadState(statePath); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Scheduler{
+		Client:     c,
+		Config:     config,
+		State:      state,
+		StatePath:  statePath,
+		MaxCatchUp: DefaultMaxCatchUp,
+		now:        time.Now,
+	}, nil
+}
+
+// Upcoming - occurrences of the rules in (from, until], holidays excluded, sorted by time
+func (s *Scheduler) Upcoming(from, until time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, rule := range s.Config.Rules {
+		for t := rule.cron.Next(from.In(s.Config.Location())); !t.IsZero() && !t.After(until); t = rule.cron.Next(t) {
+			if s.Config.IsHoliday(t) && !rule.RunOnHolidays {
+				continue
+			}
+			occurrences = append(occurrences, Occurrence{Rule: rule.Name, Action: rule.Action, Time: t})
+		}
+	}
+
+	sortOccurrences(occurrences)
+
+	return occurrences
+}
+
+// Resolve - fill the endpoints selected by the rules of the occurrences
+func (s *Scheduler) Resolve(occurrences []Occurrence) error {
+	endpoints, err := s.Client.ListEndpoints(s.Config.Namespace, nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range occurrences {
+		rule := s.rule(occurrences[i].Rule)
+		for _, endpoint := range endpoints {
+			if rule.Matches(endpoint.Name, endpoint.Tags) {
+				occurrences[i].Endpoints = append(occurrences[i].Endpoints, endpoint.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Scheduler) rule(name string) Rule {
+	for _, rule := range s.Config.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return Rule{}
+}
+
+// due - latest occurrence of each rule since its last run, rules never run start now
+func (s *Scheduler) due(now time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, rule := range s.Config.Rules {
+		state, ok := s.State.Rules[rule.Name]
+		if !ok {
+			continue
+		}
+
+		from := state.LastRun
+		if now.Sub(from) > s.MaxCatchUp {
+			from = now.Add(-s.MaxCatchUp)
+		}
+
+		// Catching up the latest missed occurrence is enough to reach the scheduled state
+		upcoming := s.Upcoming(from, now)
+		for i := len(upcoming) - 1; i >= 0; i-- {
+			if upcoming[i].Rule == rule.Name {
+				occurrences = append(occurrences, upcoming[i])
+				break
+			}
+		}
+	}
+
+	sortOccurrences(occurrences)
+
+	return occurrences
+}
+
+// RunDue - run the occurrences due since the last run, in chronological order
+func (s *Scheduler) RunDue(ctx context.Context) ([]Result, error) {
+	now := s.now()
+	occurrences := s.due(now)
+
+	var results []Result
+	retry := map[string]bool{}
+	if len(occurrences) > 0 {
+		endpoints, err := s.Client.ListEndpoints(s.Config.Namespace, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, occurrence := range occurrences {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+
+			rule := s.rule(occurrence.Rule)
+			var ruleResults []Result
+			for i := range endpoints {
+				if rule.Matches(endpoints[i].Name, endpoints[i].Tags) {
+					result := s.execute(rule, occurrence, &endpoints[i])
+					retry[rule.Name] = retry[rule.Name] || result.Retry
+					ruleResults = append(ruleResults, result)
+				}
+			}
+
+			s.ruleState(rule.Name).LastResults = ruleResults
+			results = append(results, ruleResults...)
+		}
+	}
+
+	// Rules which failed on a transient error stay due, e.g. so that a failed pause does not leave
+	// an endpoint running until the next occurrence
+	for _, rule := range s.Config.Rules {
+		if !retry[rule.Name] {
+			s.ruleState(rule.Name).LastRun = now
+		}
+	}
+
+	// Dry runs keep their state in memory so that the real schedule is not affected
+	if s.StatePath != "" && !s.DryRun {
+		if err := s.State.Save(s.StatePath); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+func (s *Scheduler) ruleState(name string) *RuleState {
+	state, ok := s.State.Rules[name]
+	if !ok {
+		state = &RuleState{}
+		s.State.Rules[name] = state
+	}
+
+	return state
+}
+
+// execute - run the action of a rule on an endpoint, skipping it when the endpoint is already in the target state.
+// The endpoint is updated with the target state so that following occurrences of the same run see it.
+func (s *Scheduler) execute(rule Rule, occurrence Occurrence, endpoint *client.EndpointWithStatus) Result {
+	result := Result{
+		Rule:      rule.Name,
+		Action:    rule.Action,
+		Endpoint:  endpoint.Name,
+		Scheduled: occurrence.Time,
+		Executed:  s.now(),
+		DryRun:    s.DryRun,
+	}
+
+	state := endpoint.Status.State
+	var run func() error
+	var apply func()
+
+	switch rule.Action {
+	case ActionPause:
+		if state == client.StatePaused {
+			result.Skipped = "already paused"
+			return result
+		}
+		run = func() error { return s.Client.PauseEndpoint(s.Config.Namespace, endpoint.Name) }
+		apply = func() { endpoint.Status.State = client.StatePaused }
+	case ActionResume:
+		if state != client.StatePaused {
+			result.Skipped = "not paused"
+			return result
+		}
+		run = func() error { return s.Client.ResumeEndpoint(s.Config.Namespace, endpoint.Name) }
+		apply = func() { endpoint.Status.State = client.StatePending }
+	case ActionScaleToZero:
+		if state == client.StateScaledToZero || state == client.StatePaused {
+			result.Skipped = "already " + string(state)
+			return result
+		}
+		run = func() error { return s.Client.ScaleEndpointToZero(s.Config.Namespace, endpoint.Name) }
+		apply = func() { endpoint.Status.State = client.StateScaledToZero }
+	case ActionScale:
+		scaling := endpoint.Compute.Scaling
+		if (rule.MinReplica == nil || *rule.MinReplica == scaling.MinReplica) && (rule.MaxReplica == nil || *rule.MaxReplica == scaling.MaxReplica) {
+			result.Skipped = "scaling already applied"
+			return result
+		}
+		run = func() error {
+			_, err := s.Client.UpdateEndpoint(s.Config.Namespace, endpoint.Name, client.EndpointUpdate{
+				Compute: &client.EndpointComputeUpdate{
+					Scaling: &client.EndpointScalingUpdate{MinReplica: rule.MinReplica, MaxReplica: rule.MaxReplica},
+				},
+			})
+			return err
+		}
+		apply = func() {
+			if rule.MinReplica != nil {
+				endpoint.Compute.Scaling.MinReplica = *rule.MinReplica
+			}
+			if rule.MaxReplica != nil {
+				endpoint.Compute.Scaling.MaxReplica = *rule.MaxReplica
+			}
+		}
+	}
+
+	if !s.DryRun {
+		if err := run(); err != nil {
+			result.Error = err.Error()
+			result.Retry = client.IsRetryable(err)
+			return result
+		}
+	}
+	apply()
+
+	return result
+}
+
+// Run - run the due rules every tick until the context is done
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration, onResult func(Result), onError func(error)) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		results, err := s.RunDue(ctx)
+		for _, result := range results {
+			onResult(result)
+		}
+		if err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func sortOccurrences(occurrences []Occurrence) {
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Time.Before(occurrences[j].Time)
+	})
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+// Ticks of sparklines, from the lowest to the highest value
+var (
+	sparkTicks      = []rune("▁▂▃▄▅▆▇█")
+	asciiSparkTicks = []rune("_.-:=+*#")
+)
+
+type ChartOptions struct {
+	// Total width of the chart, axis labels included
+	Width int
+	// Number of rows of the plot area
+	Height int
+	// Draw with ascii characters only
+	ASCII bool
+	// Time zone of the axis labels, local time when nil
+	Location *time.Location
+}
+
+type chartRunes struct {
+	point, vertical, yTick, yAxis, corner, xAxis rune
+}
+
+var (
+	unicodeRunes = chartRunes{'•', '│', '┤', '│', '└', '─'}
+	asciiRunes   = chartRunes{'*', '|', '+', '|', '+', '-'}
+)
+
+// Resample - average of the points falling in each of width buckets over [from, to],
+// NaN for the buckets without point
+func Resample(points []client.MetricPoint, from, to time.Time, width int) []float64 {
+	sums := make([]float64, width)
+	counts := make([]int, width)
+	span := to.Sub(from)
+
+	for _, point := range points {
+		if point.Time.Before(from) || point.Time.After(to) || span <= 0 {
+			continue
+		}
+		bucket := int(float64(point.Time.Sub(from)) / float64(span) * float64(width))
+		if bucket == width {
+			bucket--
+		}
+		sums[bucket] += point.Value
+		counts[bucket]++
+	}
+
+	values := make([]float64, width)
+	for i := range values {
+		if counts[i] == 0 {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = sums[i] / float64(counts[i])
+	}
+
+	return values
+}
+
+// Sparkline - one character per value scaled between the min and max values,
+// blank for NaN values
+func Sparkline(values []float64, ascii bool) string {
+	ticks := sparkTicks
+	if ascii {
+		ticks = asciiSparkTicks
+	}
+
+	min, max, ok := valueRange(values)
+
+	var b strings.Builder
+	for _, value := range values {
+		if !ok || math.IsNaN(value) {
+			b.WriteRune(' ')
+			continue
+		}
+		index := len(ticks) / 2
+		if max > min {
+			index = int(math.Round((value - min) / (max - min) * float64(len(ticks)-1)))
+		}
+		b.WriteRune(ticks[index])
+	}
+
+	return b.String()
+}
+
+// SeriesSummary - "last 1.2  min 0.5  max 3" summary of the values of a series
+func SeriesSummary(series client.MetricSeries) string {
+	if len(series.Points) == 0 {
+		return "no data"
+	}
+
+	summary := series.Summary()
+
+	return fmt.Sprintf("last %s  min %s  max %s", formatChartValue(summary.Last), formatChartValue(summary.Min), formatChartValue(summary.Max))
+}
+
+// LineChart - plot a series over [from, to] with a value axis and a time axis
+func LineChart(title string, series client.MetricSeries, from, to time.Time, options ChartOptions) string {
+	runes := unicodeRunes
+	if options.ASCII {
+		runes = asciiRunes
+	}
+	location := options.Location
+	if location == nil {
+		location = time.Local
+	}
+	height := options.Height
+	if height < 2 {
+		height = 2
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s\n", title, SeriesSummary(series))
+
+	// Value axis labels, at the top, middle and bottom rows
+	min, max, ok := valueRange(pointValues(series.Points))
+	if !ok {
+		return b.String()
+	}
+	labels := map[int]string{
+		0:          formatChartValue(max),
+		height / 2: formatChartValue(max - (max-min)*float64(height/2)/float64(height-1)),
+		height - 1: formatChartValue(min),
+	}
+	labelWidth := 0
+	for _, label := range labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	plotWidth := options.Width - labelWidth - 2
+	if plotWidth < 10 {
+		plotWidth = 10
+	}
+	values := Resample(series.Points, from, to, plotWidth)
+
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+
+	row := func(value float64) int {
+		if max == min {
+			return height / 2
+		}
+		return height - 1 - int(math.Round((value-min)/(max-min)*float64(height-1)))
+	}
+
+	previous := -1
+	for column, value := range values {
+		if math.IsNaN(value) {
+			previous = -1
+			continue
+		}
+		current := row(value)
+		// Join the previous point with a vertical line
+		if previous >= 0 {
+			for r := minInt(previous, current) + 1; r < maxInt(previous, current); r++ {
+				grid[r][column] = runes.vertical
+			}
+		}
+		grid[current][column] = runes.point
+		previous = current
+	}
+
+	for r, line := range grid {
+		axis := runes.yAxis
+		label, isLabel := labels[r]
+		if isLabel {
+			axis = runes.yTick
+		}
+		fmt.Fprintf(&b, "%*s %c%s\n", labelWidth, label, axis, strings.TrimRight(string(line), " "))
+	}
+
+	fmt.Fprintf(&b, "%*s %c%s\n", labelWidth, "", runes.corner, strings.Repeat(string(runes.xAxis), plotWidth))
+	b.WriteString(timeAxis(from, to, plotWidth, labelWidth+2, location))
+	b.WriteByte('\n')
+
+	return b.String()
+}
+
+// timeAxis - start, middle and end times of the axis in local time
+func timeAxis(from, to time.Time, width, indent int, location *time.Location) string {
+	layout := "15:04"
+	if to.Sub(from) > 24*time.Hour {
+		layout = "01-02 15:04"
+	}
+
+	start := from.In(location).Format(layout)
+	middle := from.Add(to.Sub(from) / 2).In(location).Format(layout)
+	end := to.In(location).Format(layout)
+
+	line := []rune(strings.Repeat(" ", width))
+	place := func(position int, text string) {
+		if position < 0 {
+			position = 0
+		}
+		for i, r := range text {
+			if position+i < len(line) {
+				line[position+i] = r
+			}
+		}
+	}
+	place(0, start)
+	if width >= len(start)+len(middle)+len(end)+4 {
+		place(width/2-len(middle)/2, middle)
+	}
+	place(width-len(end), end)
+
+	return strings.Repeat(" ", indent) + strings.TrimRight(string(line), " ")
+}
+
+func pointValues(points []client.MetricPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = point.Value
+	}
+
+	return values
+}
+
+// valueRange - min and max of the values, false when there is no value
+func valueRange(values []float64) (float64, float64, bool) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+	}
+
+	return min, max, !math.IsInf(min, 1)
+}
+
+func formatChartValue(value float64) string {
+	return fmt.Sprintf("%.4g", value)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
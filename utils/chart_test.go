@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sebps/huggingface-client/client"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]float64{0, 1, math.NaN(), 2}, false); got != "▁▅ █" {
+		t.Fatalf("unexpected sparkline: %q", got)
+	}
+}
+
+func TestResample(t *testing.T) {
+	from := time.Unix(0, 0)
+	points := []client.MetricPoint{{Time: from, Value: 1}, {Time: from.Add(time.Second), Value: 3}, {Time: from.Add(4 * time.Second), Value: 5}}
+
+	values := Resample(points, from, from.Add(4*time.Second), 2)
+	if values[0] != 2 || values[1] != 5 {
+		t.Fatalf("unexpected resampled values: %v", values)
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	from := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	series := client.MetricSeries{Points: []client.MetricPoint{
+		{Time: from, Value: 0},
+		{Time: from.Add(30 * time.Minute), Value: 10},
+	}}
+
+	chart := LineChart("cpu-usage", series, from, from.Add(time.Hour), ChartOptions{Width: 30, Height: 3, ASCII: true, Location: time.UTC})
+	lines := strings.Split(strings.TrimRight(chart, "\n"), "\n")
+
+	if len(lines) != 6 || lines[0] != "cpu-usage  last 10  min 0  max 10" {
+		t.Fatalf("unexpected chart:\n%s", chart)
+	}
+	if !strings.HasPrefix(lines[1], "10 +") || !strings.HasPrefix(lines[3], " 0 +*") {
+		t.Fatalf("unexpected value axis:\n%s", chart)
+	}
+	if !strings.Contains(lines[5], "14:00") || !strings.HasSuffix(lines[5], "15:00") {
+		t.Fatalf("unexpected time axis:\n%s", chart)
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// IsTerminal reports whether the file is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadSecret reads a single line from stdin. When stdin is a terminal the prompt
+// is printed to stderr and echo is disabled while typing.
+func ReadSecret(prompt string) (string, error) {
+	if !IsTerminal(os.Stdin) {
+		return readLine(os.Stdin)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	if err := stty("-echo"); err == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	return readLine(os.Stdin)
+}
+
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// TerminalSize returns the rows and columns of the terminal attached to stdin,
+// falling back to $LINES and $COLUMNS, then to 24x80
+func TerminalSize() (int, int) {
+	rows, cols := 24, 80
+	if value, err := strconv.Atoi(os.Getenv("LINES")); err == nil && value > 0 {
+		rows = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && value > 0 {
+		cols = value
+	}
+
+	if !IsTerminal(os.Stdin) {
+		return rows, cols
+	}
+
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	if err != nil {
+		return rows, cols
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 2 {
+		if value, err := strconv.Atoi(fields[0]); err == nil && value > 0 {
+			rows = value
+		}
+		if value, err := strconv.Atoi(fields[1]); err == nil && value > 0 {
+			cols = value
+		}
+	}
+
+	return rows, cols
+}
+
+// MakeRaw puts the terminal attached to stdin in non canonical mode without echo,
+// so that keys are read one by one while ctrl-c still interrupts. The returned
+// function restores the previous mode.
+func MakeRaw() (func(), error) {
+	cmd := exec.Command("stty", "-g")
+	cmd.Stdin = os.Stdin
+	saved, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		stty(strings.TrimSpace(string(saved)))
+	}, nil
+}
+
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+
+	return cmd.Run()
+}
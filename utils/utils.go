@@ -82,6 +82,10 @@ func BuildInferenceImage(
 func ParseTime(input string) (time.Time, error) {
 	input = strings.TrimSpace(input)
 
+	if input == "now" {
+		return time.Now().UTC(), nil
+	}
+
 	// First try known time formats
 	formats := []string{
 		time.RFC3339,
@@ -111,13 +115,19 @@ func ParseTime(input string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("could not parse time: %s", input)
 }
 
+// Metrics lists the endpoint metrics served by the metrics api
+var Metrics = []string{
+	"pending-requests", "request-count", "median-latency", "p95-latency", "success-throughput",
+	"bad-request-throughput", "server-error-throughput", "cpu-usage", "memory-usage", "gpu-usage",
+	"gpu-memory-usage", "neuron-usage", "neuron-memory-usage", "ready-replicas", "running-replicas",
+	"target-replicas", "average-latency", "success-rate", "bad-request-rate", "server-error-rate",
+}
+
 func IsMetricValid(metric string) bool {
-	switch metric {
-	case "pending-requests", "request-count", "median-latency", "p95-latency", "success-throughput",
-		"bad-request-throughput", "server-error-throughput", "cpu-usage", "memory-usage", "gpu-usage",
-		"gpu-memory-usage", "neuron-usage", "neuron-memory-usage", "ready-replicas", "running-replicas",
-		"target-replicas", "average-latency", "success-rate", "bad-request-rate", "server-error-rate":
-		return true
+	for _, valid := range Metrics {
+		if metric == valid {
+			return true
+		}
 	}
 
 	return false